@@ -0,0 +1,660 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+// Exit codes of the headless subcommands
+const (
+	exitOK       = 0
+	exitError    = 1 // I/O or unexpected failure
+	exitUsage    = 2 // bad arguments
+	exitNotFound = 3 // no task with that ID, or none ready for next
+	exitRefused  = 4 // invalid transition, blocked by dependencies, cycle, newer schema, safety policy
+)
+
+// usageError marks errors caused by the command line itself. reported is
+// set when the flag package already printed the error and the usage.
+type usageError struct {
+	msg      string
+	reported bool
+}
+
+func (e usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// cli runs one subcommand against the current project
+type cli struct {
+	stdout io.Writer
+	stderr io.Writer
+	usage  string
+	json   bool
+}
+
+type subcommand struct {
+	usage string
+	run   func(c *cli, args []string) error
+}
+
+var subcommands = map[string]subcommand{
+	"list":     {"list [--status S] [--agent A] [--query Q] [--json]", (*cli).list},
+	"show":     {"show ID [--json]", (*cli).show},
+	"add":      {"add [--agent A] [--priority P] [--deps 1,2] [--json] DESCRIPTION", (*cli).add},
+	"start":    {"start ID [--force] [--yes | --confirm ID] [--json]", (*cli).start},
+	"stop":     {"stop ID [--yes | --confirm ID] [--json]", (*cli).stop},
+	"complete": {"complete ID [--yes | --confirm ID] [--json]", (*cli).complete},
+	"remove":   {"remove ID [--yes | --confirm ID] [--json]", (*cli).remove},
+	"edit":     {"edit ID [--desc D] [--agent A] [--priority P] [--deps 1,2] [--yes | --confirm ID] [--json]", (*cli).edit},
+	"next":     {"next AGENT [--claim] [--json]", (*cli).next},
+	"archive":  {"archive [--older-than 168h] [--json]", (*cli).archive},
+	"archived": {"archived [--json] [QUERY]", (*cli).archived},
+	"restore":  {"restore ID [--json]", (*cli).restore},
+	"searches": {"searches [--json] | searches save NAME QUERY | searches delete NAME", (*cli).searches},
+	"serve":    {"serve [--addr 127.0.0.1:7878 | --socket PATH]", (*cli).serve},
+}
+
+// isSubcommand reports whether name is a headless subcommand
+func isSubcommand(name string) bool {
+	_, ok := subcommands[name]
+	return ok
+}
+
+// runCLI executes args (subcommand first) and returns the process exit code
+func runCLI(args []string, stdout, stderr io.Writer) int {
+	c := &cli{stdout: stdout, stderr: stderr}
+	sub, ok := subcommands[args[0]]
+	if !ok {
+		return c.fail(usagef("unknown command %q", args[0]))
+	}
+	c.usage = sub.usage
+	err := sub.run(c, args[1:])
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	var uerr usageError
+	if errors.As(err, &uerr) && !uerr.reported {
+		fmt.Fprintf(stderr, "usage: control-center %s\n", sub.usage)
+	}
+	return c.fail(err)
+}
+
+// fail reports err and maps it to an exit code
+func (c *cli) fail(err error) int {
+	code := exitError
+	var uerr usageError
+	switch {
+	case errors.As(err, &uerr):
+		code = exitUsage
+		if uerr.reported {
+			return code
+		}
+	case errors.Is(err, orchestrator.ErrInvalidQuery):
+		code = exitUsage
+	case errors.Is(err, orchestrator.ErrTaskNotFound), errors.Is(err, orchestrator.ErrNoTask), errors.Is(err, orchestrator.ErrSearchNotFound):
+		code = exitNotFound
+	case errors.Is(err, orchestrator.ErrInvalidTransition),
+		errors.Is(err, orchestrator.ErrBlocked),
+		errors.Is(err, orchestrator.ErrDependencyCycle),
+		errors.Is(err, orchestrator.ErrSchemaTooNew),
+		errors.Is(err, orchestrator.ErrConfirmationRequired),
+		errors.Is(err, orchestrator.ErrProtected):
+		code = exitRefused
+	}
+
+	if c.json {
+		out, _ := json.Marshal(map[string]any{"error": err.Error(), "code": code})
+		fmt.Fprintln(c.stderr, string(out))
+	} else {
+		fmt.Fprintf(c.stderr, "control-center: %v\n", err)
+	}
+	return code
+}
+
+// flags returns a FlagSet for a subcommand with the shared --json flag
+func (c *cli) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: control-center %s\n", c.usage)
+		fs.PrintDefaults()
+	}
+	fs.BoolVar(&c.json, "json", false, "print machine-readable JSON")
+	return fs
+}
+
+// parse parses flags placed anywhere among the positional arguments
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, usageError{msg: err.Error(), reported: true}
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// parseID parses the single task ID argument of a subcommand
+func parseID(fs *flag.FlagSet, args []string) (int, error) {
+	rest, err := parse(fs, args)
+	if err != nil {
+		return 0, err
+	}
+	if len(rest) != 1 {
+		return 0, usagef("expected exactly one task ID")
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(rest[0], "#"))
+	if err != nil || id <= 0 {
+		return 0, usagef("invalid task ID %q", rest[0])
+	}
+	return id, nil
+}
+
+// parseDeps parses "1,2,#3" into task IDs
+func parseDeps(s string) ([]int, error) {
+	deps := []int{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimPrefix(strings.TrimSpace(part), "#")
+		if part == "" {
+			continue
+		}
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, usagef("invalid dependency %q", part)
+		}
+		deps = append(deps, id)
+	}
+	return deps, nil
+}
+
+// safetyFlags are the confirmations of subcommands guarded by the safety policy
+type safetyFlags struct {
+	yes     *bool
+	confirm *string
+}
+
+func addSafetyFlags(fs *flag.FlagSet) safetyFlags {
+	return safetyFlags{
+		yes:     fs.Bool("yes", false, "confirm an action the safety policy asks about"),
+		confirm: fs.String("confirm", "", "the task ID, for actions that need a typed confirmation"),
+	}
+}
+
+// check refuses action on task id unless the confirmation the safety policy
+// asks for was given
+func (f safetyFlags) check(action string, id int) error {
+	p, err := orchestrator.CurrentProject()
+	if err != nil {
+		return err
+	}
+	g, err := p.CheckSafety(action, []int{id})
+	if err != nil {
+		return err
+	}
+	return g.Confirmed(*f.yes, *f.confirm)
+}
+
+func store() (*orchestrator.TaskStore, error) {
+	p, err := orchestrator.CurrentProject()
+	if err != nil {
+		return nil, err
+	}
+	return p.Store(), nil
+}
+
+func (c *cli) list(args []string) error {
+	fs := c.flags("list")
+	status := fs.String("status", "", "only tasks with this status")
+	agent := fs.String("agent", "", "only tasks assigned to this agent")
+	query := fs.String("query", "", `only tasks matching a search, e.g. 'status:failed prio:high+ "login"' or @saved`)
+	if rest, err := parse(fs, args); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usagef("unexpected argument %q", rest[0])
+	}
+
+	p, err := orchestrator.CurrentProject()
+	if err != nil {
+		return err
+	}
+	q, err := p.Search(*query)
+	if err != nil {
+		return err
+	}
+	data, err := p.Store().Load()
+	if err != nil {
+		return err
+	}
+	tasks := []orchestrator.Task{}
+	for _, t := range data.Tasks {
+		if (*status == "" || t.Status == *status) && (*agent == "" || strings.EqualFold(t.Agent, *agent)) && q.Match(t) {
+			tasks = append(tasks, t)
+		}
+	}
+
+	if c.json {
+		return c.printJSON(tasks)
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tAGENT\tPRIORITY\tDEPS\tDESCRIPTION")
+	for _, t := range tasks {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", t.ID, t.Status, orDash(t.Agent), orDash(t.Priority), orDash(orchestrator.FormatIDs(t.Dependencies)), t.Description)
+	}
+	return w.Flush()
+}
+
+func (c *cli) show(args []string) error {
+	id, err := parseID(c.flags("show"), args)
+	if err != nil {
+		return err
+	}
+	s, err := store()
+	if err != nil {
+		return err
+	}
+	t, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	return c.printTask(t)
+}
+
+func (c *cli) add(args []string) error {
+	fs := c.flags("add")
+	agent := fs.String("agent", "", "agent to assign")
+	priority := fs.String("priority", "", "critical, high, normal or low")
+	deps := fs.String("deps", "", "comma-separated IDs this task depends on")
+	rest, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	desc := strings.TrimSpace(strings.Join(rest, " "))
+	if desc == "" {
+		return usagef("a description is required")
+	}
+	if *priority != "" && !orchestrator.ValidPriority(*priority) {
+		return usagef("invalid priority %q", *priority)
+	}
+	t := orchestrator.Task{Description: desc, Agent: *agent, Priority: *priority}
+	if t.Dependencies, err = parseDeps(*deps); err != nil {
+		return err
+	}
+
+	s, err := store()
+	if err != nil {
+		return err
+	}
+	if t, err = s.AddTask(t); err != nil {
+		return err
+	}
+	return c.printResult(t, "Added")
+}
+
+func (c *cli) start(args []string) error {
+	fs := c.flags("start")
+	force := fs.Bool("force", false, "start even if dependencies are unfinished")
+	safety := addSafetyFlags(fs)
+	id, err := parseID(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := safety.check(orchestrator.ActionStart, id); err != nil {
+		return err
+	}
+	t, err := orchestrator.StartTask(id, *force)
+	if err != nil {
+		return err
+	}
+	return c.printResult(t, "Started")
+}
+
+// stop marks a task as stopped and stops its agent, as the TUI does
+func (c *cli) stop(args []string) error {
+	return c.transition("stop", args, func(_ *orchestrator.TaskStore, id int) (orchestrator.Task, error) {
+		return orchestrator.StopTask(id)
+	}, "Stopped")
+}
+
+func (c *cli) complete(args []string) error {
+	return c.transition("complete", args, (*orchestrator.TaskStore).Complete, "Completed")
+}
+
+func (c *cli) transition(name string, args []string, fn func(*orchestrator.TaskStore, int) (orchestrator.Task, error), verb string) error {
+	fs := c.flags(name)
+	safety := addSafetyFlags(fs)
+	id, err := parseID(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := safety.check(name, id); err != nil {
+		return err
+	}
+	s, err := store()
+	if err != nil {
+		return err
+	}
+	t, err := fn(s, id)
+	if err != nil {
+		return err
+	}
+	return c.printResult(t, verb)
+}
+
+func (c *cli) remove(args []string) error {
+	fs := c.flags("remove")
+	safety := addSafetyFlags(fs)
+	id, err := parseID(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := safety.check(orchestrator.ActionRemove, id); err != nil {
+		return err
+	}
+	s, err := store()
+	if err != nil {
+		return err
+	}
+	if err := s.Remove(id); err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(map[string]int{"removed": id})
+	}
+	fmt.Fprintf(c.stdout, "Removed task #%d\n", id)
+	return nil
+}
+
+func (c *cli) edit(args []string) error {
+	fs := c.flags("edit")
+	desc := fs.String("desc", "", "new description")
+	agent := fs.String("agent", "", "new agent (empty string unassigns)")
+	priority := fs.String("priority", "", "new priority")
+	deps := fs.String("deps", "", "new comma-separated dependency IDs (empty string clears)")
+	safety := addSafetyFlags(fs)
+	id, err := parseID(fs, args)
+	if err != nil {
+		return err
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["desc"] && !set["agent"] && !set["priority"] && !set["deps"] {
+		return usagef("nothing to edit: pass --desc, --agent, --priority or --deps")
+	}
+	if set["priority"] && !orchestrator.ValidPriority(*priority) {
+		return usagef("invalid priority %q", *priority)
+	}
+	var newDeps []int
+	if set["deps"] {
+		if newDeps, err = parseDeps(*deps); err != nil {
+			return err
+		}
+	}
+	for _, guarded := range []struct{ flag, action string }{
+		{"desc", orchestrator.ActionEdit},
+		{"deps", orchestrator.ActionEdit},
+		{"agent", orchestrator.ActionAssign},
+		{"priority", orchestrator.ActionPriority},
+	} {
+		if set[guarded.flag] {
+			if err := safety.check(guarded.action, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	s, err := store()
+	if err != nil {
+		return err
+	}
+	t, err := s.Modify(id, func(t *orchestrator.Task) error {
+		if set["desc"] {
+			t.Description = *desc
+		}
+		if set["agent"] {
+			t.Agent = *agent
+		}
+		if set["priority"] {
+			t.Priority = *priority
+		}
+		if set["deps"] {
+			t.Dependencies = newDeps
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return c.printResult(t, "Edited")
+}
+
+// next prints the task an agent should pick up, most urgent first. With
+// --claim the task is also started and assigned to the agent, atomically,
+// so agent scripts polling at the same time never get the same task.
+func (c *cli) next(args []string) error {
+	fs := c.flags("next")
+	claim := fs.Bool("claim", false, "start the task and assign it to the agent")
+	rest, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return usagef("expected exactly one agent")
+	}
+	agent := rest[0]
+
+	s, err := store()
+	if err != nil {
+		return err
+	}
+	if *claim {
+		t, err := s.ClaimNext(agent)
+		if err != nil {
+			return err
+		}
+		return c.printResult(t, "Claimed")
+	}
+	data, err := s.Load()
+	if err != nil {
+		return err
+	}
+	t, ok := orchestrator.NextTask(data.Tasks, agent)
+	if !ok {
+		return fmt.Errorf("%w for %s", orchestrator.ErrNoTask, agent)
+	}
+	return c.printTask(t)
+}
+
+// archive moves finished tasks out of tasks.json, by the archive policy's
+// retention unless --older-than is given
+func (c *cli) archive(args []string) error {
+	fs := c.flags("archive")
+	olderThan := fs.Duration("older-than", 0, "archive tasks finished longer ago than this (default: the archive policy)")
+	if rest, err := parse(fs, args); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usagef("unexpected argument %q", rest[0])
+	}
+	p, err := orchestrator.CurrentProject()
+	if err != nil {
+		return err
+	}
+	var moved []orchestrator.ArchivedTask
+	if *olderThan > 0 {
+		moved, err = p.Archive(time.Now().Add(-*olderThan))
+	} else {
+		moved, err = p.ArchiveExpired()
+	}
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(archivedTasks(moved))
+	}
+	if len(moved) == 0 {
+		fmt.Fprintln(c.stdout, "Nothing to archive")
+		return nil
+	}
+	ids := make([]int, len(moved))
+	for i, a := range moved {
+		ids[i] = a.Task.ID
+	}
+	fmt.Fprintf(c.stdout, "Archived %d task(s): %s\n", len(moved), orchestrator.FormatIDs(ids))
+	return nil
+}
+
+// archived lists the archive, or the archived tasks matching QUERY
+func (c *cli) archived(args []string) error {
+	rest, err := parse(c.flags("archived"), args)
+	if err != nil {
+		return err
+	}
+	p, err := orchestrator.CurrentProject()
+	if err != nil {
+		return err
+	}
+	found, err := p.SearchArchive(strings.Join(rest, " "))
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJSON(archivedTasks(found))
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATUS\tAGENT\tFINISHED\tLOG\tDESCRIPTION")
+	for _, a := range found {
+		t := a.Task
+		finished := "-"
+		if at := orchestrator.Finished(t); !at.IsZero() {
+			finished = at.Format("2006-01-02")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", t.ID, t.Status, orDash(t.Agent), finished, orDash(a.Log), t.Description)
+	}
+	return w.Flush()
+}
+
+func (c *cli) restore(args []string) error {
+	id, err := parseID(c.flags("restore"), args)
+	if err != nil {
+		return err
+	}
+	p, err := orchestrator.CurrentProject()
+	if err != nil {
+		return err
+	}
+	t, err := p.Restore(id)
+	if err != nil {
+		return err
+	}
+	return c.printResult(t, "Restored")
+}
+
+// searches lists, saves or deletes the saved searches used as @NAME
+func (c *cli) searches(args []string) error {
+	rest, err := parse(c.flags("searches"), args)
+	if err != nil {
+		return err
+	}
+	p, err := orchestrator.CurrentProject()
+	if err != nil {
+		return err
+	}
+	var saved []orchestrator.SavedSearch
+	switch {
+	case len(rest) == 0:
+		saved, err = p.LoadSavedSearches()
+	case rest[0] == "save" && len(rest) >= 3:
+		saved, err = p.SaveSearch(rest[1], strings.Join(rest[2:], " "))
+	case rest[0] == "delete" && len(rest) == 2:
+		saved, err = p.DeleteSearch(rest[1])
+	default:
+		return usagef("expected no argument, save NAME QUERY or delete NAME")
+	}
+	if err != nil {
+		return err
+	}
+	if saved == nil {
+		saved = []orchestrator.SavedSearch{}
+	}
+	if c.json {
+		return c.printJSON(saved)
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tQUERY")
+	for _, s := range saved {
+		fmt.Fprintf(w, "@%s\t%s\n", s.Name, s.Query)
+	}
+	return w.Flush()
+}
+
+// archivedTasks keeps --json output a list when nothing matched
+func archivedTasks(list []orchestrator.ArchivedTask) []orchestrator.ArchivedTask {
+	if list == nil {
+		return []orchestrator.ArchivedTask{}
+	}
+	return list
+}
+
+// printResult prints the task after a mutation
+func (c *cli) printResult(t orchestrator.Task, verb string) error {
+	if c.json {
+		return c.printJSON(t)
+	}
+	fmt.Fprintf(c.stdout, "%s task #%d (%s)\n", verb, t.ID, t.Status)
+	return nil
+}
+
+func (c *cli) printTask(t orchestrator.Task) error {
+	if c.json {
+		return c.printJSON(t)
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID:\t#%d\n", t.ID)
+	fmt.Fprintf(w, "Description:\t%s\n", t.Description)
+	fmt.Fprintf(w, "Status:\t%s\n", t.Status)
+	fmt.Fprintf(w, "Agent:\t%s\n", orDash(t.Agent))
+	fmt.Fprintf(w, "Priority:\t%s\n", orDash(t.Priority))
+	fmt.Fprintf(w, "Dependencies:\t%s\n", orDash(orchestrator.FormatIDs(t.Dependencies)))
+	fmt.Fprintf(w, "Progress:\t%d%%\n", t.Progress)
+	fmt.Fprintf(w, "Created:\t%s\n", orDash(t.CreatedAt))
+	fmt.Fprintf(w, "Updated:\t%s\n", orDash(t.UpdatedAt))
+	fmt.Fprintf(w, "Started:\t%s\n", orDash(t.StartedAt))
+	fmt.Fprintf(w, "Completed:\t%s\n", orDash(t.CompletedAt))
+	return w.Flush()
+}
+
+func (c *cli) printJSON(v any) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(c.stdout, string(out))
+	return err
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+func newTestProject(t *testing.T) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".claude"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".claude", "tasks.json"), []byte(`{"tasks": [], "last_id": 0}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	project, err := orchestrator.FindProject(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orchestrator.SetProject(project)
+}
+
+func run(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := runCLI(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCLILifecycle(t *testing.T) {
+	newTestProject(t)
+
+	code, out, errOut := run(t, "add", "--json", "Design", "schema", "--priority", "high")
+	if code != exitOK {
+		t.Fatalf("add failed (%d): %s", code, errOut)
+	}
+	var added orchestrator.Task
+	if err := json.Unmarshal([]byte(out), &added); err != nil {
+		t.Fatalf("add --json output is not a task: %v\n%s", err, out)
+	}
+	if added.ID != 1 || added.Description != "Design schema" || added.Priority != "high" {
+		t.Errorf("unexpected task %+v", added)
+	}
+
+	if code, _, errOut := run(t, "add", "--deps", "1", "Build API"); code != exitOK {
+		t.Fatalf("add with deps failed (%d): %s", code, errOut)
+	}
+	if code, _, _ := run(t, "start", "2"); code != exitRefused {
+		t.Errorf("starting a blocked task should exit %d, got %d", exitRefused, code)
+	}
+	if code, _, errOut := run(t, "complete", "1"); code != exitOK {
+		t.Fatalf("complete failed (%d): %s", code, errOut)
+	}
+	if code, _, errOut := run(t, "edit", "2", "--desc", "Build REST API", "--deps", ""); code != exitOK {
+		t.Fatalf("edit failed (%d): %s", code, errOut)
+	}
+
+	code, out, _ = run(t, "list", "--status", "pending", "--json")
+	var pending []orchestrator.Task
+	if err := json.Unmarshal([]byte(out), &pending); err != nil || code != exitOK {
+		t.Fatalf("list --json failed (%d, %v): %s", code, err, out)
+	}
+	if len(pending) != 1 || pending[0].Description != "Build REST API" || len(pending[0].Dependencies) != 0 {
+		t.Errorf("unexpected pending list %+v", pending)
+	}
+
+	if code, out, _ := run(t, "show", "2"); code != exitOK || !strings.Contains(out, "Build REST API") {
+		t.Errorf("show failed (%d): %s", code, out)
+	}
+	if code, _, _ := run(t, "remove", "2"); code != exitRefused {
+		t.Errorf("remove without --yes should exit %d, got %d", exitRefused, code)
+	}
+	if code, _, _ := run(t, "remove", "2", "--yes"); code != exitOK {
+		t.Errorf("remove failed: %d", code)
+	}
+}
+
+func TestCLISafetyPolicy(t *testing.T) {
+	newTestProject(t)
+	run(t, "add", "Design schema")
+	run(t, "add", "--deps", "1", "Build API")
+
+	code, _, errOut := run(t, "remove", "1", "--yes")
+	if code != exitRefused || !strings.Contains(errOut, "--confirm 1") {
+		t.Errorf("removing a task with dependents should ask for --confirm 1 (%d): %s", code, errOut)
+	}
+	if code, _, errOut := run(t, "remove", "1", "--confirm", "1"); code != exitOK {
+		t.Errorf("typed confirmation failed (%d): %s", code, errOut)
+	}
+
+	p, _ := orchestrator.CurrentProject()
+	if err := p.Store().Update(func(data *orchestrator.TasksData) error {
+		data.Find(2).Status = orchestrator.StatusPendingApproval
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, errOut := run(t, "stop", "2", "--yes"); code != exitRefused || !strings.Contains(errOut, "waits for approval") {
+		t.Errorf("a task waiting for approval should be protected (%d): %s", code, errOut)
+	}
+
+	if err := os.WriteFile(p.SafetyPolicyPath(), []byte(`{"actions": {"stop": "none"}, "pending_approval": "none"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, errOut := run(t, "stop", "2"); code != exitOK {
+		t.Errorf("the policy file should allow stopping (%d): %s", code, errOut)
+	}
+}
+
+func TestCLIExitCodes(t *testing.T) {
+	newTestProject(t)
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"show", "42"}, exitNotFound},
+		{[]string{"stop", "abc"}, exitUsage},
+		{[]string{"add"}, exitUsage},
+		{[]string{"edit", "1"}, exitUsage},
+		{[]string{"list", "--bogus"}, exitUsage},
+	}
+	for _, tt := range tests {
+		if code, _, _ := run(t, tt.args...); code != tt.want {
+			t.Errorf("%v: expected exit %d, got %d", tt.args, tt.want, code)
+		}
+	}
+
+	code, _, errOut := run(t, "complete", "7", "--json")
+	var reply struct {
+		Error string `json:"error"`
+		Code  int    `json:"code"`
+	}
+	if err := json.Unmarshal([]byte(errOut), &reply); err != nil || code != exitNotFound || reply.Code != exitNotFound {
+		t.Errorf("expected JSON error with code %d, got %d: %s", exitNotFound, code, errOut)
+	}
+}
+
+func TestCLINext(t *testing.T) {
+	newTestProject(t)
+	run(t, "add", "--agent", "backend", "--priority", "low", "Tidy logs")
+	run(t, "add", "--agent", "backend", "--priority", "critical", "Fix outage")
+	run(t, "add", "--priority", "high", "Unassigned")
+
+	if code, out, _ := run(t, "next", "backend"); code != exitOK || !strings.Contains(out, "Fix outage") {
+		t.Errorf("expected the critical task first (%d): %s", code, out)
+	}
+	code, out, _ := run(t, "next", "backend", "--claim", "--json")
+	var claimed orchestrator.Task
+	if err := json.Unmarshal([]byte(out), &claimed); err != nil || code != exitOK || claimed.ID != 2 || claimed.Status != orchestrator.StatusInProgress {
+		t.Fatalf("unexpected claim (%d): %s", code, out)
+	}
+	if code, out, _ := run(t, "next", "frontend", "--claim"); code != exitOK || !strings.Contains(out, "#3") {
+		t.Errorf("expected frontend to take the unassigned task (%d): %s", code, out)
+	}
+	run(t, "next", "backend", "--claim")
+	if code, _, _ := run(t, "next", "backend"); code != exitNotFound {
+		t.Errorf("expected exit %d with nothing ready, got %d", exitNotFound, code)
+	}
+	if code, _, _ := run(t, "add", "--priority", "urgent", "x"); code != exitUsage {
+		t.Errorf("expected an unknown priority to be a usage error, got %d", code)
+	}
+}
+
+func TestCLIArchive(t *testing.T) {
+	newTestProject(t)
+	run(t, "add", "Ship v1")
+	run(t, "add", "Write notes")
+	run(t, "complete", "1")
+
+	if code, out, _ := run(t, "archive"); code != exitOK || !strings.Contains(out, "Nothing to archive") {
+		t.Errorf("expected nothing archived within the retention (%d): %s", code, out)
+	}
+	code, out, errOut := run(t, "archive", "--older-than", "1ns", "--json")
+	var moved []orchestrator.ArchivedTask
+	if err := json.Unmarshal([]byte(out), &moved); err != nil || code != exitOK || len(moved) != 1 || moved[0].Task.ID != 1 {
+		t.Fatalf("unexpected archive (%d): %s%s", code, out, errOut)
+	}
+	if _, out, _ := run(t, "list"); strings.Contains(out, "Ship v1") {
+		t.Errorf("expected #1 gone from the task list: %s", out)
+	}
+	if code, out, _ := run(t, "archived", "ship"); code != exitOK || !strings.Contains(out, "Ship v1") {
+		t.Errorf("expected #1 in the archive (%d): %s", code, out)
+	}
+	if _, out, _ := run(t, "archived", "--json", "nothing like this"); strings.TrimSpace(out) != "[]" {
+		t.Errorf("expected an empty JSON list, got %s", out)
+	}
+	if code, out, _ := run(t, "restore", "1"); code != exitOK || !strings.Contains(out, "Restored") {
+		t.Errorf("unexpected restore (%d): %s", code, out)
+	}
+	if code, _, _ := run(t, "restore", "1"); code != exitNotFound {
+		t.Errorf("expected exit %d restoring twice, got %d", exitNotFound, code)
+	}
+}
+
+func TestCLISearch(t *testing.T) {
+	newTestProject(t)
+	run(t, "add", "--agent", "backend", "--priority", "high", "Login API")
+	run(t, "add", "--agent", "frontend", "Login form")
+	run(t, "add", "--agent", "backend", "Schema")
+
+	code, out, _ := run(t, "list", "--query", `agent:backend "login"`, "--json")
+	var tasks []orchestrator.Task
+	if err := json.Unmarshal([]byte(out), &tasks); err != nil || code != exitOK || len(tasks) != 1 || tasks[0].ID != 1 {
+		t.Fatalf("unexpected search (%d): %s", code, out)
+	}
+	if code, _, _ := run(t, "list", "--query", "owner:me"); code != exitUsage {
+		t.Errorf("expected an invalid query to be a usage error, got %d", code)
+	}
+
+	if code, out, errOut := run(t, "searches", "save", "mine", "agent:backend -schema"); code != exitOK || !strings.Contains(out, "@mine") {
+		t.Fatalf("unexpected save (%d): %s%s", code, out, errOut)
+	}
+	if code, out, _ := run(t, "list", "--query", "@mine"); code != exitOK || !strings.Contains(out, "Login API") || strings.Contains(out, "Schema") {
+		t.Errorf("expected the saved search applied (%d): %s", code, out)
+	}
+	if code, out, _ := run(t, "searches", "delete", "mine", "--json"); code != exitOK || strings.TrimSpace(out) != "[]" {
+		t.Errorf("unexpected delete (%d): %s", code, out)
+	}
+	if code, _, _ := run(t, "list", "--query", "@mine"); code != exitNotFound {
+		t.Errorf("expected exit %d for a missing saved search, got %d", exitNotFound, code)
+	}
+}
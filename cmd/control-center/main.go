@@ -1,19 +1,61 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"sort"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"shineos/claude-orchestra/internal/orchestrator"
 	"shineos/claude-orchestra/internal/ui"
 )
 
 func main() {
+	projectFlag := flag.String("project", "", "project root (or its .claude directory); defaults to $"+orchestrator.ProjectEnvVar+" or the nearest .claude/ above the current directory")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() > 0 && !isSubcommand(flag.Arg(0)) {
+		fmt.Fprintf(os.Stderr, "control-center: unknown command %q\n", flag.Arg(0))
+		usage()
+		os.Exit(exitUsage)
+	}
+
+	project, err := orchestrator.FindProject(*projectFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "control-center: %v\n", err)
+		os.Exit(1)
+	}
+	orchestrator.SetProject(project)
+
+	// Subcommands run headless; without one we start the dashboard
+	if flag.NArg() > 0 {
+		os.Exit(runCLI(flag.Args(), os.Stdout, os.Stderr))
+	}
+
 	// Create and start the program
 	p := tea.NewProgram(ui.InitialModel(), tea.WithAltScreen(), tea.WithMouseCellMotion())
 
-    if _, err := p.Run(); err != nil {
+	if _, err := p.Run(); err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
 		os.Exit(1)
 	}
 }
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "usage: control-center [--project DIR] [command]")
+	fmt.Fprintln(out, "\nWithout a command the interactive dashboard starts. Commands:")
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %s\n", subcommands[name].usage)
+	}
+	fmt.Fprintln(out, "\nExit codes: 0 ok, 1 error, 2 usage, 3 task not found, 4 refused (transition, dependencies)")
+	fmt.Fprintln(out, "\nOptions:")
+	flag.PrintDefaults()
+}
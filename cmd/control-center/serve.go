@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"shineos/claude-orchestra/internal/api"
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+const defaultServeAddr = "127.0.0.1:7878"
+
+// serve runs the HTTP/JSON API until interrupted
+func (c *cli) serve(args []string) error {
+	fs := c.flags("serve")
+	addr := fs.String("addr", defaultServeAddr, "loopback address to listen on")
+	socket := fs.String("socket", "", "listen on this Unix socket instead of TCP")
+	if rest, err := parse(fs, args); err != nil {
+		return err
+	} else if len(rest) > 0 {
+		return usagef("unexpected argument %q", rest[0])
+	}
+
+	project, err := orchestrator.CurrentProject()
+	if err != nil {
+		return err
+	}
+	ln, err := listen(*addr, *socket)
+	if err != nil {
+		return err
+	}
+
+	handler := api.NewServer(project)
+	if *socket == "" {
+		// Any local process, including a browser tab, can reach a TCP port
+		token, err := api.LoadToken(project)
+		if err == nil {
+			err = handler.RequireAuth(ln.Addr().String(), token)
+		}
+		if err != nil {
+			ln.Close()
+			return err
+		}
+	}
+
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(c.stderr, "control-center: serving %s on %s\n", project.Root, ln.Addr())
+	if *socket == "" {
+		fmt.Fprintf(c.stderr, "control-center: send \"Authorization: Bearer <token>\" with the token in %s\n", api.TokenPath(project))
+	}
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// listen opens a Unix socket (owner-only) or a TCP listener restricted to
+// loopback addresses
+func listen(addr, socket string) (net.Listener, error) {
+	if socket != "" {
+		if info, err := os.Lstat(socket); err == nil && info.Mode()&os.ModeSocket != 0 {
+			// Left behind by a previous run that did not shut down cleanly
+			os.Remove(socket)
+		}
+		ln, err := net.Listen("unix", socket)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chmod(socket, 0600); err != nil {
+			ln.Close()
+			return nil, err
+		}
+		return ln, nil
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, usagef("invalid address %q: %v", addr, err)
+	}
+	if host != "localhost" {
+		if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+			return nil, usagef("refusing to listen on %s: only loopback addresses are allowed", addr)
+		}
+	}
+	return net.Listen("tcp", addr)
+}
@@ -5,10 +5,10 @@ import (
 	"os"
 	"strings"
 	"shineos/claude-orchestra/internal/orchestrator"
-	
-	tea "github.com/charmbracelet/bubbletea"
 )
 
+var store *orchestrator.TaskStore
+
 func main() {
 	// Change directory to target env for testing
 	targetDir := "/Users/grace/dev/shineos/shineos-saas-starter"
@@ -17,11 +17,21 @@ func main() {
 		fmt.Printf("Error changing dir: %v\n", err)
 		os.Exit(1)
 	}
+	project, err := orchestrator.FindProject(targetDir)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	orchestrator.SetProject(project)
+	store = project.Store()
 	fmt.Printf("Running scenario test in %s\n", targetDir)
 
 	// 1. Add Task
 	fmt.Println(">> Adding Task: 'Integration Test Task'")
-	performCmd(orchestrator.AddTaskCmd("Integration Test Task", ""))
+	if _, err := store.Add("Integration Test Task", "", ""); err != nil {
+		fmt.Printf("Command Failed: %v\n", err)
+		os.Exit(1)
+	}
 
 
 
@@ -43,7 +53,7 @@ func main() {
 
 	// 3. Start Task
 	fmt.Printf(">> Starting Task #%d\n", testTask.ID)
-	performCmd(orchestrator.StartTaskCmd(testTask.ID))
+	perform(store.Start(testTask.ID))
 	
 	// Verify Status
 	tasks = fetchTasks()
@@ -66,7 +76,7 @@ func main() {
 
 	// 4. Complete Task
 	fmt.Printf(">> Completing Task #%d\n", testTask.ID)
-	performCmd(orchestrator.CompleteTaskCmd(testTask.ID))
+	perform(store.Complete(testTask.ID))
 
 	// Verify Status
 	tasks = fetchTasks()
@@ -86,18 +96,17 @@ func main() {
 	fmt.Println(">> SCENARIO TEST PASSED")
 }
 
-func performCmd(cmd tea.Cmd) {
-	msg := cmd()
-	if errMsg, ok := msg.(orchestrator.ErrorMsg); ok {
-		fmt.Printf("Command Failed: %v\n", errMsg)
+func perform(_ orchestrator.Task, err error) {
+	if err != nil {
+		fmt.Printf("Command Failed: %v\n", err)
 		os.Exit(1)
 	}
 }
 
 func fetchTasks() []orchestrator.Task {
-	msg := orchestrator.FetchTasksCmd()()
-	if tasks, ok := msg.(orchestrator.TaskLoadMsg); ok {
-		return []orchestrator.Task(tasks)
+	data, err := store.Load()
+	if err != nil {
+		return nil
 	}
-	return nil
+	return data.Tasks
 }
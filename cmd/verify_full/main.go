@@ -3,14 +3,15 @@ package main
 import (
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"shineos/claude-orchestra/internal/orchestrator"
 )
 
+var store *orchestrator.TaskStore
+
 func main() {
 	// Change directory to target env for testing
 	targetDir := "/Users/grace/dev/shineos/shineos-saas-starter"
@@ -19,6 +20,13 @@ func main() {
 		fmt.Printf("Error changing dir: %v\n", err)
 		os.Exit(1)
 	}
+	project, err := orchestrator.FindProject(targetDir)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	orchestrator.SetProject(project)
+	store = project.Store()
 	fmt.Printf("Running full verification in %s\n", targetDir)
 
 	taskName := "Process Verification Task"
@@ -26,35 +34,8 @@ func main() {
 	// 1. Add Task (using dummy agent to test process launch)
 	fmt.Println(">> [Add] Adding Task for 'dummy' agent...")
 	
-	// We need to modify AddTaskCmd to accept agent or modify orchestrator usage?
-	// AddTaskCmd only takes desc. 
-	// orchestrator.sh add <desc> [agent] [priority]
-	// The client.go AddTaskCmd func currently hardcodes: exec.Command("bash", scriptPath, "add", desc)
-	// It doesn't allow agent spec.
-	// But `orchestrator.sh` auto-detects agent from keywords if not specified.
-	// Let's modify AddTaskCmd in client.go to allow agent arg? No, that changes signature.
-	// We can cheat by including agent name in desc if auto-detection works? 
-	// Or simplistic: Just modify client.go for the test? 
-	// Or update client.go to support optional args?
-	// Actually, let's just use "Check dummy agent functionality" as desc - if auto-detection maps 'dummy' keyword?
-	// The `orchestrator.sh` has a list of keywords. `dummy` is not in it.
-	// We should update client.go to support AddTask with Agent arg, OR checks `AddTaskCmd` implementation.
-	// Implementation: `func AddTaskCmd(desc string) tea.Cmd`
-	// We can temporarily update client.go or just append to desc if the script parses it? No.
-	// Wait, we can modify client.go to `AddTaskCmd(desc string, agent string)`?
-	// That would require updating `update.go` call site.
-	// Let's update client.go to `AddTaskCmd(desc string)` but internally use a new func like `AddTaskWithAgentCmd`.
-	// Or better: update `orchestrator.sh` logic or use a raw exec command here in main.go for setup?
-	// Yes, let's use raw exec for the ADD step to ensure 'dummy' agent is used.
-	
-	// performCmd(orchestrator.AddTaskCmd(taskName)) <- Replaced by manual add to specify agent
-	scriptPath := ".claude/scripts/orchestrator.sh"
-	addCmd := exec.Command("bash", scriptPath, "add", taskName, "dummy", "high")
-	// Ensure auto-launch is ENABLED for this test
-	addCmd.Env = append(os.Environ(), "ORCH_AUTO_CONFIRM=yes", "USE_AI=false", "ORCH_NO_AUTO_LAUNCH=false")
-	out, err := addCmd.CombinedOutput()
-	if err != nil {
-		fmt.Printf("Failed to add task manually: %v\nOutput: %s\n", err, out)
+	if _, err := store.Add(taskName, "dummy", "high"); err != nil {
+		fmt.Printf("Failed to add task: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -76,9 +57,11 @@ func main() {
 
 	// 3. Start Task
 	fmt.Printf(">> [Start] Starting Task #%d...\n", testTask.ID)
-	// Ensure StartTaskCmd respects env? client.go doesn't set env vars in StartTaskCmd.
-	// orchestrator.sh default is to launch.
-	performCmd(orchestrator.StartTaskCmd(testTask.ID))
+	// StartTask also spawns the dummy agent since it is not running yet
+	if _, err := orchestrator.StartTask(testTask.ID, false); err != nil {
+		fmt.Printf("Command Failed: %v\n", err)
+		os.Exit(1)
+	}
 	
 	// Verify Status -> in_progress
 	// AND Verify PID file exists!
@@ -103,7 +86,7 @@ func main() {
 	}
 
 	// Verify Process
-	pidFile := fmt.Sprintf(".claude/pids/dummy.pid")
+	pidFile := filepath.Join(project.PidsDir(), "dummy.pid")
 	if _, err := os.Stat(pidFile); err == nil {
 		fmt.Printf(">> Verified PID file exists: %s\n", pidFile)
 		// Check if process runs? 
@@ -118,7 +101,10 @@ func main() {
 
 	// 4. Stop Task
 	fmt.Printf(">> [Stop] Stopping Task #%d...\n", testTask.ID)
-	performCmd(orchestrator.StopTaskCmd(testTask.ID))
+	if _, err := store.Stop(testTask.ID); err != nil {
+		fmt.Printf("Command Failed: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Verify Status
 	time.Sleep(1 * time.Second)
@@ -145,7 +131,10 @@ func main() {
 
 	// 6. Remove Task
 	fmt.Printf(">> [Delete] Removing Task #%d...\n", testTask.ID)
-	performCmd(orchestrator.RemoveTaskCmd(testTask.ID))
+	if err := store.Remove(testTask.ID); err != nil {
+		fmt.Printf("Command Failed: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Verify Removed
 	tasks = fetchTasks()
@@ -160,20 +149,10 @@ func main() {
 	fmt.Println(">> FULL VERIFICATION PASSED")
 }
 
-func performCmd(cmd tea.Cmd) {
-	msg := cmd()
-	if errMsg, ok := msg.(orchestrator.ErrorMsg); ok {
-		fmt.Printf("Command Failed: %v\n", errMsg)
-		os.Exit(1)
-	}
-	// Also for AddTask, msg is TaskLoadMsg (from FetchTasksCmd)
-	// We don't check it here, assuming fetchTasks() below will verify state.
-}
-
 func fetchTasks() []orchestrator.Task {
-	msg := orchestrator.FetchTasksCmd()()
-	if tasks, ok := msg.(orchestrator.TaskLoadMsg); ok {
-		return []orchestrator.Task(tasks)
+	data, err := store.Load()
+	if err != nil {
+		return nil
 	}
-	return nil
+	return data.Tasks
 }
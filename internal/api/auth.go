@@ -0,0 +1,86 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+// TokenPath returns .claude/api-token, the bearer token of the TCP API
+func TokenPath(p *orchestrator.Project) string {
+	return p.Path("api-token")
+}
+
+// LoadToken reads the bearer token of the TCP API, creating a random one
+// readable only by the owner on first use
+func LoadToken(p *orchestrator.Project) (string, error) {
+	path := TokenPath(p)
+	if b, err := os.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(b)); token != "" {
+			return token, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read api-token: %w", err)
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(raw)
+	if err := orchestrator.WriteFileAtomic(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write api-token: %w", err)
+	}
+	// WriteFileAtomic keeps the mode of an existing file
+	if err := os.Chmod(path, 0600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RequireAuth restricts a server listening on the TCP address addr: the Host
+// header must name a loopback host with its port, which defeats DNS
+// rebinding, and every request must carry "Authorization: Bearer token"
+func (s *Server) RequireAuth(addr, token string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	s.hosts = map[string]bool{}
+	for _, host := range []string{"localhost", "127.0.0.1", "[::1]"} {
+		s.hosts[host+":"+port] = true
+	}
+	s.token = token
+	return nil
+}
+
+// check refuses the requests a web page in the user's browser could send:
+// anything with an Origin header, bodies that are not JSON and, when
+// RequireAuth was called, foreign Host headers and missing tokens
+func (s *Server) check(r *http.Request) error {
+	if r.Header.Get("Origin") != "" {
+		return &httpError{http.StatusForbidden, "cross-origin requests are not allowed"}
+	}
+	if s.hosts != nil && !s.hosts[strings.ToLower(r.Host)] {
+		return &httpError{http.StatusForbidden, fmt.Sprintf("unexpected host %q", r.Host)}
+	}
+	if s.token != "" {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(s.token)) != 1 {
+			return &httpError{http.StatusUnauthorized, "missing or invalid bearer token (see .claude/api-token)"}
+		}
+	}
+	if r.ContentLength != 0 && r.Method != http.MethodGet {
+		if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct != "application/json" {
+			return &httpError{http.StatusUnsupportedMediaType, "request bodies must be application/json"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+// httpError is an error with an explicit status code
+type httpError struct {
+	status int
+	msg    string
+}
+
+func (e *httpError) Error() string { return e.msg }
+
+func badRequest(format string, args ...any) error {
+	return &httpError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+// statusFor maps orchestrator errors to HTTP status codes
+func statusFor(err error) int {
+	var herr *httpError
+	switch {
+	case errors.As(err, &herr):
+		return herr.status
+	case errors.Is(err, orchestrator.ErrTaskNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, orchestrator.ErrProtected):
+		return http.StatusForbidden
+	case errors.Is(err, orchestrator.ErrConfirmationRequired):
+		return http.StatusConflict
+	case errors.Is(err, orchestrator.ErrInvalidTransition),
+		errors.Is(err, orchestrator.ErrBlocked),
+		errors.Is(err, orchestrator.ErrDependencyCycle),
+		errors.Is(err, orchestrator.ErrSchemaTooNew):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// writeError replies with {"error": "..."}
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusFor(err), map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func decodeBody(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func taskID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		return 0, badRequest("invalid task ID %q", r.PathValue("id"))
+	}
+	return id, nil
+}
@@ -0,0 +1,494 @@
+// Package api serves the orchestra over a local HTTP/JSON API so editor
+// plugins and web boards do not have to parse tasks.json themselves.
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+const (
+	defaultLogTail = 200
+	maxLogTail     = 5000
+	heartbeatEvery = 15 * time.Second
+)
+
+// Server is an http.Handler exposing tasks, agents, logs and actions
+type Server struct {
+	project *orchestrator.Project
+	store   *orchestrator.TaskStore
+	mux     *http.ServeMux
+	hosts   map[string]bool // allowed Host headers; nil accepts any
+	token   string          // bearer token required on every request, if set
+}
+
+// NewServer returns a Server for project
+func NewServer(project *orchestrator.Project) *Server {
+	s := &Server{project: project, store: project.Store(), mux: http.NewServeMux()}
+
+	s.mux.HandleFunc("GET /api/tasks", s.listTasks)
+	s.mux.HandleFunc("POST /api/tasks", s.addTask)
+	s.mux.HandleFunc("GET /api/tasks/{id}", s.getTask)
+	s.mux.HandleFunc("PATCH /api/tasks/{id}", s.editTask)
+	s.mux.HandleFunc("DELETE /api/tasks/{id}", s.removeTask)
+	s.mux.HandleFunc("POST /api/tasks/{id}/start", s.startTask)
+	s.mux.HandleFunc("POST /api/tasks/{id}/stop", s.transition(orchestrator.ActionStop, project.StopTask))
+	s.mux.HandleFunc("POST /api/tasks/{id}/complete", s.transition(orchestrator.ActionComplete, s.store.Complete))
+
+	s.mux.HandleFunc("GET /api/agents", s.listAgents)
+	s.mux.HandleFunc("POST /api/agents/{name}/spawn", s.spawnAgent)
+	s.mux.HandleFunc("POST /api/agents/{name}/stop", s.agentAction(project.StopAgent, "stopped"))
+	s.mux.HandleFunc("POST /api/agents/{name}/restart", s.agentAction(s.restartAgent, "restarted"))
+
+	s.mux.HandleFunc("GET /api/logs", s.listLogs)
+	s.mux.HandleFunc("GET /api/logs/{name}", s.readLog)
+
+	s.mux.HandleFunc("GET /api/events", s.events)
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := s.check(r); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// taskInput is the body of POST /api/tasks and PATCH /api/tasks/{id}.
+// Omitted fields are left unchanged on PATCH.
+type taskInput struct {
+	Description  *string `json:"description"`
+	Agent        *string `json:"agent"`
+	Priority     *string `json:"priority"`
+	Dependencies *[]int  `json:"dependencies"`
+}
+
+// AgentInfo is one entry of GET /api/agents
+type AgentInfo struct {
+	orchestrator.AgentStatus
+	Tasks []int `json:"tasks"` // in_progress tasks assigned to the agent
+}
+
+// LogInfo is one entry of GET /api/logs
+type LogInfo struct {
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Modified string `json:"modified"`
+}
+
+func (s *Server) listTasks(w http.ResponseWriter, r *http.Request) {
+	data, err := s.store.Load()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	status, agent := r.URL.Query().Get("status"), r.URL.Query().Get("agent")
+	q, err := orchestrator.ParseQuery(r.URL.Query().Get("q"))
+	if err != nil {
+		writeError(w, badRequest("%v", err))
+		return
+	}
+	tasks := []orchestrator.Task{}
+	for _, t := range data.Tasks {
+		if (status == "" || t.Status == status) && (agent == "" || strings.EqualFold(t.Agent, agent)) && q.Match(t) {
+			tasks = append(tasks, t)
+		}
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+func (s *Server) addTask(w http.ResponseWriter, r *http.Request) {
+	var in taskInput
+	if err := decodeBody(r, &in); err != nil {
+		writeError(w, err)
+		return
+	}
+	if in.Description == nil || strings.TrimSpace(*in.Description) == "" {
+		writeError(w, badRequest("description is required"))
+		return
+	}
+	var t orchestrator.Task
+	in.apply(&t)
+	t, err := s.store.AddTask(t)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, t)
+}
+
+func (s *Server) getTask(w http.ResponseWriter, r *http.Request) {
+	id, err := taskID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	t, err := s.store.Get(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) editTask(w http.ResponseWriter, r *http.Request) {
+	id, err := taskID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var in taskInput
+	if err := decodeBody(r, &in); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.checkSafety(r, id, in.actions()...); err != nil {
+		writeError(w, err)
+		return
+	}
+	t, err := s.store.Modify(id, func(t *orchestrator.Task) error {
+		in.apply(t)
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) removeTask(w http.ResponseWriter, r *http.Request) {
+	id, err := taskID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.checkSafety(r, id, orchestrator.ActionRemove); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.store.Remove(id); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// startTask also spawns the task's agent; ?force=true ignores dependencies
+func (s *Server) startTask(w http.ResponseWriter, r *http.Request) {
+	id, err := taskID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.checkSafety(r, id, orchestrator.ActionStart); err != nil {
+		writeError(w, err)
+		return
+	}
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+	t, err := s.project.StartTask(id, force)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) transition(action string, fn func(id int) (orchestrator.Task, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := taskID(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if err := s.checkSafety(r, id, action); err != nil {
+			writeError(w, err)
+			return
+		}
+		t, err := fn(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, t)
+	}
+}
+
+func (s *Server) listAgents(w http.ResponseWriter, r *http.Request) {
+	agents, err := s.agents()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, agents)
+}
+
+func (s *Server) agents() ([]AgentInfo, error) {
+	data, err := s.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	agents := []AgentInfo{}
+	for _, st := range s.project.AgentStatuses() {
+		info := AgentInfo{AgentStatus: st, Tasks: []int{}}
+		for _, t := range data.Tasks {
+			if strings.EqualFold(t.Agent, st.Name) && t.Status == orchestrator.StatusInProgress {
+				info.Tasks = append(info.Tasks, t.ID)
+			}
+		}
+		agents = append(agents, info)
+	}
+	return agents, nil
+}
+
+func (s *Server) spawnAgent(w http.ResponseWriter, r *http.Request) {
+	name, err := agentName(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if _, running := s.project.AgentPID(name); running {
+		writeError(w, &httpError{http.StatusConflict, fmt.Sprintf("agent %s is already running", name)})
+		return
+	}
+	if err := s.project.SpawnAgent(name); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"spawned": name})
+}
+
+func (s *Server) agentAction(fn func(name string) error, verb string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, err := agentName(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if err := fn(name); err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{verb: name})
+	}
+}
+
+func (s *Server) restartAgent(name string) error {
+	if err := s.project.StopAgent(name); err != nil {
+		return err
+	}
+	return s.project.SpawnAgent(name)
+}
+
+func agentName(r *http.Request) (string, error) {
+	name := r.PathValue("name")
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", badRequest("invalid agent name %q", name)
+	}
+	return name, nil
+}
+
+func (s *Server) listLogs(w http.ResponseWriter, r *http.Request) {
+	matches, _ := filepath.Glob(filepath.Join(s.project.LogsDir(), "*.log"))
+	logs := []LogInfo{}
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil {
+			continue
+		}
+		logs = append(logs, LogInfo{
+			Name:     filepath.Base(m),
+			Size:     info.Size(),
+			Modified: info.ModTime().UTC().Format(time.RFC3339),
+		})
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].Modified > logs[j].Modified })
+	writeJSON(w, http.StatusOK, logs)
+}
+
+// readLog returns the last ?tail=N lines of a log file as plain text
+func (s *Server) readLog(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name != filepath.Base(name) || !strings.HasSuffix(name, ".log") {
+		writeError(w, badRequest("invalid log name %q", name))
+		return
+	}
+	n := defaultLogTail
+	if v := r.URL.Query().Get("tail"); v != "" {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil || n <= 0 {
+			writeError(w, badRequest("invalid tail %q", v))
+			return
+		}
+		n = min(n, maxLogTail)
+	}
+
+	f, err := os.Open(filepath.Join(s.project.LogsDir(), name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = &httpError{http.StatusNotFound, fmt.Sprintf("log %s not found", name)}
+		}
+		writeError(w, err)
+		return
+	}
+	defer f.Close()
+	lines, err := tailLines(f, n)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// events streams changes as Server-Sent Events: "tasks" and "agents" carry
+// full snapshots, "log" names the log file that grew
+func (s *Server) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, errors.New("streaming is not supported"))
+		return
+	}
+	watcher, err := orchestrator.NewWatcher(s.project.WatchPaths())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer watcher.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event string, v any) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	}
+	sendTasks := func() {
+		if data, err := s.store.Load(); err == nil {
+			send("tasks", data.Tasks)
+		}
+	}
+	sendAgents := func() {
+		if agents, err := s.agents(); err == nil {
+			send("agents", agents)
+		}
+	}
+
+	sendTasks()
+	sendAgents()
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatEvery)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case paths, ok := <-watcher.Events():
+			if !ok {
+				return
+			}
+			var tasksChanged, agentsChanged bool
+			for _, path := range paths {
+				switch {
+				case path == s.project.TasksPath():
+					tasksChanged = true
+				case filepath.Dir(path) == s.project.PidsDir():
+					agentsChanged = true
+				case filepath.Dir(path) == s.project.LogsDir():
+					send("log", map[string]string{"name": filepath.Base(path)})
+				}
+			}
+			if tasksChanged {
+				sendTasks()
+			}
+			if tasksChanged || agentsChanged {
+				sendAgents()
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// checkSafety applies the safety policy to actions on task id, as the CLI
+// does: ?confirm=yes (or the task ID) confirms, ?confirm=ID is needed where
+// the ID must be typed
+func (s *Server) checkSafety(r *http.Request, id int, actions ...string) error {
+	for _, action := range actions {
+		g, err := s.project.CheckSafety(action, []int{id})
+		if err != nil {
+			return err
+		}
+		if err := g.ConfirmedParam(r.URL.Query().Get("confirm")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// actions returns the safety policy actions a PATCH amounts to
+func (in taskInput) actions() []string {
+	var actions []string
+	if in.Description != nil || in.Dependencies != nil {
+		actions = append(actions, orchestrator.ActionEdit)
+	}
+	if in.Agent != nil {
+		actions = append(actions, orchestrator.ActionAssign)
+	}
+	if in.Priority != nil {
+		actions = append(actions, orchestrator.ActionPriority)
+	}
+	return actions
+}
+
+func (in taskInput) apply(t *orchestrator.Task) {
+	if in.Description != nil {
+		t.Description = strings.TrimSpace(*in.Description)
+	}
+	if in.Agent != nil {
+		t.Agent = *in.Agent
+	}
+	if in.Priority != nil {
+		t.Priority = *in.Priority
+	}
+	if in.Dependencies != nil {
+		t.Dependencies = *in.Dependencies
+	}
+}
+
+// tailLines returns the last n lines of r
+func tailLines(r io.Reader, n int) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,289 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, *orchestrator.Project) {
+	t.Helper()
+	root := t.TempDir()
+	for _, dir := range []string{"logs", "pids", "agents"} {
+		if err := os.MkdirAll(filepath.Join(root, ".claude", dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, ".claude", "tasks.json"), []byte(`{"tasks": [], "last_id": 0}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	project, err := orchestrator.FindProject(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orchestrator.SetProject(project)
+	srv := httptest.NewServer(NewServer(project))
+	t.Cleanup(srv.Close)
+	return srv, project
+}
+
+func do(t *testing.T, method, url, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	out, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(out)
+}
+
+func TestTaskEndpoints(t *testing.T) {
+	srv, _ := newTestServer(t)
+	api := srv.URL + "/api"
+
+	if code, body := do(t, "POST", api+"/tasks", `{"description": "schema", "agent": "backend"}`); code != http.StatusCreated {
+		t.Fatalf("create: %d %s", code, body)
+	}
+	if code, body := do(t, "POST", api+"/tasks", `{"description": "api", "dependencies": [1]}`); code != http.StatusCreated {
+		t.Fatalf("create with deps: %d %s", code, body)
+	}
+	if code, _ := do(t, "POST", api+"/tasks", `{"agent": "x"}`); code != http.StatusBadRequest {
+		t.Errorf("missing description should be 400, got %d", code)
+	}
+
+	if code, body := do(t, "POST", api+"/tasks/2/start", ""); code != http.StatusConflict || !strings.Contains(body, "waits for #1") {
+		t.Errorf("blocked start: %d %s", code, body)
+	}
+	if code, body := do(t, "POST", api+"/tasks/1/complete", ""); code != http.StatusOK {
+		t.Fatalf("complete: %d %s", code, body)
+	}
+	if code, body := do(t, "PATCH", api+"/tasks/2", `{"priority": "high"}`); code != http.StatusOK || !strings.Contains(body, `"high"`) {
+		t.Errorf("patch: %d %s", code, body)
+	}
+
+	code, body := do(t, "GET", api+"/tasks?status=completed", "")
+	var tasks []orchestrator.Task
+	if err := json.Unmarshal([]byte(body), &tasks); err != nil || code != http.StatusOK {
+		t.Fatalf("list: %d %v %s", code, err, body)
+	}
+	if len(tasks) != 1 || tasks[0].ID != 1 {
+		t.Errorf("expected only #1 completed, got %+v", tasks)
+	}
+	code, body = do(t, "GET", api+"/tasks?q="+url.QueryEscape("prio:high -status:completed"), "")
+	if err := json.Unmarshal([]byte(body), &tasks); err != nil || code != http.StatusOK || len(tasks) != 1 || tasks[0].ID != 2 {
+		t.Errorf("query: %d %s", code, body)
+	}
+	if code, _ := do(t, "GET", api+"/tasks?q=owner:me", ""); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid query, got %d", code)
+	}
+
+	// the safety policy asks before removing
+	if code, body := do(t, "DELETE", api+"/tasks/2", ""); code != http.StatusConflict || !strings.Contains(body, "?confirm=2") {
+		t.Errorf("unconfirmed delete: %d %s", code, body)
+	}
+	if code, _ := do(t, "DELETE", api+"/tasks/2?confirm=yes", ""); code != http.StatusNoContent {
+		t.Errorf("delete: %d", code)
+	}
+	if code, _ := do(t, "GET", api+"/tasks/2", ""); code != http.StatusNotFound {
+		t.Errorf("deleted task should be 404, got %d", code)
+	}
+}
+
+func TestSafetyPolicy(t *testing.T) {
+	srv, project := newTestServer(t)
+	api := srv.URL + "/api"
+	policy := `{"actions": {"complete": "deny", "priority": "typed"}}`
+	if err := os.WriteFile(project.SafetyPolicyPath(), []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := project.Store().Add("schema", "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, body := do(t, "POST", api+"/tasks/1/complete?confirm=1", ""); code != http.StatusForbidden {
+		t.Errorf("denied complete: %d %s", code, body)
+	}
+	if code, body := do(t, "PATCH", api+"/tasks/1?confirm=yes", `{"priority": "high"}`); code != http.StatusConflict {
+		t.Errorf("priority without the typed ID: %d %s", code, body)
+	}
+	if code, body := do(t, "PATCH", api+"/tasks/1?confirm=1", `{"priority": "high"}`); code != http.StatusOK {
+		t.Errorf("priority with the typed ID: %d %s", code, body)
+	}
+	if code, body := do(t, "PATCH", api+"/tasks/1", `{"description": "db schema"}`); code != http.StatusOK {
+		t.Errorf("unguarded edit: %d %s", code, body)
+	}
+}
+
+func TestLogsAndAgents(t *testing.T) {
+	srv, project := newTestServer(t)
+	log := strings.Repeat("noise\n", 10) + "last line\n"
+	if err := os.WriteFile(filepath.Join(project.LogsDir(), "backend.log"), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project.AgentsDir(), "backend.json"), []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, body := do(t, "GET", srv.URL+"/api/logs/backend.log?tail=2", ""); code != http.StatusOK || body != "noise\nlast line\n" {
+		t.Errorf("tail: %d %q", code, body)
+	}
+	if code, _ := do(t, "GET", srv.URL+"/api/logs/..%2Ftasks.json", ""); code != http.StatusBadRequest && code != http.StatusNotFound {
+		t.Errorf("path traversal should be refused, got %d", code)
+	}
+
+	// agent names match regardless of case, as in the task filter
+	task, err := project.Store().Add("Login API", "Backend", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := project.Store().Start(task.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	code, body := do(t, "GET", srv.URL+"/api/agents", "")
+	var agents []AgentInfo
+	if err := json.Unmarshal([]byte(body), &agents); err != nil || code != http.StatusOK {
+		t.Fatalf("agents: %d %v %s", code, err, body)
+	}
+	if len(agents) != 1 || agents[0].Name != "backend" || agents[0].Running || len(agents[0].Tasks) != 1 {
+		t.Errorf("unexpected agents %+v", agents)
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	srv, project := newTestServer(t)
+
+	resp, err := http.Get(srv.URL + "/api/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	events := make(chan string, 16)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		var event string
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.HasPrefix(line, "event: ") {
+				event = strings.TrimPrefix(line, "event: ")
+			} else if strings.HasPrefix(line, "data: ") {
+				events <- event + " " + strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+
+	// Initial snapshots, then a change pushed by the watcher
+	for _, want := range []string{"tasks []", "agents []"} {
+		select {
+		case got := <-events:
+			if got != want {
+				t.Errorf("expected %q, got %q", want, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no initial %q event", want)
+		}
+	}
+	if _, err := project.Store().Add("streamed", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-events:
+		if !strings.HasPrefix(got, "tasks ") || !strings.Contains(got, "streamed") {
+			t.Errorf("expected tasks snapshot, got %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event after tasks.json changed")
+	}
+}
+
+func TestRequestChecks(t *testing.T) {
+	srv, project := newTestServer(t)
+	api := srv.URL + "/api"
+
+	send := func(method, url, body string, header map[string]string) int {
+		t.Helper()
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range header {
+			if k == "Host" {
+				req.Host = v
+			} else {
+				req.Header.Set(k, v)
+			}
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := send("GET", api+"/tasks", "", map[string]string{"Origin": "http://evil.example"}); code != http.StatusForbidden {
+		t.Errorf("cross-origin request: %d", code)
+	}
+	if code := send("POST", api+"/tasks", `{"description": "x"}`, map[string]string{"Content-Type": "text/plain"}); code != http.StatusUnsupportedMediaType {
+		t.Errorf("text/plain body: %d", code)
+	}
+	if code := send("POST", api+"/tasks", `{"description": "x"}`, map[string]string{"Content-Type": "application/json; charset=utf-8"}); code != http.StatusCreated {
+		t.Errorf("json body: %d", code)
+	}
+
+	token, err := LoadToken(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(TokenPath(project)); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected an owner-only token file, got %v %v", info, err)
+	}
+	if again, _ := LoadToken(project); again != token {
+		t.Errorf("expected the token to be reused")
+	}
+	handler := NewServer(project)
+	authed := httptest.NewUnstartedServer(handler)
+	if err := handler.RequireAuth(authed.Listener.Addr().String(), token); err != nil {
+		t.Fatal(err)
+	}
+	authed.Start()
+	t.Cleanup(authed.Close)
+	api = authed.URL + "/api"
+
+	bearer := "Bearer " + token
+	if code := send("GET", api+"/tasks", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("no token: %d", code)
+	}
+	if code := send("GET", api+"/tasks", "", map[string]string{"Authorization": "Bearer wrong"}); code != http.StatusUnauthorized {
+		t.Errorf("wrong token: %d", code)
+	}
+	_, port, _ := strings.Cut(strings.TrimPrefix(authed.URL, "http://"), ":")
+	if code := send("GET", api+"/tasks", "", map[string]string{"Authorization": bearer, "Host": "rebound.example:" + port}); code != http.StatusForbidden {
+		t.Errorf("foreign host: %d", code)
+	}
+	if code := send("GET", api+"/tasks", "", map[string]string{"Authorization": bearer, "Host": "localhost:" + port}); code != http.StatusOK {
+		t.Errorf("localhost with token: %d", code)
+	}
+}
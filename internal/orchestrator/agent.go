@@ -0,0 +1,119 @@
+package orchestrator
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// AgentPID returns the PID recorded in .claude/pids/<name>.pid and whether
+// that process is still alive
+func (p *Project) AgentPID(name string) (int, bool) {
+	content, err := os.ReadFile(filepath.Join(p.PidsDir(), name+".pid"))
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, syscall.Kill(pid, 0) == nil
+}
+
+// AgentNames returns the agents defined in .claude/agents/*.json together
+// with any agent that has a pid file, sorted by name
+func (p *Project) AgentNames() []string {
+	seen := make(map[string]bool)
+	for _, pattern := range []string{
+		filepath.Join(p.AgentsDir(), "*.json"),
+		filepath.Join(p.PidsDir(), "*.pid"),
+	} {
+		matches, _ := filepath.Glob(pattern)
+		for _, m := range matches {
+			name := strings.TrimSuffix(filepath.Base(m), filepath.Ext(m))
+			seen[name] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AgentStatus is a snapshot of one agent for the dashboard and the API
+type AgentStatus struct {
+	Name        string    `json:"name"`
+	PID         int       `json:"pid,omitempty"`
+	Running     bool      `json:"running"`
+	Proc        *ProcStat `json:"proc,omitempty"` // nil when not running or unsupported
+	LastLogLine string    `json:"last_log_line,omitempty"`
+}
+
+// AgentStatuses returns the status of every agent known to the project
+func (p *Project) AgentStatuses() []AgentStatus {
+	names := p.AgentNames()
+	statuses := make([]AgentStatus, 0, len(names))
+	for _, name := range names {
+		st := AgentStatus{Name: name, LastLogLine: p.LastLogLine(name)}
+		st.PID, st.Running = p.AgentPID(name)
+		if st.Running {
+			if proc, err := ReadProcStat(st.PID); err == nil {
+				st.Proc = &proc
+			}
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
+
+// LastLogLine returns the last non-empty line the agent logged
+func (p *Project) LastLogLine(name string) string {
+	path := p.AgentLogFile(name)
+	if path == "" {
+		return ""
+	}
+	return lastLine(path)
+}
+
+// AgentLogFile returns whichever of logs/agent-<name>.log and logs/<name>.log
+// changed last, or "" when the agent has not logged anything yet
+func (p *Project) AgentLogFile(name string) string {
+	var newest string
+	var newestMod time.Time
+	for _, path := range []string{p.AgentLogPath(name), filepath.Join(p.LogsDir(), name+".log")} {
+		info, err := os.Stat(path)
+		if err != nil || info.ModTime().Before(newestMod) {
+			continue
+		}
+		newest, newestMod = path, info.ModTime()
+	}
+	return newest
+}
+
+// lastLine reads the last non-empty line from the end of a file
+func lastLine(path string) string {
+	const chunk = 4096
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return ""
+	}
+	offset := max(0, info.Size()-chunk)
+	buf := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(buf, offset); err != nil && err != io.EOF {
+		return ""
+	}
+	lines := strings.Split(strings.TrimRight(string(buf), "\r\n\t "), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,578 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ApprovalRequest is an entry of a task's approval_requests
+// (see docs/approval/requirements.md)
+type ApprovalRequest struct {
+	ID            string            `json:"id"`
+	OperationType string            `json:"operation_type"` // file_write, command_exec, git_commit, ...
+	Details       json.RawMessage   `json:"details,omitempty"`
+	RequestedAt   string            `json:"requested_at"`
+	RequestedBy   string            `json:"requested_by"`
+	Status        string            `json:"status"` // pending, approved, rejected, expired
+	ExpiresAt     string            `json:"expires_at,omitempty"`
+	Response      *ApprovalResponse `json:"response"`
+}
+
+// ApprovalResponse records who answered an approval request and how
+type ApprovalResponse struct {
+	Action      string `json:"action"`
+	RespondedAt string `json:"responded_at"`
+	RespondedBy string `json:"responded_by"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+// Approval request status values
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+	ApprovalExpired  = "expired"
+)
+
+// OpTaskCompletion is the operation of approvals.json requests: accepting the
+// result of a task the agent reported as done
+const OpTaskCompletion = "task_completion"
+
+var (
+	// ErrApprovalNotFound is returned when no request has the given ID
+	ErrApprovalNotFound = errors.New("approval request not found")
+	// ErrApprovalClosed is returned when a request was already answered
+	ErrApprovalClosed = errors.New("approval request already answered")
+	// ErrApprovalExpired is returned when a request is past its deadline
+	ErrApprovalExpired = errors.New("approval request expired")
+	// ErrReasonRequired is returned when rejecting without a reason
+	ErrReasonRequired = errors.New("a reason is required")
+
+	// errUnchanged leaves tasks.json alone when a decision does not touch it
+	errUnchanged = errors.New("unchanged")
+)
+
+// ApprovalFile is a file change attached to a request
+type ApprovalFile struct {
+	Path string `json:"path"`
+	Diff string `json:"diff"`
+}
+
+// Approval is a request from approvals.json or from a task in tasks.json,
+// in one shape for listing and answering
+type Approval struct {
+	ID            string            `json:"id"`
+	TaskID        int               `json:"task_id"`
+	Agent         string            `json:"agent"`
+	Description   string            `json:"description"`
+	OperationType string            `json:"operation_type"`
+	Details       json.RawMessage   `json:"details,omitempty"`
+	Status        string            `json:"status"`
+	RequestedBy   string            `json:"requested_by,omitempty"`
+	CreatedAt     string            `json:"created_at"`
+	ExpiresAt     string            `json:"expires_at,omitempty"`
+	CompletedAt   string            `json:"completed_at,omitempty"`
+	Files         []ApprovalFile    `json:"files,omitempty"`
+	Response      *ApprovalResponse `json:"response,omitempty"`
+	Source        string            `json:"source"` // approvals.json or tasks.json
+}
+
+// Deadline returns when the request expires, or the zero time if it does not
+func (a Approval) Deadline() time.Time {
+	t, err := time.Parse(time.RFC3339, a.ExpiresAt)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// ApprovalsPath returns .claude/approvals.json
+func (p *Project) ApprovalsPath() string {
+	return p.Path("approvals.json")
+}
+
+// Approvals returns the approval store of this project
+func (p *Project) Approvals() *ApprovalStore {
+	return &ApprovalStore{path: p.ApprovalsPath(), tasks: p.Store(), now: time.Now}
+}
+
+// ApprovalStore reads and answers the approval requests of a project. Writes
+// to approvals.json take its lock before the tasks.json lock.
+type ApprovalStore struct {
+	path  string
+	tasks *TaskStore
+	now   func() time.Time
+}
+
+// approvalEntry is an approvals.json request as written by the scripts
+type approvalEntry struct {
+	ID          json.RawMessage `json:"id"`
+	TaskID      int             `json:"task_id"`
+	Agent       string          `json:"agent"`
+	Description string          `json:"description"`
+	Status      string          `json:"status"`
+	CreatedAt   string          `json:"created_at"`
+	ExpiresAt   string          `json:"expires_at"`
+	CompletedAt *string         `json:"completed_at"`
+	Changes     struct {
+		Files []ApprovalFile `json:"files"`
+	} `json:"changes"`
+	Response *ApprovalResponse `json:"response"`
+}
+
+// List returns every request, pending ones first, then newest first
+func (s *ApprovalStore) List() ([]Approval, error) {
+	file, err := s.loadFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.tasks.Load()
+	if err != nil {
+		return nil, err
+	}
+	var list []Approval
+	for _, raw := range file {
+		a, err := decodeApprovalEntry(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse approvals.json: %w", err)
+		}
+		list = append(list, a)
+	}
+	list = append(list, taskApprovals(data)...)
+
+	sort.SliceStable(list, func(i, j int) bool {
+		pi, pj := list[i].Status == ApprovalPending, list[j].Status == ApprovalPending
+		if pi != pj {
+			return pi
+		}
+		return list[i].CreatedAt > list[j].CreatedAt
+	})
+	return list, nil
+}
+
+// Get returns the request with the given ID
+func (s *ApprovalStore) Get(id string) (Approval, error) {
+	list, err := s.List()
+	if err != nil {
+		return Approval{}, err
+	}
+	for _, a := range list {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+	return Approval{}, fmt.Errorf("%w: %s", ErrApprovalNotFound, id)
+}
+
+// Approve accepts a request; comment is optional
+func (s *ApprovalStore) Approve(id, comment string) (Approval, error) {
+	return s.decide(id, ApprovalApproved, strings.TrimSpace(comment))
+}
+
+// Reject refuses a request; the reason is passed on to the agent
+func (s *ApprovalStore) Reject(id, reason string) (Approval, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return Approval{}, ErrReasonRequired
+	}
+	return s.decide(id, ApprovalRejected, reason)
+}
+
+// decide records the answer and moves the task on once nothing else is pending:
+// approved completions complete the task, rejected ones send it back to the
+// queue, and answered operations let the agent continue
+func (s *ApprovalStore) decide(id, action, comment string) (Approval, error) {
+	lock, err := LockFile(s.path)
+	if err != nil {
+		return Approval{}, err
+	}
+	defer lock.Unlock()
+
+	file, err := s.loadFile()
+	if err != nil {
+		return Approval{}, err
+	}
+	now := s.now().UTC()
+	resp := &ApprovalResponse{Action: action, RespondedAt: now.Format(time.RFC3339), RespondedBy: currentUser(), Comment: comment}
+
+	var decided Approval
+	found := false
+	for i, raw := range file {
+		a, err := decodeApprovalEntry(raw)
+		if err != nil || a.ID != id {
+			continue
+		}
+		if err := checkOpen(a, now); err != nil {
+			return a, err
+		}
+		setRaw(raw, "status", action)
+		setRaw(raw, "completed_at", resp.RespondedAt)
+		setRaw(raw, "response", resp)
+		file[i] = raw
+		a.Status, a.CompletedAt, a.Response = action, resp.RespondedAt, resp
+		decided, found = a, true
+		break
+	}
+
+	// approvals.json goes first so a failed tasks.json save can put it back;
+	// the other way round would leave the task settled by an open request
+	var prev []byte
+	if found {
+		if prev, err = os.ReadFile(s.path); err != nil {
+			return decided, fmt.Errorf("failed to read approvals.json: %w", err)
+		}
+		if err := s.saveFile(file); err != nil {
+			return decided, err
+		}
+	}
+
+	err = s.tasks.Update(func(data *TasksData) error {
+		if !found {
+			a, ok := findTaskApproval(data, id)
+			if !ok {
+				return fmt.Errorf("%w: %s", ErrApprovalNotFound, id)
+			}
+			if err := checkOpen(a, now); err != nil {
+				return err
+			}
+			answerTaskApproval(data, id, action, resp)
+			a.Status, a.CompletedAt, a.Response = action, resp.RespondedAt, resp
+			decided = a
+			settleTask(data, decided, file, resp.RespondedAt, "")
+			return nil
+		}
+		if !settleTask(data, decided, file, resp.RespondedAt, "") {
+			return errUnchanged
+		}
+		return nil
+	})
+	if err != nil && err != errUnchanged {
+		if found {
+			if werr := WriteFileAtomic(s.path, prev, 0644); werr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to restore approvals.json: %w", werr))
+			}
+		}
+		return decided, err
+	}
+	return decided, nil
+}
+
+// checkOpen refuses requests that were answered or ran out of time
+func checkOpen(a Approval, now time.Time) error {
+	switch {
+	case a.Status == ApprovalExpired:
+		return fmt.Errorf("%w: %s", ErrApprovalExpired, a.ID)
+	case a.Status != ApprovalPending:
+		return fmt.Errorf("%w: %s is %s", ErrApprovalClosed, a.ID, a.Status)
+	case !a.Deadline().IsZero() && now.After(a.Deadline()):
+		return fmt.Errorf("%w: %s expired at %s", ErrApprovalExpired, a.ID, a.ExpiresAt)
+	}
+	return nil
+}
+
+// settleTask moves the task of a decided request out of pending_approval when
+// none of its other requests are still pending. Expired requests fail the task
+// when onExpiry is ExpiryFail and re-queue it otherwise. It reports whether it did.
+func settleTask(data *TasksData, a Approval, file []map[string]json.RawMessage, now, onExpiry string) bool {
+	t := data.Find(a.TaskID)
+	if t == nil || t.Status != StatusPendingApproval {
+		return false
+	}
+	for _, other := range taskApprovals(data) {
+		if other.TaskID == t.ID && other.ID != a.ID && other.Status == ApprovalPending {
+			return false
+		}
+	}
+	for _, raw := range file {
+		other, err := decodeApprovalEntry(raw)
+		if err == nil && other.TaskID == t.ID && other.ID != a.ID && other.Status == ApprovalPending {
+			return false
+		}
+	}
+
+	t.UpdatedAt = now
+	switch {
+	case a.Status == ApprovalExpired && onExpiry == ExpiryFail:
+		t.Status = StatusFailed
+	case a.Status == ApprovalExpired:
+		t.Status = StatusPending
+	case a.OperationType != OpTaskCompletion:
+		t.Status = StatusInProgress
+	case a.Status == ApprovalApproved:
+		t.Status = StatusCompleted
+		t.CompletedAt = now
+		t.Progress = 100
+	default:
+		t.Status = StatusPending
+	}
+	return true
+}
+
+// taskApprovals collects the requests kept in tasks.json: each task's
+// approval_requests, plus entries of the top-level "approvals" list that
+// are not attached to a task
+func taskApprovals(data *TasksData) []Approval {
+	var list []Approval
+	seen := map[string]bool{}
+	for _, t := range data.Tasks {
+		for _, r := range t.ApprovalRequests {
+			seen[r.ID] = true
+			list = append(list, fromRequest(r, t.ID, t.Agent, t.Description))
+		}
+	}
+	var top []struct {
+		ApprovalRequest
+		TaskID int `json:"task_id"`
+	}
+	if raw, ok := data.Extra["approvals"]; ok && json.Unmarshal(raw, &top) == nil {
+		for _, r := range top {
+			if seen[r.ID] {
+				continue
+			}
+			a := fromRequest(r.ApprovalRequest, r.TaskID, "", "")
+			if t := data.Find(r.TaskID); t != nil {
+				a.Agent, a.Description = t.Agent, t.Description
+			}
+			list = append(list, a)
+		}
+	}
+	return list
+}
+
+// fromRequest converts a tasks.json request; file changes come from details
+func fromRequest(r ApprovalRequest, taskID int, agent, desc string) Approval {
+	a := Approval{
+		ID:            r.ID,
+		TaskID:        taskID,
+		Agent:         agent,
+		Description:   desc,
+		OperationType: r.OperationType,
+		Details:       r.Details,
+		Status:        r.Status,
+		RequestedBy:   r.RequestedBy,
+		CreatedAt:     r.RequestedAt,
+		ExpiresAt:     r.ExpiresAt,
+		Response:      r.Response,
+		Source:        "tasks.json",
+	}
+	if a.Status == "" {
+		a.Status = ApprovalPending
+	}
+	if r.Response != nil {
+		a.CompletedAt = r.Response.RespondedAt
+	}
+	var d struct {
+		File  string         `json:"file"`
+		Path  string         `json:"path"`
+		Diff  string         `json:"diff"`
+		Files []ApprovalFile `json:"files"`
+	}
+	if json.Unmarshal(r.Details, &d) == nil {
+		a.Files = d.Files
+		if path := d.File + d.Path; path != "" {
+			a.Files = append(a.Files, ApprovalFile{Path: path, Diff: d.Diff})
+		}
+	}
+	return a
+}
+
+// findTaskApproval finds a request kept in tasks.json
+func findTaskApproval(data *TasksData, id string) (Approval, bool) {
+	for _, a := range taskApprovals(data) {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return Approval{}, false
+}
+
+// answerTaskApproval records the answer on the task's request and on the
+// matching entry of the top-level "approvals" list
+func answerTaskApproval(data *TasksData, id, action string, resp *ApprovalResponse) {
+	updateTaskApproval(data, id, func(r *ApprovalRequest) {
+		r.Status, r.Response = action, resp
+	}, map[string]any{"status": action, "response": resp})
+}
+
+// updateTaskApproval changes a request kept in tasks.json with fn, and sets
+// fields on the matching entry of the top-level "approvals" list
+func updateTaskApproval(data *TasksData, id string, fn func(r *ApprovalRequest), fields map[string]any) {
+	for i := range data.Tasks {
+		for j := range data.Tasks[i].ApprovalRequests {
+			if r := &data.Tasks[i].ApprovalRequests[j]; r.ID == id {
+				fn(r)
+			}
+		}
+	}
+	raw, ok := data.Extra["approvals"]
+	if !ok {
+		return
+	}
+	var top []map[string]json.RawMessage
+	if json.Unmarshal(raw, &top) != nil {
+		return
+	}
+	for _, entry := range top {
+		if rawID(entry["id"]) == id {
+			for k, v := range fields {
+				setRaw(entry, k, v)
+			}
+		}
+	}
+	if b, err := json.Marshal(top); err == nil {
+		data.Extra["approvals"] = b
+	}
+}
+
+// loadFile reads the entries of approvals.json as raw objects, so fields
+// this package does not model survive a rewrite. A missing file is empty.
+func (s *ApprovalStore) loadFile() ([]map[string]json.RawMessage, error) {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read approvals.json: %w", err)
+	}
+	var file struct {
+		Approvals []map[string]json.RawMessage `json:"approvals"`
+	}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse approvals.json: %w", err)
+	}
+	return file.Approvals, nil
+}
+
+// saveFile replaces the approvals list, keeping the other top-level fields
+func (s *ApprovalStore) saveFile(entries []map[string]json.RawMessage) error {
+	top := map[string]json.RawMessage{}
+	if b, err := os.ReadFile(s.path); err == nil {
+		json.Unmarshal(b, &top)
+	}
+	list, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	top["approvals"] = list
+	out, err := json.MarshalIndent(top, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := WriteFileAtomic(s.path, append(out, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write approvals.json: %w", err)
+	}
+	return nil
+}
+
+// decodeApprovalEntry converts a raw approvals.json entry
+func decodeApprovalEntry(raw map[string]json.RawMessage) (Approval, error) {
+	b, _ := json.Marshal(raw)
+	var e approvalEntry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return Approval{}, err
+	}
+	a := Approval{
+		ID:            rawID(e.ID),
+		TaskID:        e.TaskID,
+		Agent:         e.Agent,
+		Description:   e.Description,
+		OperationType: OpTaskCompletion,
+		Status:        e.Status,
+		RequestedBy:   "agent:" + e.Agent,
+		CreatedAt:     e.CreatedAt,
+		ExpiresAt:     e.ExpiresAt,
+		Files:         e.Changes.Files,
+		Response:      e.Response,
+		Source:        "approvals.json",
+	}
+	if e.CompletedAt != nil {
+		a.CompletedAt = *e.CompletedAt
+	}
+	switch a.Status {
+	case "":
+		a.Status = ApprovalPending
+	case "timeout":
+		a.Status = ApprovalExpired // the usage guide's name for it
+	}
+	return a, nil
+}
+
+// rawID reads an ID written as a number or a string
+func rawID(raw json.RawMessage) string {
+	var n int
+	if json.Unmarshal(raw, &n) == nil {
+		return strconv.Itoa(n)
+	}
+	var s string
+	json.Unmarshal(raw, &s)
+	return s
+}
+
+func setRaw(obj map[string]json.RawMessage, key string, v any) {
+	if b, err := json.Marshal(v); err == nil {
+		obj[key] = b
+	}
+}
+
+// currentUser names who answered a request
+func currentUser() string {
+	if u := os.Getenv("USER"); u != "" {
+		return "user:" + u
+	}
+	return "user"
+}
+
+// ApprovalsLoadMsg carries the approval requests of the current project
+type ApprovalsLoadMsg []Approval
+
+// ApprovalDecidedMsg reports an answered request
+type ApprovalDecidedMsg struct {
+	Approval Approval
+}
+
+// FetchApprovalsCmd lists the approval requests of the current project
+func FetchApprovalsCmd() tea.Cmd {
+	return func() tea.Msg {
+		p, err := CurrentProject()
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		list, err := p.Approvals().List()
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		return ApprovalsLoadMsg(list)
+	}
+}
+
+// ApproveCmd approves a request with an optional comment
+func ApproveCmd(id, comment string) tea.Cmd {
+	return decideCmd(func(s *ApprovalStore) (Approval, error) { return s.Approve(id, comment) })
+}
+
+// RejectCmd rejects a request; reason must not be empty
+func RejectCmd(id, reason string) tea.Cmd {
+	return decideCmd(func(s *ApprovalStore) (Approval, error) { return s.Reject(id, reason) })
+}
+
+func decideCmd(fn func(s *ApprovalStore) (Approval, error)) tea.Cmd {
+	return func() tea.Msg {
+		p, err := CurrentProject()
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		a, err := fn(p.Approvals())
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		return ApprovalDecidedMsg{Approval: a}
+	}
+}
@@ -0,0 +1,162 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestApprovals(t *testing.T, tasks, approvals string) *ApprovalStore {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "approvals.json")
+	if approvals != "" {
+		if err := os.WriteFile(path, []byte(approvals), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	return &ApprovalStore{path: path, tasks: newTestStore(t, tasks), now: func() time.Time { return now }}
+}
+
+func TestApprovalsFileDecisions(t *testing.T) {
+	s := newTestApprovals(t, `{"tasks": [
+		{"id": 1, "description": "Login API", "status": "pending_approval", "agent": "backend"},
+		{"id": 2, "description": "Signup form", "status": "pending_approval", "agent": "frontend"},
+		{"id": 3, "description": "Docs", "status": "pending_approval", "agent": "docs"}
+	], "last_id": 3}`, `{"version": 1, "approvals": [
+		{"id": 1, "task_id": 1, "agent": "backend", "description": "Login API", "status": "pending",
+		 "created_at": "2026-01-02T10:00:00Z", "expires_at": "2026-01-03T10:00:00Z",
+		 "changes": {"files": [{"path": "api/login.go", "diff": "+func Login() {}"}]}, "reviewer": "kept"},
+		{"id": 2, "task_id": 2, "agent": "frontend", "description": "Signup form", "status": "pending",
+		 "created_at": "2026-01-02T11:00:00Z"},
+		{"id": 3, "task_id": 3, "agent": "docs", "description": "Docs", "status": "pending",
+		 "created_at": "2026-01-01T10:00:00Z", "expires_at": "2026-01-02T10:00:00Z"}
+	]}`)
+
+	list, err := s.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 3 || list[0].ID != "2" || list[1].ID != "1" {
+		t.Fatalf("expected newest pending first, got %+v", list)
+	}
+	if list[1].Files[0].Path != "api/login.go" || list[1].OperationType != OpTaskCompletion {
+		t.Errorf("unexpected request: %+v", list[1])
+	}
+
+	if _, err := s.Reject("2", "  "); !errors.Is(err, ErrReasonRequired) {
+		t.Errorf("expected ErrReasonRequired, got %v", err)
+	}
+	if _, err := s.Approve("1", "looks good"); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if _, err := s.Reject("2", "missing validation"); err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+	if _, err := s.Approve("1", ""); !errors.Is(err, ErrApprovalClosed) {
+		t.Errorf("expected ErrApprovalClosed for an answered request, got %v", err)
+	}
+	if _, err := s.Approve("3", ""); !errors.Is(err, ErrApprovalExpired) {
+		t.Errorf("expected ErrApprovalExpired past expires_at, got %v", err)
+	}
+	if _, err := s.Approve("9", ""); !errors.Is(err, ErrApprovalNotFound) {
+		t.Errorf("expected ErrApprovalNotFound, got %v", err)
+	}
+
+	data, err := s.tasks.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task := data.Find(1); task.Status != StatusCompleted || task.Progress != 100 {
+		t.Errorf("approved completion should complete the task, got %+v", task)
+	}
+	if task := data.Find(2); task.Status != StatusPending {
+		t.Errorf("rejected completion should re-queue the task, got %+v", task)
+	}
+	if task := data.Find(3); task.Status != StatusPendingApproval {
+		t.Errorf("expired request should leave the task alone, got %+v", task)
+	}
+
+	b, _ := os.ReadFile(s.path)
+	for _, want := range []string{`"version": 1`, `"reviewer": "kept"`, `"comment": "missing validation"`} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("approvals.json lost %s:\n%s", want, b)
+		}
+	}
+	a, err := s.Get("2")
+	if err != nil || a.Status != ApprovalRejected || a.Response.Comment != "missing validation" || a.CompletedAt == "" {
+		t.Errorf("unexpected rejected request: %+v (%v)", a, err)
+	}
+}
+
+func TestDecisionRollsBackOnFailedSave(t *testing.T) {
+	// a schema newer than this build refuses to be saved
+	s := newTestApprovals(t, `{"schema_version": 99, "tasks": [
+		{"id": 1, "description": "Login API", "status": "pending_approval", "agent": "backend"}
+	], "last_id": 1}`, `{"version": 1, "approvals": [
+		{"id": 1, "task_id": 1, "agent": "backend", "description": "Login API", "status": "pending",
+		 "created_at": "2026-01-02T10:00:00Z"}
+	]}`)
+
+	if _, err := s.Approve("1", ""); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("expected ErrSchemaTooNew, got %v", err)
+	}
+	a, err := s.Get("1")
+	if err != nil || a.Status != ApprovalPending || a.Response != nil {
+		t.Errorf("a failed tasks.json save should leave the request open, got %+v (%v)", a, err)
+	}
+}
+
+func TestTaskApprovalDecisions(t *testing.T) {
+	s := newTestApprovals(t, `{"tasks": [
+		{"id": 1, "description": "Deploy", "status": "pending_approval", "agent": "devops",
+		 "approval_requests": [
+			{"id": "req-1", "operation_type": "command_exec", "details": {"command": "make deploy"},
+			 "requested_at": "2026-01-02T10:00:00Z", "requested_by": "agent:devops", "status": "pending", "response": null},
+			{"id": "req-2", "operation_type": "file_write", "details": {"file": "deploy.yml", "diff": "-a\n+b"},
+			 "requested_at": "2026-01-02T10:05:00Z", "requested_by": "agent:devops", "status": "pending", "response": null}
+		 ]}
+	], "last_id": 1, "approvals": [{"id": "req-1", "task_id": 1, "status": "pending"}]}`, "")
+
+	list, err := s.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected the mirrored request once, got %+v", list)
+	}
+
+	if _, err := s.Approve("req-1", ""); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	data, _ := s.tasks.Load()
+	if task := data.Find(1); task.Status != StatusPendingApproval {
+		t.Errorf("task should wait for req-2, got %s", task.Status)
+	}
+	var top []map[string]any
+	json.Unmarshal(data.Extra["approvals"], &top)
+	if len(top) != 1 || top[0]["status"] != ApprovalApproved {
+		t.Errorf("top-level approvals not updated: %v", top)
+	}
+
+	a, err := s.Reject("req-2", "wrong environment")
+	if err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+	if len(a.Files) != 1 || a.Files[0].Path != "deploy.yml" {
+		t.Errorf("expected the file from details, got %+v", a.Files)
+	}
+	data, _ = s.tasks.Load()
+	task := data.Find(1)
+	if task.Status != StatusInProgress {
+		t.Errorf("answered operations should let the agent continue, got %s", task.Status)
+	}
+	if r := task.ApprovalRequests[1]; r.Status != ApprovalRejected || r.Response == nil || r.Response.Comment != "wrong environment" {
+		t.Errorf("unexpected request after Reject: %+v", r)
+	}
+}
@@ -0,0 +1,423 @@
+package orchestrator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const defaultArchiveRetention = 7 * 24 * time.Hour
+
+// errNothingToArchive leaves tasks.json untouched when no task is due
+var errNothingToArchive = errors.New("nothing to archive")
+
+// ArchivedTask is one line of an archive file: a task moved out of
+// tasks.json, with the log of its run
+type ArchivedTask struct {
+	ArchivedAt string `json:"archived_at"`
+	Log        string `json:"log,omitempty"` // relative to the project root
+	Task       Task   `json:"task"`
+	File       string `json:"-"` // archive file the entry was read from
+}
+
+// Finished returns when the task was completed, or last updated when it
+// failed; zero when unknown
+func Finished(t Task) time.Time {
+	ts := t.CompletedAt
+	if ts == "" {
+		ts = t.UpdatedAt
+	}
+	at, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return time.Time{}
+	}
+	return at
+}
+
+// ArchivePolicy is .claude/archive-policy.json:
+//
+//	{"retention": "168h", "auto": true}
+//
+// Completed and failed tasks that finished more than Retention ago are
+// moved to the archive; with Auto the control center does so on start.
+type ArchivePolicy struct {
+	Retention Duration `json:"retention"`
+	Auto      bool     `json:"auto"`
+}
+
+// DefaultArchivePolicy archives a week after tasks finish, on start
+func DefaultArchivePolicy() ArchivePolicy {
+	return ArchivePolicy{Retention: Duration(defaultArchiveRetention), Auto: true}
+}
+
+// ArchivePolicyPath returns .claude/archive-policy.json
+func (p *Project) ArchivePolicyPath() string {
+	return p.Path("archive-policy.json")
+}
+
+// ArchiveDir returns .claude/archive
+func (p *Project) ArchiveDir() string {
+	return p.Path("archive")
+}
+
+// LoadArchivePolicy reads the archive policy; fields left out keep their
+// defaults and a missing file is the default policy
+func (p *Project) LoadArchivePolicy() (ArchivePolicy, error) {
+	pol := DefaultArchivePolicy()
+	b, err := os.ReadFile(p.ArchivePolicyPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return pol, nil
+		}
+		return pol, fmt.Errorf("failed to read archive-policy.json: %w", err)
+	}
+	if err := json.Unmarshal(b, &pol); err != nil {
+		return DefaultArchivePolicy(), fmt.Errorf("failed to parse archive-policy.json: %w", err)
+	}
+	if pol.Retention < 0 {
+		return DefaultArchivePolicy(), fmt.Errorf("archive-policy.json: retention must not be negative")
+	}
+	return pol, nil
+}
+
+// Archive moves the completed and failed tasks that finished before cutoff
+// into .claude/archive/<finish date>.jsonl. Tasks that a task left in
+// tasks.json depends on stay, so no dependency ever points into the
+// archive. Writes take the archive lock before the tasks.json lock.
+func (p *Project) Archive(cutoff time.Time) ([]ArchivedTask, error) {
+	if err := os.MkdirAll(p.ArchiveDir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the archive: %w", err)
+	}
+	lock, err := LockFile(p.ArchiveDir())
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	store := p.Store()
+	var moved []ArchivedTask
+	sizes := map[string]int64{} // archive files written, with their size before
+	err = store.Update(func(data *TasksData) error {
+		due := archivable(data.Tasks, cutoff)
+		if len(due) == 0 {
+			return errNothingToArchive
+		}
+		now := store.timestamp()
+		byFile := map[string][]ArchivedTask{}
+		var kept []Task
+		for _, t := range data.Tasks {
+			if !due[t.ID] {
+				kept = append(kept, t)
+				continue
+			}
+			a := ArchivedTask{ArchivedAt: now, Task: t}
+			if path := p.TaskLogPath(t.ID); fileExists(path) {
+				a.Log, _ = filepath.Rel(p.Root, path)
+			}
+			a.File = filepath.Join(p.ArchiveDir(), Finished(t).Format("2006-01-02")+".jsonl")
+			byFile[a.File] = append(byFile[a.File], a)
+			moved = append(moved, a)
+		}
+		// the archive is written first: a failure leaves tasks.json as it was,
+		// and a failure to save tasks.json takes the entries back out
+		for file, entries := range byFile {
+			sizes[file] = -1
+			if info, err := os.Stat(file); err == nil {
+				sizes[file] = info.Size()
+			}
+			if err := appendArchive(file, entries); err != nil {
+				return err
+			}
+		}
+		if kept == nil {
+			kept = []Task{}
+		}
+		data.Tasks = kept
+		return nil
+	})
+	if errors.Is(err, errNothingToArchive) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Join(err, unappendArchive(sizes))
+	}
+	return moved, nil
+}
+
+// unappendArchive cuts archive files back to their size before a failed
+// Archive, removing the ones it created
+func unappendArchive(sizes map[string]int64) error {
+	var errs []error
+	for file, size := range sizes {
+		var err error
+		if size < 0 {
+			err = os.Remove(file)
+			if os.IsNotExist(err) {
+				err = nil
+			}
+		} else {
+			err = os.Truncate(file, size)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to roll back the archive: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ArchiveExpired archives what the archive policy says has expired
+func (p *Project) ArchiveExpired() ([]ArchivedTask, error) {
+	pol, err := p.LoadArchivePolicy()
+	if err != nil {
+		return nil, err
+	}
+	return p.Archive(time.Now().Add(-time.Duration(pol.Retention)))
+}
+
+// archivable returns the finished tasks due before cutoff that no remaining
+// task depends on
+func archivable(tasks []Task, cutoff time.Time) map[int]bool {
+	due := map[int]bool{}
+	for _, t := range tasks {
+		if t.Status != StatusCompleted && t.Status != StatusFailed {
+			continue
+		}
+		if at := Finished(t); !at.IsZero() && at.Before(cutoff) {
+			due[t.ID] = true
+		}
+	}
+	// keeping a task can keep its own dependencies, so repeat until stable
+	for changed := true; changed; {
+		changed = false
+		for _, t := range tasks {
+			if due[t.ID] {
+				continue
+			}
+			for _, dep := range t.Dependencies {
+				if due[dep] {
+					delete(due, dep)
+					changed = true
+				}
+			}
+		}
+	}
+	return due
+}
+
+func appendArchive(path string, entries []ArchivedTask) error {
+	var buf bytes.Buffer
+	for _, a := range entries {
+		line, err := json.Marshal(a)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open the archive: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write the archive: %w", err)
+	}
+	return f.Close()
+}
+
+// LoadArchive reads every archived task, most recently finished first
+func (p *Project) LoadArchive() ([]ArchivedTask, error) {
+	files, err := filepath.Glob(filepath.Join(p.ArchiveDir(), "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	var all []ArchivedTask
+	for _, file := range files {
+		entries, err := readArchive(file)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, entries...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		a, b := Finished(all[i].Task), Finished(all[j].Task)
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return all[i].Task.ID > all[j].Task.ID
+	})
+	return all, nil
+}
+
+func readArchive(path string) ([]ArchivedTask, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the archive: %w", err)
+	}
+	defer f.Close()
+	var entries []ArchivedTask
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var a ArchivedTask
+		if err := json.Unmarshal(sc.Bytes(), &a); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", filepath.Base(path), n, err)
+		}
+		a.File = path
+		entries = append(entries, a)
+	}
+	return entries, sc.Err()
+}
+
+// SearchArchive returns the archived tasks matching a query (see Query);
+// @name stands for a saved search
+func (p *Project) SearchArchive(text string) ([]ArchivedTask, error) {
+	q, err := p.Search(text)
+	if err != nil {
+		return nil, err
+	}
+	all, err := p.LoadArchive()
+	if err != nil {
+		return nil, err
+	}
+	var found []ArchivedTask
+	for _, a := range all {
+		if q.Match(a.Task) {
+			found = append(found, a)
+		}
+	}
+	return found, nil
+}
+
+// Restore moves an archived task back into tasks.json, where it keeps its
+// ID and status
+func (p *Project) Restore(id int) (Task, error) {
+	lock, err := LockFile(p.ArchiveDir())
+	if err != nil {
+		return Task{}, err
+	}
+	defer lock.Unlock()
+
+	all, err := p.LoadArchive()
+	if err != nil {
+		return Task{}, err
+	}
+	var found *ArchivedTask
+	for i := range all {
+		if all[i].Task.ID == id {
+			found = &all[i]
+			break
+		}
+	}
+	if found == nil {
+		return Task{}, fmt.Errorf("%w: #%d is not archived", ErrTaskNotFound, id)
+	}
+
+	err = p.Store().Update(func(data *TasksData) error {
+		if data.Find(id) != nil {
+			return fmt.Errorf("%w: #%d is already in tasks.json", ErrInvalidTransition, id)
+		}
+		i := sort.Search(len(data.Tasks), func(i int) bool { return data.Tasks[i].ID > id })
+		data.Tasks = append(data.Tasks[:i], append([]Task{found.Task}, data.Tasks[i:]...)...)
+		data.LastID = max(data.LastID, id)
+		return nil
+	})
+	if err != nil {
+		return Task{}, err
+	}
+	return found.Task, removeArchived(found.File, id)
+}
+
+// removeArchived drops task id from an archive file, deleting the file once
+// it is empty
+func removeArchived(path string, id int) error {
+	entries, err := readArchive(path)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, a := range entries {
+		if a.Task.ID == id {
+			continue
+		}
+		line, err := json.Marshal(a)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+	}
+	if buf.Len() == 0 {
+		return os.Remove(path)
+	}
+	return WriteFileAtomic(path, buf.Bytes(), 0644)
+}
+
+// ArchivedMsg reports the tasks just moved to the archive
+type ArchivedMsg []ArchivedTask
+
+// ArchiveLoadMsg carries the whole archive
+type ArchiveLoadMsg []ArchivedTask
+
+// RestoredMsg reports a task restored from the archive
+type RestoredMsg Task
+
+// ArchiveExpiredCmd archives the tasks whose retention has passed. With
+// auto set it does nothing unless the policy enables archiving on start.
+func ArchiveExpiredCmd(auto bool) tea.Cmd {
+	return func() tea.Msg {
+		p, err := CurrentProject()
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		if auto {
+			if pol, err := p.LoadArchivePolicy(); err != nil || !pol.Auto {
+				return nil
+			}
+		}
+		moved, err := p.ArchiveExpired()
+		if err != nil {
+			return ErrorMsg(fmt.Errorf("archive failed: %w", err))
+		}
+		return ArchivedMsg(moved)
+	}
+}
+
+// LoadArchiveCmd reads the archive of the current project
+func LoadArchiveCmd() tea.Cmd {
+	return func() tea.Msg {
+		p, err := CurrentProject()
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		all, err := p.LoadArchive()
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		return ArchiveLoadMsg(all)
+	}
+}
+
+// RestoreCmd moves an archived task back into tasks.json
+func RestoreCmd(id int) tea.Cmd {
+	return func() tea.Msg {
+		p, err := CurrentProject()
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		t, err := p.Restore(id)
+		if err != nil {
+			return ErrorMsg(fmt.Errorf("restore failed: %w", err))
+		}
+		return RestoredMsg(t)
+	}
+}
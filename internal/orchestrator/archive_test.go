@@ -0,0 +1,135 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArchiveAndRestore(t *testing.T) {
+	p := &Project{Root: t.TempDir()}
+	if err := os.MkdirAll(p.LogsDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p.TasksPath(), []byte(`{"tasks": [
+		{"id": 1, "description": "old schema", "status": "completed", "agent": "backend", "completed_at": "2026-01-02T10:00:00Z"},
+		{"id": 2, "description": "old login form", "status": "failed", "agent": "frontend", "updated_at": "2026-01-03T10:00:00Z"},
+		{"id": 3, "description": "old api", "status": "completed", "completed_at": "2026-01-02T12:00:00Z"},
+		{"id": 4, "description": "new docs", "status": "completed", "completed_at": "2026-03-01T10:00:00Z"},
+		{"id": 5, "description": "wire the api", "status": "pending", "dependencies": [3]}
+	], "last_id": 5}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p.TaskLogPath(1), []byte("done\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	moved, err := p.Archive(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// #3 stays: the pending #5 depends on it
+	if len(moved) != 2 || moved[0].Task.ID != 1 || moved[1].Task.ID != 2 {
+		t.Fatalf("expected #1 and #2 archived, got %+v", moved)
+	}
+	if moved[0].Log != filepath.Join(".claude", "logs", "task-1.log") || moved[1].Log != "" {
+		t.Errorf("expected only #1 to link its log, got %q and %q", moved[0].Log, moved[1].Log)
+	}
+	for _, day := range []string{"2026-01-02", "2026-01-03"} {
+		if _, err := os.Stat(filepath.Join(p.ArchiveDir(), day+".jsonl")); err != nil {
+			t.Errorf("expected the archive file of %s: %v", day, err)
+		}
+	}
+	data, err := p.Store().Load()
+	if err != nil || len(data.Tasks) != 3 || data.Find(1) != nil || data.Find(3) == nil {
+		t.Fatalf("expected #3, #4 and #5 left in tasks.json, got %+v (%v)", data, err)
+	}
+	if moved, err := p.Archive(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)); err != nil || len(moved) != 0 {
+		t.Errorf("expected nothing more to archive, got %+v (%v)", moved, err)
+	}
+
+	all, err := p.LoadArchive()
+	if err != nil || len(all) != 2 || all[0].Task.ID != 2 {
+		t.Fatalf("expected the archive newest first, got %+v (%v)", all, err)
+	}
+	if found, _ := p.SearchArchive("LOGIN failed"); len(found) != 1 || found[0].Task.ID != 2 {
+		t.Errorf("expected #2 found by words, got %+v", found)
+	}
+	if found, _ := p.SearchArchive("#1"); len(found) != 1 || found[0].Task.ID != 1 {
+		t.Errorf("expected #1 found by ID, got %+v", found)
+	}
+	if found, _ := p.SearchArchive("2026-01-02"); len(found) != 1 || found[0].Task.ID != 1 {
+		t.Errorf("expected #1 found by date, got %+v", found)
+	}
+
+	task, err := p.Restore(1)
+	if err != nil || task.Status != StatusCompleted {
+		t.Fatalf("unexpected restore: %+v %v", task, err)
+	}
+	if data, _ := p.Store().Load(); len(data.Tasks) != 4 || data.Tasks[0].ID != 1 {
+		t.Errorf("expected #1 back first in tasks.json, got %+v", data.Tasks)
+	}
+	if _, err := os.Stat(filepath.Join(p.ArchiveDir(), "2026-01-02.jsonl")); !os.IsNotExist(err) {
+		t.Errorf("expected the emptied archive file removed, got %v", err)
+	}
+	if _, err := p.Restore(1); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestArchiveRollsBackOnFailedSave(t *testing.T) {
+	p := &Project{Root: t.TempDir()}
+	if err := os.MkdirAll(p.ArchiveDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	kept := `{"archived_at": "2026-01-05T00:00:00Z", "task": {"id": 7, "status": "completed"}}` + "\n"
+	if err := os.WriteFile(filepath.Join(p.ArchiveDir(), "2026-01-02.jsonl"), []byte(kept), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// a newer schema can be read but not saved
+	if err := os.WriteFile(p.TasksPath(), []byte(`{"schema_version": 99, "tasks": [
+		{"id": 1, "status": "completed", "completed_at": "2026-01-02T10:00:00Z"},
+		{"id": 2, "status": "completed", "completed_at": "2026-01-03T10:00:00Z"}
+	], "last_id": 2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := p.Archive(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("expected ErrSchemaTooNew, got %v", err)
+	}
+	if b, _ := os.ReadFile(filepath.Join(p.ArchiveDir(), "2026-01-02.jsonl")); string(b) != kept {
+		t.Errorf("expected the existing archive file cut back, got %q", b)
+	}
+	if _, err := os.Stat(filepath.Join(p.ArchiveDir(), "2026-01-03.jsonl")); !os.IsNotExist(err) {
+		t.Errorf("expected the new archive file removed, got %v", err)
+	}
+	if data, _ := p.Store().Load(); len(data.Tasks) != 2 {
+		t.Errorf("expected tasks.json untouched, got %+v", data.Tasks)
+	}
+}
+
+func TestLoadArchivePolicy(t *testing.T) {
+	p := &Project{Root: t.TempDir()}
+	pol, err := p.LoadArchivePolicy()
+	if err != nil || time.Duration(pol.Retention) != defaultArchiveRetention || !pol.Auto {
+		t.Fatalf("expected the default policy without a file, got %+v (%v)", pol, err)
+	}
+	if err := os.MkdirAll(p.ClaudeDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p.ArchivePolicyPath(), []byte(`{"auto": false}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pol, err = p.LoadArchivePolicy()
+	if err != nil || time.Duration(pol.Retention) != defaultArchiveRetention || pol.Auto {
+		t.Errorf("expected the file merged over the defaults, got %+v (%v)", pol, err)
+	}
+	if err := os.WriteFile(p.ArchivePolicyPath(), []byte(`{"retention": "-1h"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.LoadArchivePolicy(); err == nil {
+		t.Error("expected a negative retention to be rejected")
+	}
+}
@@ -0,0 +1,121 @@
+package orchestrator
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Actions of RunBulk
+const (
+	BulkStart    = "start"
+	BulkStop     = "stop"
+	BulkComplete = "complete"
+	BulkRemove   = "remove"
+	BulkAssign   = "assign" // Arg is the agent ("" unassigns)
+	BulkRaise    = "raise"  // one priority step up
+	BulkLower    = "lower"  // one priority step down
+)
+
+// BulkAction is one action applied to several tasks
+type BulkAction struct {
+	Kind string
+	Arg  string
+}
+
+// BulkResult is the outcome of a bulk action for one task
+type BulkResult struct {
+	ID   int
+	Task Task // the task after the change (zero once removed)
+	Err  error
+}
+
+// RunBulk applies a to every task of p in turn. Each task is its own write,
+// so one refusal (blocked, invalid transition) does not stop the others.
+// Starting and stopping also start and stop the agents, as for one task.
+func (p *Project) RunBulk(a BulkAction, ids []int) []BulkResult {
+	results := p.bulkTasks(a, ids)
+	p.bulkAgents(a, results)
+	return results
+}
+
+// bulkTasks is the part of RunBulk that writes tasks.json
+func (p *Project) bulkTasks(a BulkAction, ids []int) []BulkResult {
+	s := p.Store()
+	results := make([]BulkResult, 0, len(ids))
+	for _, id := range ids {
+		r := BulkResult{ID: id}
+		switch a.Kind {
+		case BulkStart:
+			r.Task, r.Err = p.startTask(id, false)
+		case BulkStop:
+			r.Task, r.Err = s.Stop(id)
+		case BulkComplete:
+			r.Task, r.Err = s.Complete(id)
+		case BulkRemove:
+			r.Err = s.Remove(id)
+		case BulkAssign:
+			r.Task, r.Err = s.Modify(id, func(t *Task) error {
+				t.Agent = a.Arg
+				return nil
+			})
+		case BulkRaise, BulkLower:
+			delta := 1
+			if a.Kind == BulkLower {
+				delta = -1
+			}
+			r.Task, r.Err = s.Modify(id, func(t *Task) error {
+				t.Priority = ShiftPriority(t.Priority, delta)
+				return nil
+			})
+		default:
+			r.Err = fmt.Errorf("unknown bulk action %q", a.Kind)
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// bulkAgents starts or stops the agents of the tasks bulkTasks started or
+// stopped, recording failures in their results
+func (p *Project) bulkAgents(a BulkAction, results []BulkResult) {
+	for i, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		switch a.Kind {
+		case BulkStart:
+			results[i].Err = p.ensureAgent(r.Task)
+		case BulkStop:
+			results[i].Err = p.releaseAgent(r.Task)
+		}
+	}
+}
+
+// BulkDoneMsg reports a bulk action task by task
+type BulkDoneMsg struct {
+	Action  BulkAction
+	Results []BulkResult
+}
+
+// BulkCmd applies an action to several tasks of the current project
+func BulkCmd(a BulkAction, ids []int) tea.Cmd {
+	return func() tea.Msg {
+		p, err := CurrentProject()
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		var results []BulkResult
+		// one journal entry for the whole action, so one undo reverts it;
+		// agents are started and stopped outside it, as for one task
+		err = p.Journal().Record(fmt.Sprintf("%s %s", a.Kind, FormatIDs(ids)), func(*TaskStore) ([]int, error) {
+			results = p.bulkTasks(a, ids)
+			return ids, nil
+		})
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		p.bulkAgents(a, results)
+		return BulkDoneMsg{Action: a, Results: results}
+	}
+}
@@ -0,0 +1,60 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestRunBulk(t *testing.T) {
+	p := &Project{Root: t.TempDir()}
+	if err := os.MkdirAll(p.ClaudeDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p.TasksPath(), []byte(`{"tasks": [
+		{"id": 1, "description": "a", "status": "in_progress", "agent": "backend", "priority": "normal"},
+		{"id": 2, "description": "b", "status": "completed", "agent": "backend", "priority": "high"},
+		{"id": 3, "description": "c", "status": "in_progress", "priority": "low"},
+		{"id": 4, "description": "d", "status": "pending", "priority": "normal"}
+	], "last_id": 4}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := p.Store()
+
+	// a refusal in the middle does not stop the others
+	results := p.RunBulk(BulkAction{Kind: BulkComplete}, []int{1, 2, 3})
+	if len(results) != 3 || results[0].Err != nil || results[2].Err != nil || !errors.Is(results[1].Err, ErrInvalidTransition) {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if results[2].Task.Status != StatusCompleted {
+		t.Errorf("expected #3 completed, got %+v", results[2].Task)
+	}
+
+	results = p.RunBulk(BulkAction{Kind: BulkAssign, Arg: "frontend"}, []int{2, 3})
+	if results[0].Task.Agent != "frontend" || results[1].Task.Agent != "frontend" {
+		t.Errorf("unexpected reassignment: %+v", results)
+	}
+	results = p.RunBulk(BulkAction{Kind: BulkRaise}, []int{2, 3})
+	if results[0].Task.Priority != "critical" || results[1].Task.Priority != "normal" {
+		t.Errorf("unexpected priorities: %+v", results)
+	}
+
+	// starting works on p, whichever project is current
+	SetProject(&Project{Root: t.TempDir()})
+	t.Cleanup(func() { SetProject(nil) })
+	results = p.RunBulk(BulkAction{Kind: BulkStart}, []int{4})
+	if results[0].Err != nil || results[0].Task.Status != StatusInProgress {
+		t.Errorf("unexpected start: %+v", results)
+	}
+	if task, _ := s.Get(4); task.Status != StatusInProgress {
+		t.Errorf("expected #4 started in p, got %+v", task)
+	}
+
+	results = p.RunBulk(BulkAction{Kind: BulkRemove}, []int{2, 9})
+	if results[0].Err != nil || !errors.Is(results[1].Err, ErrTaskNotFound) {
+		t.Errorf("unexpected removal: %+v", results)
+	}
+	if data, _ := s.Load(); len(data.Tasks) != 3 {
+		t.Errorf("expected 3 tasks left, got %d", len(data.Tasks))
+	}
+}
@@ -0,0 +1,196 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// AgentDef describes an agent defined in .claude/agents/<name>.json
+type AgentDef struct {
+	Name         string   `json:"name"`
+	DisplayName  string   `json:"display_name"`
+	Color        string   `json:"color"` // ANSI 256 number ("208") or hex ("#ff8800")
+	Description  string   `json:"description"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// builtinAgents is used when a project has no agent definitions yet, and
+// supplies the colors the dashboard has always used for these names
+var builtinAgents = []AgentDef{
+	{Name: "frontend", DisplayName: "Frontend", Color: "39", Description: "UI components and styling"},
+	{Name: "backend", DisplayName: "Backend", Color: "208", Description: "APIs, data and server logic"},
+	{Name: "tests", DisplayName: "Tests", Color: "197", Description: "Unit, integration and E2E tests"},
+	{Name: "docs", DisplayName: "Docs", Color: "220", Description: "Documentation"},
+	{Name: "planner", DisplayName: "Planner", Color: "141", Description: "Breaks work down into tasks"},
+	{Name: "architect", DisplayName: "Architect", Color: "75", Description: "System design and structure"},
+	{Name: "reviewer", DisplayName: "Reviewer", Color: "114", Description: "Code review"},
+	{Name: "tester", DisplayName: "Tester", Color: "197", Description: "Manual and exploratory testing"},
+}
+
+// builtinColors also covers aliases that appear in older tasks.json files
+var builtinColors = map[string]string{"ui": "39", "api": "208"}
+
+// fallbackPalette colors agents without a configured color, picked by name hash
+var fallbackPalette = []string{"33", "99", "135", "166", "172", "43", "71", "168"}
+
+// AgentCatalog is the set of known agents
+type AgentCatalog struct {
+	agents []AgentDef
+	byName map[string]AgentDef
+}
+
+// NewAgentCatalog builds a catalog from defs, sorted by name
+func NewAgentCatalog(defs []AgentDef) *AgentCatalog {
+	c := &AgentCatalog{byName: make(map[string]AgentDef, len(defs))}
+	for _, d := range defs {
+		c.byName[strings.ToLower(d.Name)] = d
+	}
+	for _, d := range c.byName {
+		c.agents = append(c.agents, d)
+	}
+	sort.Slice(c.agents, func(i, j int) bool { return c.agents[i].Name < c.agents[j].Name })
+	return c
+}
+
+// DefaultAgentCatalog returns the built-in agents
+func DefaultAgentCatalog() *AgentCatalog {
+	return NewAgentCatalog(builtinAgents)
+}
+
+// LoadAgentCatalog reads every .claude/agents/*.json. Without any definition
+// the built-in agents are returned. Files that fail to parse are reported in
+// the error, but the remaining agents are still returned.
+func (p *Project) LoadAgentCatalog() (*AgentCatalog, error) {
+	files, _ := filepath.Glob(filepath.Join(p.AgentsDir(), "*.json"))
+	if len(files) == 0 {
+		return DefaultAgentCatalog(), nil
+	}
+
+	var defs []AgentDef
+	var bad []string
+	for _, f := range files {
+		def, err := readAgentDef(f)
+		if err != nil {
+			bad = append(bad, fmt.Sprintf("%s: %v", filepath.Base(f), err))
+			continue
+		}
+		defs = append(defs, def)
+	}
+	c := NewAgentCatalog(defs)
+	if len(bad) > 0 {
+		return c, fmt.Errorf("invalid agent definitions: %s", strings.Join(bad, "; "))
+	}
+	return c, nil
+}
+
+// readAgentDef reads one agent file. The agent is named after the file; the
+// other fields are optional since the scripts keep prompts in the same file.
+func readAgentDef(path string) (AgentDef, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return AgentDef{}, err
+	}
+	var aux struct {
+		AgentDef
+		Capabilities json.RawMessage `json:"capabilities"`
+	}
+	if err := json.Unmarshal(raw, &aux); err != nil {
+		return AgentDef{}, err
+	}
+	def := aux.AgentDef
+	def.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	// capabilities may be a list or a comma-separated string
+	var list []string
+	var joined string
+	if err := json.Unmarshal(aux.Capabilities, &list); err == nil {
+		def.Capabilities = list
+	} else if err := json.Unmarshal(aux.Capabilities, &joined); err == nil {
+		for _, c := range strings.Split(joined, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				def.Capabilities = append(def.Capabilities, c)
+			}
+		}
+	}
+	return def, nil
+}
+
+// Agents returns every agent sorted by name
+func (c *AgentCatalog) Agents() []AgentDef {
+	if c == nil {
+		return DefaultAgentCatalog().agents
+	}
+	return c.agents
+}
+
+// Names returns the agent names sorted
+func (c *AgentCatalog) Names() []string {
+	agents := c.Agents()
+	names := make([]string, len(agents))
+	for i, a := range agents {
+		names[i] = a.Name
+	}
+	return names
+}
+
+// Lookup finds an agent by name, ignoring case
+func (c *AgentCatalog) Lookup(name string) (AgentDef, bool) {
+	if c == nil {
+		c = DefaultAgentCatalog()
+	}
+	d, ok := c.byName[strings.ToLower(name)]
+	return d, ok
+}
+
+// DisplayName returns the display name of an agent, or its name
+func (c *AgentCatalog) DisplayName(name string) string {
+	if d, ok := c.Lookup(name); ok && d.DisplayName != "" {
+		return d.DisplayName
+	}
+	return name
+}
+
+// Color returns the configured color of an agent, the built-in color for
+// well-known names, or a stable color derived from the name
+func (c *AgentCatalog) Color(name string) string {
+	if d, ok := c.Lookup(name); ok && d.Color != "" {
+		return d.Color
+	}
+	lower := strings.ToLower(name)
+	for _, d := range builtinAgents {
+		if d.Name == lower {
+			return d.Color
+		}
+	}
+	if color, ok := builtinColors[lower]; ok {
+		return color
+	}
+	h := fnv.New32a()
+	h.Write([]byte(lower))
+	return fallbackPalette[h.Sum32()%uint32(len(fallbackPalette))]
+}
+
+// AgentCatalogMsg carries a freshly loaded agent catalog
+type AgentCatalogMsg struct {
+	Catalog *AgentCatalog
+	Err     error // problems with individual files; Catalog is still usable
+}
+
+// LoadAgentCatalogCmd reads the agent definitions of the current project
+func LoadAgentCatalogCmd() tea.Cmd {
+	return func() tea.Msg {
+		p, err := CurrentProject()
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		c, err := p.LoadAgentCatalog()
+		return AgentCatalogMsg{Catalog: c, Err: err}
+	}
+}
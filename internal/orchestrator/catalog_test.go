@@ -0,0 +1,66 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadAgentCatalog(t *testing.T) {
+	p := &Project{Root: t.TempDir()}
+
+	c, err := p.LoadAgentCatalog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Lookup("backend"); !ok {
+		t.Fatal("expected built-in agents without definitions")
+	}
+
+	dir := p.AgentsDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"security.json": `{"display_name": "Security", "color": "#ff0000", "capabilities": ["audit", "threat-model"]}`,
+		"mobile.json":   `{"description": "iOS and Android", "capabilities": "swift, kotlin", "prompt": "..."}`,
+		"broken.json":   `{`,
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err = p.LoadAgentCatalog()
+	if err == nil {
+		t.Error("expected an error for broken.json")
+	}
+	if got := c.Names(); !reflect.DeepEqual(got, []string{"mobile", "security"}) {
+		t.Fatalf("unexpected agents %v", got)
+	}
+	if _, ok := c.Lookup("backend"); ok {
+		t.Error("built-in agents should not be mixed with project definitions")
+	}
+
+	sec, _ := c.Lookup("Security")
+	if sec.Color != "#ff0000" || !reflect.DeepEqual(sec.Capabilities, []string{"audit", "threat-model"}) {
+		t.Errorf("unexpected security agent %+v", sec)
+	}
+	mobile, _ := c.Lookup("mobile")
+	if !reflect.DeepEqual(mobile.Capabilities, []string{"swift", "kotlin"}) {
+		t.Errorf("unexpected mobile capabilities %v", mobile.Capabilities)
+	}
+	if c.DisplayName("security") != "Security" || c.DisplayName("mobile") != "mobile" {
+		t.Errorf("unexpected display names %q %q", c.DisplayName("security"), c.DisplayName("mobile"))
+	}
+
+	// colors fall back to the built-in table, then to a stable hash
+	if got := c.Color("backend"); got != "208" {
+		t.Errorf("expected built-in color for backend, got %s", got)
+	}
+	if c.Color("mobile") != c.Color("mobile") || c.Color("mobile") == "" {
+		t.Error("fallback color should be stable")
+	}
+}
@@ -1,32 +1,16 @@
 package orchestrator
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"os"
 	"os/exec"
 	"runtime"
+	"strings"
 	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// Task represents a task in the system
-type Task struct {
-	ID          int    `json:"id"`
-	Description string `json:"description"`
-	Status      string `json:"status"` // pending, in_progress, completed, failed
-	Agent       string `json:"agent"`
-	Priority    string `json:"priority"`
-	UpdatedAt   string `json:"updated_at"`
-}
-
-type TasksData struct {
-	Tasks  []Task `json:"tasks"`
-	LastID int    `json:"last_id"`
-}
-
 // Msg types
 type TaskLoadMsg []Task
 type ErrorMsg error
@@ -34,260 +18,397 @@ type ErrorMsg error
 // FetchTasksCmd reads tasks.json and returns a message
 func FetchTasksCmd() tea.Cmd {
 	return func() tea.Msg {
-		// Assuming we are running from project root or know where .claude is
-		// Try to find .claude/tasks.json. 
-		// For now, let's hardcode a path or look in current dir/.claude
-		path := ".claude/tasks.json"
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			// Fallback for testing from different dirs
-			path = "../../.claude/tasks.json" 
-		}
-
-		data, err := os.ReadFile(path)
+		store, err := currentStore()
 		if err != nil {
-			return ErrorMsg(fmt.Errorf("failed to read tasks.json: %w", err))
+			return ErrorMsg(err)
 		}
-
-		var tasksData TasksData
-		if err := json.Unmarshal(data, &tasksData); err != nil {
+		data, err := store.Load()
+		if err != nil {
 			return ErrorMsg(fmt.Errorf("failed to list tasks: %w", err))
 		}
-		return TaskLoadMsg(tasksData.Tasks)
+		return TaskLoadMsg(data.Tasks)
 	}
 }
 
-// AddTaskCmd executes the orchestrator script to add a task, optionally with an agent
+// currentStore returns the TaskStore of the current project
+func currentStore() (*TaskStore, error) {
+	p, err := CurrentProject()
+	if err != nil {
+		return nil, err
+	}
+	return p.Store(), nil
+}
+
+// AddTaskCmd adds a pending task, optionally assigned to an agent
 func AddTaskCmd(desc string, agent string) tea.Cmd {
-	scriptPath := findScriptPath()
-	args := []string{scriptPath, "add", desc}
-	if agent != "" {
-		args = append(args, agent)
-	}
-
-	c := exec.Command("bash", args...)
-	// Remove ORCH_AUTO_CONFIRM=yes to allow interactive mode
-	// Remove USE_AI=false to allow AI usage if configured (or keep it if we want speed?)
-	// Actually, the user wants interactive agent selection, so we should allow interaction.
-	// We should probably NOT force any env vars that disable interaction.
-	// However, we might want to keep ORCH_NO_AUTO_LAUNCH=yes if we don't want it to auto launch.
-	// But let's remove them to match standard behavior for now, or just keep ORCH_NO_AUTO_LAUNCH.
-	// The user's goal is agent selection.
-	c.Env = os.Environ()
-	// If we want to force interactive mode for agent selection, we might need to ensure
-	// we don't pass -y or similar flags if they existed. But here we just used to set env vars.
-
-	c.Stdin = os.Stdin
-	c.Stdout = os.Stdout
-	c.Stderr = os.Stderr
-
-	return tea.ExecProcess(c, func(err error) tea.Msg {
-		// Ignore signal errors (Ctrl+C is normal cancel)
-		if err != nil && !isSignalError(err) {
-			return ErrorMsg(err)
+	return func() tea.Msg {
+		err := journaled("add task", func(s *TaskStore) ([]int, error) {
+			t, err := s.Add(desc, agent, "")
+			return []int{t.ID}, err
+		})
+		if err != nil {
+			return ErrorMsg(fmt.Errorf("add task failed: %w", err))
 		}
 		return FetchTasksCmd()()
-	})
+	}
 }
 
-// StartTaskCmd executes orchestrator.sh start <id>
+// StartTaskCmd moves a task to in_progress and makes sure its agent is running.
+// Tasks with unfinished dependencies are refused with ErrBlocked.
 func StartTaskCmd(id int) tea.Cmd {
+	return startTaskCmd(id, false)
+}
+
+// ForceStartTaskCmd starts a task even if its dependencies are not done
+func ForceStartTaskCmd(id int) tea.Cmd {
+	return startTaskCmd(id, true)
+}
+
+func startTaskCmd(id int, force bool) tea.Cmd {
 	return func() tea.Msg {
-		scriptPath := findScriptPath()
-		cmd := exec.Command("bash", scriptPath, "start", fmt.Sprintf("%d", id))
-		output, err := cmd.CombinedOutput()
+		p, err := CurrentProject()
 		if err != nil {
-			// エラー時もリフレッシュして画面の状態を同期
-			_ = FetchTasksCmd()()
-			return ErrorMsg(fmt.Errorf("start task failed: %v\nOutput: %s", err, output))
+			return ErrorMsg(err)
+		}
+		// the agent is spawned outside the journal: what it writes is not
+		// part of the start
+		var t Task
+		err = p.Journal().Record(fmt.Sprintf("start #%d", id), func(*TaskStore) ([]int, error) {
+			var err error
+			t, err = p.startTask(id, force)
+			return []int{id}, err
+		})
+		if err == nil {
+			err = p.ensureAgent(t)
+		}
+		if err != nil {
+			return ErrorMsg(err)
 		}
 		return FetchTasksCmd()()
 	}
 }
 
-// CompleteTaskCmd executes orchestrator.sh complete <id>
+// StartTask moves a task to in_progress in the current project and spawns its
+// agent when it is not running yet. force ignores unfinished dependencies.
+func StartTask(id int, force bool) (Task, error) {
+	p, err := CurrentProject()
+	if err != nil {
+		return Task{}, err
+	}
+	return p.StartTask(id, force)
+}
+
+// StartTask moves a task of p to in_progress and spawns its agent when it is
+// not running yet
+func (p *Project) StartTask(id int, force bool) (Task, error) {
+	t, err := p.startTask(id, force)
+	if err != nil {
+		return t, err
+	}
+	return t, p.ensureAgent(t)
+}
+
+// startTask moves a task to in_progress and records the commit it starts from
+func (p *Project) startTask(id int, force bool) (Task, error) {
+	store := p.Store()
+	start := store.Start
+	if force {
+		start = store.ForceStart
+	}
+	t, err := start(id)
+	if err != nil {
+		return t, fmt.Errorf("start task failed: %w", err)
+	}
+	p.recordBaseCommit(&t)
+	return t, nil
+}
+
+// ensureAgent spawns the agent of a started task when it is not running yet
+func (p *Project) ensureAgent(t Task) error {
+	if t.Agent == "" {
+		return nil
+	}
+	if _, alive := p.AgentPID(t.Agent); alive {
+		return nil
+	}
+	return p.SpawnAgent(t.Agent)
+}
+
+// recordBaseCommit remembers the commit a task started from, so its changes
+// can be reviewed later. Projects outside git are left alone.
+func (p *Project) recordBaseCommit(t *Task) {
+	if t.BaseCommit != "" {
+		return
+	}
+	head, err := p.HeadCommit()
+	if err != nil || head == "" {
+		return
+	}
+	if updated, err := p.Store().Modify(t.ID, func(task *Task) error {
+		if task.BaseCommit == "" {
+			task.BaseCommit = head
+		}
+		return nil
+	}); err == nil {
+		*t = updated
+	}
+}
+
+// CompleteTaskCmd marks a task as completed
 func CompleteTaskCmd(id int) tea.Cmd {
 	return func() tea.Msg {
-		scriptPath := findScriptPath()
-		cmd := exec.Command("bash", scriptPath, "complete", fmt.Sprintf("%d", id))
-		output, err := cmd.CombinedOutput()
+		err := journaled(fmt.Sprintf("complete #%d", id), func(s *TaskStore) ([]int, error) {
+			_, err := s.Complete(id)
+			return []int{id}, err
+		})
 		if err != nil {
-			// エラー時もリフレッシュ
-			_ = FetchTasksCmd()()
-			return ErrorMsg(fmt.Errorf("complete task failed: %v\nOutput: %s", err, output))
+			return ErrorMsg(fmt.Errorf("complete task failed: %w", err))
 		}
 		return FetchTasksCmd()()
 	}
 }
 
-// StopTaskCmd executes orchestrator.sh stop <id>
+// StopTaskCmd marks a task as stopped and stops its agent
 func StopTaskCmd(id int) tea.Cmd {
 	return func() tea.Msg {
-		// First, verify the task exists and has an agent
-		scriptPath := findScriptPath()
-
-		// Check if task exists and get agent
-		checkCmd := exec.Command("bash", scriptPath, "check-task", fmt.Sprintf("%d", id))
-		output, err := checkCmd.CombinedOutput()
+		p, err := CurrentProject()
 		if err != nil {
-			return ErrorMsg(fmt.Errorf("task #%d not found or error checking: %v\nOutput: %s", id, err, output))
+			return ErrorMsg(err)
+		}
+		// the agent is stopped outside the journal, as it is spawned outside
+		// it by startTaskCmd
+		var t Task
+		err = p.Journal().Record(fmt.Sprintf("stop #%d", id), func(s *TaskStore) ([]int, error) {
+			var err error
+			t, err = s.Stop(id)
+			return []int{id}, err
+		})
+		if err == nil {
+			err = p.releaseAgent(t)
 		}
-
-		// Now try to stop
-		cmd := exec.Command("bash", scriptPath, "stop", fmt.Sprintf("%d", id))
-		output, err = cmd.CombinedOutput()
 		if err != nil {
-			// エラー時も状態を同期
-			_ = FetchTasksCmd()()
-			return ErrorMsg(fmt.Errorf("stop task failed: %v\nOutput: %s", err, output))
+			return ErrorMsg(fmt.Errorf("stop task failed: %w", err))
 		}
 		return FetchTasksCmd()()
 	}
 }
 
-
 // EditTaskCmd updates the description of a task
 func EditTaskCmd(id int, newDescription string) tea.Cmd {
 	return func() tea.Msg {
-		// Calculate tasks.json path (same logic as FetchTasksCmd)
-		path := ".claude/tasks.json"
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			path = "../../.claude/tasks.json"
+		err := journaled(fmt.Sprintf("edit #%d", id), func(s *TaskStore) ([]int, error) {
+			_, err := s.Edit(id, newDescription)
+			return []int{id}, err
+		})
+		if err != nil {
+			return ErrorMsg(fmt.Errorf("edit task failed: %w", err))
 		}
+		return FetchTasksCmd()()
+	}
+}
 
-		data, err := os.ReadFile(path)
+// ReplaceTaskCmd overwrites every field of a task
+func ReplaceTaskCmd(t Task) tea.Cmd {
+	return func() tea.Msg {
+		err := journaled(fmt.Sprintf("edit #%d", t.ID), func(s *TaskStore) ([]int, error) {
+			_, err := s.Replace(t)
+			return []int{t.ID}, err
+		})
 		if err != nil {
-			return ErrorMsg(fmt.Errorf("failed to read tasks.json for editing: %w", err))
+			return ErrorMsg(fmt.Errorf("edit task failed: %w", err))
 		}
+		return FetchTasksCmd()()
+	}
+}
 
-		// Use Decoder with UseNumber to preserve numeric precision/type
-		var root map[string]interface{}
-		decoder := json.NewDecoder(bytes.NewReader(data))
-		decoder.UseNumber()
-		if err := decoder.Decode(&root); err != nil {
-			return ErrorMsg(fmt.Errorf("failed to parse tasks.json for editing: %w", err))
+// RemoveTaskCmd deletes a task from tasks.json
+func RemoveTaskCmd(id int) tea.Cmd {
+	return func() tea.Msg {
+		err := journaled(fmt.Sprintf("remove #%d", id), func(s *TaskStore) ([]int, error) {
+			return []int{id}, s.Remove(id)
+		})
+		if err != nil {
+			return ErrorMsg(fmt.Errorf("remove task failed: %w", err))
 		}
+		return FetchTasksCmd()()
+	}
+}
 
-        tasksList, ok := root["tasks"].([]interface{})
-        if !ok {
-             return ErrorMsg(fmt.Errorf("invalid tasks.json format: tasks field missing or not an array"))
-        }
-
-		// Find and update task
-		found := false
-		for _, item := range tasksList {
-            taskMap, ok := item.(map[string]interface{})
-            if !ok {
-                continue
-            }
-            
-            // ID is generic number, handle as json.Number if UseNumber() was used
-            idVal := taskMap["id"]
-            var taskID int
-            switch v := idVal.(type) {
-            case json.Number:
-                 i, _ := v.Int64()
-                 taskID = int(i)
-            case float64:
-                 taskID = int(v)
-            case int:
-                 taskID = v
-            }
-            
-            if taskID == id {
-                taskMap["description"] = newDescription
-                found = true
-                break
-            }
+// SpawnAgentCmd launches an agent in watch mode
+func SpawnAgentCmd(agentName string) tea.Cmd {
+	return func() tea.Msg {
+		if err := SpawnAgent(agentName); err != nil {
+			return ErrorMsg(err)
 		}
+		// ステータスを即座に更新（[RUNNING] 表示にするため）するためにリフレッシュを発行
+		return func() tea.Msg { return FetchTasksCmd()() }
+	}
+}
 
-		if !found {
-			return ErrorMsg(fmt.Errorf("task #%d not found", id))
-		}
-        
-        // No need to re-assign tasksList to root["tasks"] because slice elements are pointers/references to map?
-        // Wait, slice of interface{} contains maps. Maps are references. So modifying taskMap works.
+// SpawnAgent starts an agent of the current project
+func SpawnAgent(agentName string) error {
+	p, err := CurrentProject()
+	if err != nil {
+		return err
+	}
+	return p.SpawnAgent(agentName)
+}
 
-		// Write back to file
-		updatedData, err := json.MarshalIndent(root, "", "  ")
-		if err != nil {
-			return ErrorMsg(fmt.Errorf("failed to marshal updated tasks: %w", err))
-		}
+// SpawnAgent starts agent.sh watch <agent>. With a Supervisor of p installed
+// the agent is supervised; otherwise it is started detached and left running.
+func (p *Project) SpawnAgent(agentName string) error {
+	if s := currentSupervisor(); s != nil && s.project.Root == p.Root {
+		return s.Spawn(agentName)
+	}
 
-		if err := os.WriteFile(path, updatedData, 0644); err != nil {
-			return ErrorMsg(fmt.Errorf("failed to write tasks.json: %w", err))
-		}
+	cmd := exec.Command("bash", p.AgentScriptPath(), "watch", agentName)
+	cmd.Dir = p.Root
 
-		return FetchTasksCmd()()
+	// SysProcAttr.Setsid = true により OS レベルで新しいセッションを作成する。
+	// これにより TUI の終了シグナル（SIGINT/SIGTERM/SIGHUP）が
+	// エージェントプロセスに伝播しなくなる。
+	// setsid コマンドに依存せず macOS / Linux 両対応。
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+
+	// 標準出力・標準エラーは .claude/logs/agent-<name>.log に追記する
+	// （ダッシュボードの画面を汚さず、クラッシュ原因を後から追えるように）
+	out, err := openAgentLog(p, agentName)
+	if err != nil {
+		return fmt.Errorf("failed to open agent log: %w", err)
 	}
+	defer out.Close()
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to spawn agent %s: %w", agentName, err)
+	}
+	// プロセスを背後に残すので Wait はしない。Release で子プロセスの
+	// ハンドルを手放す（監視が必要な場合は Supervisor を使う）
+	return cmd.Process.Release()
 }
 
-// RemoveTaskCmd executes orchestrator.sh remove-task <id>
-func RemoveTaskCmd(id int) tea.Cmd {
+// AgentsLoadMsg carries the status of every agent
+type AgentsLoadMsg []AgentStatus
+
+// FetchAgentsCmd reads pid files, /proc and agent logs
+func FetchAgentsCmd() tea.Cmd {
 	return func() tea.Msg {
-		scriptPath := findScriptPath()
-		cmd := exec.Command("bash", scriptPath, "remove-task", fmt.Sprintf("%d", id))
-		// We might need to auto-confirm if it asks "Are you sure?"
-		// But usually orchestration commands are non-interactive unless critical.
-		// Let's assume non-interactive or pipe "y" if needed.
-		// Checking remove_task: it asks for confirmation if task count > 0 (for agents).
-		// Wait, remove_task removes a task by ID.
-		// "remove_agent" asks for confirmation.
-		output, err := cmd.CombinedOutput()
+		p, err := CurrentProject()
 		if err != nil {
-			// エラー時もリフレッシュ
-			_ = FetchTasksCmd()()
-			return ErrorMsg(fmt.Errorf("remove task failed: %v\nOutput: %s", err, output))
+			return ErrorMsg(err)
 		}
-		return FetchTasksCmd()()
+		return AgentsLoadMsg(p.AgentStatuses())
 	}
 }
 
-// SpawnAgentCmd launches an agent in watch mode
-func SpawnAgentCmd(agentName string) tea.Cmd {
+// StopAgentCmd stops an agent and cancels its automatic restarts
+func StopAgentCmd(agentName string) tea.Cmd {
+	return func() tea.Msg {
+		if err := StopAgent(agentName); err != nil {
+			return ErrorMsg(err)
+		}
+		return FetchAgentsCmd()()
+	}
+}
+
+// RestartAgentCmd stops an agent and starts it again
+func RestartAgentCmd(agentName string) tea.Cmd {
 	return func() tea.Msg {
-		// agent.sh のパスを探す
-		scriptPath := ".claude/agent.sh"
-		if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
-			scriptPath = "../agent.sh"
+		if s := currentSupervisor(); s != nil {
+			if err := s.Restart(agentName); err != nil {
+				return ErrorMsg(err)
+			}
+			return FetchAgentsCmd()()
 		}
-		if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
-			scriptPath = "../../.claude/agent.sh"
+		if err := StopAgent(agentName); err != nil {
+			return ErrorMsg(err)
 		}
+		if err := SpawnAgent(agentName); err != nil {
+			return ErrorMsg(err)
+		}
+		return FetchAgentsCmd()()
+	}
+}
 
-		cmd := exec.Command("bash", scriptPath, "watch", agentName)
+// StopTask marks a task as stopped in the current project and stops its
+// agent unless another of its tasks is still in progress
+func StopTask(id int) (Task, error) {
+	p, err := CurrentProject()
+	if err != nil {
+		return Task{}, err
+	}
+	return p.StopTask(id)
+}
 
-		// SysProcAttr.Setsid = true により OS レベルで新しいセッションを作成する。
-		// これにより TUI の終了シグナル（SIGINT/SIGTERM/SIGHUP）が
-		// エージェントプロセスに伝播しなくなる。
-		// setsid コマンドに依存せず macOS / Linux 両対応。
-		cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+// StopTask marks a task as stopped and stops its agent unless another of
+// its tasks is still in progress
+func (p *Project) StopTask(id int) (Task, error) {
+	t, err := p.Store().Stop(id)
+	if err != nil {
+		return t, err
+	}
+	return t, p.releaseAgent(t)
+}
 
-		// 標準入出力をすべて /dev/null に向けてデーモン化する
-		// （ダッシュボードの画面を汚さないため）
-		devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
-		if err == nil {
-			cmd.Stdin = devNull
-			cmd.Stdout = devNull
-			cmd.Stderr = devNull
+// releaseAgent stops the agent of a stopped task unless another of its
+// tasks is still in progress
+func (p *Project) releaseAgent(t Task) error {
+	if t.Agent == "" {
+		return nil
+	}
+	data, err := p.Store().Load()
+	if err != nil {
+		return err
+	}
+	for _, other := range data.Tasks {
+		if other.ID != t.ID && other.Status == StatusInProgress && strings.EqualFold(other.Agent, t.Agent) {
+			return nil
 		}
+	}
+	if err := p.StopAgent(t.Agent); err != nil {
+		return fmt.Errorf("task #%d stopped, but its agent did not: %w", t.ID, err)
+	}
+	return nil
+}
 
-		if err := cmd.Start(); err != nil {
-			return ErrorMsg(fmt.Errorf("failed to spawn agent %s: %w", agentName, err))
-		}
+// StopAgent stops an agent of the current project
+func StopAgent(agentName string) error {
+	p, err := CurrentProject()
+	if err != nil {
+		return err
+	}
+	return p.StopAgent(agentName)
+}
 
-		// プロセスを背後に残すので Wait はしない
-		// ステータスを即座に更新（[RUNNING] 表示にするため）するためにリフレッシュを発行
-		return func() tea.Msg { return FetchTasksCmd()() }
+// StopAgent stops an agent through the Supervisor, or by signalling the PID
+// in its pid file when nothing supervises it
+func (p *Project) StopAgent(agentName string) error {
+	if s := currentSupervisor(); s != nil && s.project.Root == p.Root {
+		return s.Stop(agentName)
+	}
+	pid, alive := p.AgentPID(agentName)
+	if !alive {
+		return nil
+	}
+	signalGroup(pid, syscall.SIGTERM)
+	for i := 0; i < 50; i++ {
+		if syscall.Kill(pid, 0) != nil {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
 	}
+	return fmt.Errorf("agent %s (pid %d) did not stop", agentName, pid)
 }
 
 // OpenTaskCmd opens the tasks.json file or specific task file
 func OpenTaskCmd(id int) tea.Cmd {
 	return func() tea.Msg {
-		path := ".claude/tasks.json"
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			path = "../../.claude/tasks.json"
+		p, err := CurrentProject()
+		if err != nil {
+			return ErrorMsg(err)
 		}
+		path := p.TasksPath()
 
 		var cmd *exec.Cmd
 		if runtime.GOOS == "darwin" {
@@ -302,76 +423,3 @@ func OpenTaskCmd(id int) tea.Cmd {
 		return nil
 	}
 }
-
-// isSignalError checks if the error is due to a signal (e.g., Ctrl+C)
-// exit codes 128+n means signal n was received (130 = SIGINT)
-func isSignalError(err error) bool {
-	if err == nil {
-		return false
-	}
-	if exitErr, ok := err.(*exec.ExitError); ok {
-		// 130 = 128 + SIGINT(2), 129 = 128 + SIGHUP(1), etc.
-		// Common signals: 1 (SIGHUP), 2 (SIGINT), 15 (SIGTERM)
-		exitCode := exitErr.ExitCode()
-		return exitCode >= 128 && exitCode <= 143
-	}
-	return false
-}
-
-// LogsTuiCmd executes orchestrator.sh logs-tui with raw-task mode
-// Shows the full Claude execution log for the task
-func LogsTuiCmd(id int) tea.Cmd {
-	scriptPath := findScriptPath()
-	// Use --raw-task to show Claude execution logs (verbose level)
-	args := []string{scriptPath, "logs-tui", "--raw-task", fmt.Sprintf("%d", id)}
-	c := exec.Command("bash", args...)
-	c.Stdin = os.Stdin
-	c.Stdout = os.Stdout
-	c.Stderr = os.Stderr
-	return tea.ExecProcess(c, func(err error) tea.Msg {
-		// Ignore signal errors (Ctrl+C is normal exit)
-		if err != nil && !isSignalError(err) {
-			return ErrorMsg(err)
-		}
-		return nil
-	})
-}
-
-// OpenRawLogCmd executes tui-logs.sh --raw-task <id> (same as LogsTuiCmd now)
-// Kept for backward compatibility with [V] Verbose command
-func OpenRawLogCmd(id int) tea.Cmd {
-	scriptPath := findScriptPath()
-	args := []string{scriptPath, "logs-tui", "--raw-task", fmt.Sprintf("%d", id)}
-	c := exec.Command("bash", args...)
-	c.Stdin = os.Stdin
-	c.Stdout = os.Stdout
-	c.Stderr = os.Stderr
-	return tea.ExecProcess(c, func(err error) tea.Msg {
-		// Ignore signal errors (Ctrl+C is normal exit)
-		if err != nil && !isSignalError(err) {
-			return ErrorMsg(err)
-		}
-		return nil
-	})
-}
-
-func findScriptPath() string {
-    // When running from project root (typical case)
-    if _, err := os.Stat(".claude/scripts/orchestrator.sh"); err == nil {
-        return ".claude/scripts/orchestrator.sh"
-    }
-
-    // When running from installed bin location (~/project/.claude/bin/control-center)
-    // We need to look up one level to .claude/scripts/orchestrator.sh
-    if _, err := os.Stat("../scripts/orchestrator.sh"); err == nil {
-        return "../scripts/orchestrator.sh"
-    }
-    
-    // Absolute fallback (User's specific path if all else fails - risky but useful for debugging)
-    // Better: Assume if we are in bin, scripts is adjacent.
-    // If we are control-center binary, look relative to executable?
-    // For now, let's return a best guess that covers the install.sh case.
-    // install.sh puts binary in .claude/bin
-    // scripts are in .claude/scripts
-    return "../scripts/orchestrator.sh"
-}
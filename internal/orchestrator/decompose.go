@@ -0,0 +1,252 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ErrInvalidDecomposition matches every DecompositionError
+var ErrInvalidDecomposition = errors.New("invalid decomposition")
+
+// Codes of DecompositionError, as reported by validate_decomposition
+const (
+	DecompInvalidJSON         = "invalid_json"
+	DecompMissingSubtasks     = "missing_subtasks"
+	DecompNoSubtasks          = "no_subtasks"
+	DecompMissingFields       = "missing_required_fields"
+	DecompInvalidAgent        = "invalid_agent"
+	DecompInvalidDependencies = "invalid_dependencies"
+)
+
+// DecompositionError is a plan that does not follow the subtask schema
+type DecompositionError struct {
+	Code   string
+	Detail string
+}
+
+func (e *DecompositionError) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("invalid decomposition (%s)", e.Code)
+	}
+	return fmt.Sprintf("invalid decomposition (%s): %s", e.Code, e.Detail)
+}
+
+// Is makes errors.Is(err, ErrInvalidDecomposition) hold
+func (e *DecompositionError) Is(target error) bool {
+	return target == ErrInvalidDecomposition
+}
+
+func decompError(code, format string, args ...any) error {
+	return &DecompositionError{Code: code, Detail: fmt.Sprintf(format, args...)}
+}
+
+// Subtask is one proposed piece of a decomposed task
+type Subtask struct {
+	Description  string `json:"description"`
+	Agent        string `json:"agent"`
+	Rationale    string `json:"rationale,omitempty"`
+	Dependencies []int  `json:"dependencies,omitempty"` // indexes of other subtasks
+}
+
+// Decomposition is a proposed split of a task, in the JSON format of
+// decompose_task_ai (see docs/y-feature-api-spec.md)
+type Decomposition struct {
+	Subtasks []Subtask `json:"subtasks"`
+}
+
+// Validate checks that every subtask has a description and that the
+// dependencies point at other subtasks without forming a cycle. Subtasks
+// may be unassigned; see CheckSchema for proposed plans.
+func (d Decomposition) Validate() error {
+	if len(d.Subtasks) == 0 {
+		return decompError(DecompNoSubtasks, "")
+	}
+	tasks := make([]Task, len(d.Subtasks))
+	for i, s := range d.Subtasks {
+		if strings.TrimSpace(s.Description) == "" {
+			return decompError(DecompMissingFields, "subtask %d has no description", i+1)
+		}
+		for _, dep := range s.Dependencies {
+			if dep < 0 || dep >= len(d.Subtasks) || dep == i {
+				return decompError(DecompInvalidDependencies, "subtask %d depends on unknown subtask %d", i+1, dep+1)
+			}
+		}
+		tasks[i] = Task{ID: i + 1, Dependencies: subtaskIDs(s.Dependencies, 1)}
+	}
+	if cycles := NewGraph(tasks).Cycles(); len(cycles) > 0 {
+		return decompError(DecompInvalidDependencies, "dependency cycle between subtasks %s", formatCycles(cycles))
+	}
+	return nil
+}
+
+// CheckSchema validates a proposed plan against the subtask JSON schema:
+// on top of Validate, every subtask names an agent of the catalog
+func (d Decomposition) CheckSchema(c *AgentCatalog) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	for i, s := range d.Subtasks {
+		if s.Agent == "" {
+			return decompError(DecompMissingFields, "subtask %d has no agent", i+1)
+		}
+		if _, ok := c.Lookup(s.Agent); !ok {
+			return decompError(DecompInvalidAgent, "subtask %d: unknown agent %q", i+1, s.Agent)
+		}
+	}
+	return nil
+}
+
+// Move swaps subtask i with its neighbour delta (±1) away, keeping the
+// dependencies on the same subtasks. It returns the new index of i.
+func (d *Decomposition) Move(i, delta int) int {
+	j := i + delta
+	if i < 0 || i >= len(d.Subtasks) || j < 0 || j >= len(d.Subtasks) {
+		return i
+	}
+	d.Subtasks[i], d.Subtasks[j] = d.Subtasks[j], d.Subtasks[i]
+	d.remap(func(k int) int {
+		switch k {
+		case i:
+			return j
+		case j:
+			return i
+		}
+		return k
+	})
+	return j
+}
+
+// Remove deletes subtask i and the dependencies on it
+func (d *Decomposition) Remove(i int) {
+	if i < 0 || i >= len(d.Subtasks) {
+		return
+	}
+	d.Subtasks = append(d.Subtasks[:i:i], d.Subtasks[i+1:]...)
+	d.remap(func(k int) int {
+		switch {
+		case k == i:
+			return -1
+		case k > i:
+			return k - 1
+		}
+		return k
+	})
+}
+
+// remap rewrites every dependency index with fn; negative results are dropped
+func (d *Decomposition) remap(fn func(int) int) {
+	for i := range d.Subtasks {
+		var deps []int
+		for _, dep := range d.Subtasks[i].Dependencies {
+			if k := fn(dep); k >= 0 {
+				deps = append(deps, k)
+			}
+		}
+		d.Subtasks[i].Dependencies = deps
+	}
+}
+
+// subtaskIDs turns subtask indexes into task IDs starting at first
+func subtaskIDs(deps []int, first int) []int {
+	ids := []int{}
+	for _, dep := range deps {
+		ids = append(ids, first+dep)
+	}
+	return ids
+}
+
+// AddSubtasks adds a decomposition as pending tasks in one write, turning
+// the subtask dependencies into dependencies on the new task IDs
+func (s *TaskStore) AddSubtasks(d Decomposition, priority string) ([]Task, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	if priority == "" {
+		priority = "normal"
+	}
+	var added []Task
+	now := s.timestamp()
+	err := s.Update(func(data *TasksData) error {
+		added = nil
+		first := data.LastID + 1
+		for i, st := range d.Subtasks {
+			added = append(added, Task{
+				ID:           first + i,
+				Description:  strings.TrimSpace(st.Description),
+				Status:       StatusPending,
+				Agent:        st.Agent,
+				Priority:     priority,
+				Dependencies: subtaskIDs(st.Dependencies, first),
+				CreatedAt:    now,
+				UpdatedAt:    now,
+			})
+		}
+		data.Tasks = append(data.Tasks, added...)
+		data.LastID += len(added)
+		return nil
+	})
+	return added, err
+}
+
+// ParseDecomposition extracts the decomposition JSON from a model answer,
+// ignoring markdown fences and text around the object, and checks that the
+// schema's fields are there
+func ParseDecomposition(out string) (Decomposition, error) {
+	start, end := strings.Index(out, "{"), strings.LastIndex(out, "}")
+	if start < 0 || end < start {
+		return Decomposition{}, decompError(DecompInvalidJSON, "no JSON object in the answer")
+	}
+	var raw struct {
+		Subtasks *[]map[string]json.RawMessage `json:"subtasks"`
+		Error    string                        `json:"error"`
+	}
+	body := []byte(out[start : end+1])
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return Decomposition{}, decompError(DecompInvalidJSON, "%v", err)
+	}
+	if raw.Error != "" {
+		return Decomposition{}, decompError(DecompInvalidJSON, "%s", raw.Error)
+	}
+	if raw.Subtasks == nil {
+		return Decomposition{}, decompError(DecompMissingSubtasks, "")
+	}
+	for i, st := range *raw.Subtasks {
+		for _, field := range []string{"description", "agent"} {
+			if _, ok := st[field]; !ok {
+				return Decomposition{}, decompError(DecompMissingFields, "subtask %d has no %s", i+1, field)
+			}
+		}
+	}
+	var d Decomposition
+	if err := json.Unmarshal(body, &d); err != nil {
+		return Decomposition{}, decompError(DecompInvalidJSON, "%v", err)
+	}
+	return d, nil
+}
+
+// SubtasksAddedMsg reports the tasks created from a decomposition
+type SubtasksAddedMsg []Task
+
+// AddSubtasksCmd adds the reviewed plan to tasks.json
+func AddSubtasksCmd(d Decomposition) tea.Cmd {
+	return func() tea.Msg {
+		var added []Task
+		err := journaled(fmt.Sprintf("add %d subtask(s)", len(d.Subtasks)), func(s *TaskStore) ([]int, error) {
+			var err error
+			added, err = s.AddSubtasks(d, "")
+			ids := make([]int, len(added))
+			for i, t := range added {
+				ids[i] = t.ID
+			}
+			return ids, err
+		})
+		if err != nil {
+			return ErrorMsg(fmt.Errorf("add subtasks failed: %w", err))
+		}
+		return SubtasksAddedMsg(added)
+	}
+}
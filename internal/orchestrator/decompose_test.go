@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecompositionEdits(t *testing.T) {
+	d := Decomposition{Subtasks: []Subtask{
+		{Description: "schema"},
+		{Description: "api", Dependencies: []int{0}},
+		{Description: "ui", Dependencies: []int{0, 1}},
+	}}
+	if i := d.Move(2, -1); i != 1 {
+		t.Fatalf("expected ui at 1, got %d", i)
+	}
+	if d.Subtasks[1].Description != "ui" || d.Subtasks[1].Dependencies[1] != 2 || d.Subtasks[2].Dependencies[0] != 0 {
+		t.Fatalf("dependencies not remapped after move: %+v", d.Subtasks)
+	}
+	if err := d.Validate(); err != nil {
+		t.Errorf("a dependency on a later subtask is fine: %v", err)
+	}
+
+	d.Remove(0)
+	if len(d.Subtasks) != 2 || len(d.Subtasks[0].Dependencies) != 1 || d.Subtasks[0].Dependencies[0] != 1 || len(d.Subtasks[1].Dependencies) != 0 {
+		t.Fatalf("dependencies not remapped after remove: %+v", d.Subtasks)
+	}
+
+	d.Subtasks[1].Dependencies = []int{0}
+	if err := d.Validate(); !errors.Is(err, ErrInvalidDecomposition) {
+		t.Errorf("expected a cycle to be rejected, got %v", err)
+	}
+	d.Subtasks[1].Dependencies = []int{5}
+	if err := d.Validate(); !errors.Is(err, ErrInvalidDecomposition) {
+		t.Errorf("expected an unknown subtask to be rejected, got %v", err)
+	}
+}
+
+func TestAddSubtasks(t *testing.T) {
+	s := newTestStore(t, `{"tasks": [{"id": 4, "description": "old", "status": "completed"}], "last_id": 4}`)
+	added, err := s.AddSubtasks(Decomposition{Subtasks: []Subtask{
+		{Description: "api", Agent: "backend"},
+		{Description: " ui ", Agent: "frontend", Dependencies: []int{0}},
+	}}, "")
+	if err != nil {
+		t.Fatalf("AddSubtasks: %v", err)
+	}
+	if len(added) != 2 || added[0].ID != 5 || added[1].ID != 6 || added[1].Description != "ui" {
+		t.Fatalf("unexpected tasks: %+v", added)
+	}
+	if deps := added[1].Dependencies; len(deps) != 1 || deps[0] != 5 {
+		t.Errorf("expected ui to depend on #5, got %v", deps)
+	}
+	data, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.LastID != 6 || len(data.Tasks) != 3 || data.Tasks[2].Status != StatusPending || data.Tasks[2].Priority != "normal" {
+		t.Errorf("unexpected store: %+v", data)
+	}
+
+	if _, err := s.AddSubtasks(Decomposition{Subtasks: []Subtask{{Description: ""}}}, ""); !errors.Is(err, ErrInvalidDecomposition) {
+		t.Errorf("expected an empty description to be rejected, got %v", err)
+	}
+	if data, _ := s.Load(); len(data.Tasks) != 3 {
+		t.Errorf("a rejected plan must not add tasks, got %d", len(data.Tasks))
+	}
+}
+
+func TestParseDecomposition(t *testing.T) {
+	d, err := ParseDecomposition("Here you go:\n```json\n{\"subtasks\": [{\"description\": \"a\", \"agent\": \"docs\", \"rationale\": \"r\", \"dependencies\": []}]}\n```\n")
+	if err != nil || len(d.Subtasks) != 1 || d.Subtasks[0].Agent != "docs" {
+		t.Fatalf("unexpected plan: %+v %v", d, err)
+	}
+	for out, code := range map[string]string{
+		"no json":                              DecompInvalidJSON,
+		`{"subtasks": [`:                       DecompInvalidJSON,
+		`{"error": "too vague"}`:               DecompInvalidJSON,
+		`{"tasks": []}`:                        DecompMissingSubtasks,
+		`{"subtasks": [{"description": "a"}]}`: DecompMissingFields,
+	} {
+		var derr *DecompositionError
+		if _, err := ParseDecomposition(out); !errors.As(err, &derr) || derr.Code != code || !errors.Is(err, ErrInvalidDecomposition) {
+			t.Errorf("%q: expected %s, got %v", out, code, err)
+		}
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	c := DefaultAgentCatalog()
+	for _, tc := range []struct {
+		plan Decomposition
+		code string
+	}{
+		{Decomposition{}, DecompNoSubtasks},
+		{Decomposition{Subtasks: []Subtask{{Description: "a"}}}, DecompMissingFields},
+		{Decomposition{Subtasks: []Subtask{{Description: "a", Agent: "designer"}}}, DecompInvalidAgent},
+		{Decomposition{Subtasks: []Subtask{{Description: "a", Agent: "docs", Dependencies: []int{0}}}}, DecompInvalidDependencies},
+	} {
+		var derr *DecompositionError
+		if err := tc.plan.CheckSchema(c); !errors.As(err, &derr) || derr.Code != tc.code {
+			t.Errorf("%+v: expected %s, got %v", tc.plan, tc.code, err)
+		}
+	}
+	if err := (Decomposition{Subtasks: []Subtask{{Description: "a", Agent: "Docs"}}}).CheckSchema(c); err != nil {
+		t.Errorf("expected a valid plan, got %v", err)
+	}
+}
@@ -0,0 +1,431 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Decomposer backends selectable in decomposer.json
+const (
+	DecomposerClaude = "claude" // Claude CLI with .claude/prompts/decompose_task.txt
+	DecomposerRules  = "rules"  // keyword rules, works offline
+	DecomposerScript = "script" // canned answers from a file, for tests and demos
+)
+
+// defaultClaudeTimeout bounds a Claude run unless configured or CLAUDE_TIMEOUT is set
+const defaultClaudeTimeout = 2 * time.Minute
+
+// defaultDecomposePrompt is used when .claude/prompts/decompose_task.txt is missing
+const defaultDecomposePrompt = `You split a software task into 3 to 8 subtasks that each take one or two hours.
+Assign every subtask to one of the agents: {AGENTS}.
+Answer with JSON only, without markdown:
+{"subtasks": [{"description": "...", "agent": "...", "rationale": "why this agent", "dependencies": [0]}]}
+dependencies are the 0-based indexes of the subtasks that must be done first.
+
+Task: {TASK_DESCRIPTION}`
+
+// DecomposeRequest asks for a plan. Feedback and Previous are set when the
+// user asked again about an earlier plan; Attempt counts the requests for
+// the same task from 1.
+type DecomposeRequest struct {
+	Task     string
+	Feedback string
+	Previous *Decomposition
+	Attempt  int
+}
+
+// Decomposer proposes subtasks for a task. Plans are checked against the
+// subtask schema by Decompose, whatever the backend.
+type Decomposer interface {
+	Name() string
+	Decompose(req DecomposeRequest) (Decomposition, error)
+}
+
+// Decompose runs dec and validates its plan against the agents of c
+func Decompose(dec Decomposer, req DecomposeRequest, c *AgentCatalog) (Decomposition, error) {
+	d, err := dec.Decompose(req)
+	if err != nil {
+		return Decomposition{}, err
+	}
+	if err := d.CheckSchema(c); err != nil {
+		return Decomposition{}, fmt.Errorf("%s: %w", dec.Name(), err)
+	}
+	return d, nil
+}
+
+// ClaudeDecomposer asks the Claude CLI, like decompose_task_ai
+type ClaudeDecomposer struct {
+	Project *Project
+	Agents  []string // offered to the model in the built-in prompt
+	Timeout time.Duration
+}
+
+func (c *ClaudeDecomposer) Name() string { return DecomposerClaude }
+
+// PromptPath returns .claude/prompts/decompose_task.txt
+func (c *ClaudeDecomposer) PromptPath() string {
+	return c.Project.Path("prompts", "decompose_task.txt")
+}
+
+func (c *ClaudeDecomposer) Decompose(req DecomposeRequest) (Decomposition, error) {
+	claude, err := exec.LookPath("claude")
+	if err != nil {
+		return Decomposition{}, fmt.Errorf("claude CLI not found: %w", err)
+	}
+	system := defaultDecomposePrompt
+	if b, err := os.ReadFile(c.PromptPath()); err == nil {
+		system = string(b)
+	}
+	system = strings.ReplaceAll(system, "{AGENTS}", strings.Join(c.Agents, ", "))
+	system = strings.ReplaceAll(system, "{TASK_DESCRIPTION}", req.Task)
+
+	input := "タスク: " + req.Task
+	if req.Previous != nil {
+		plan, _ := json.Marshal(req.Previous)
+		input += "\n前回のプラン: " + string(plan)
+	}
+	if req.Feedback != "" {
+		input += "\n前回のフィードバック: " + req.Feedback
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultClaudeTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, claude, "-p", "--system-prompt", system, "--output-format", "text", input)
+	cmd.Dir = c.Project.Root
+	out, err := cmd.Output()
+	if err != nil {
+		return Decomposition{}, fmt.Errorf("claude failed: %w", err)
+	}
+	return ParseDecomposition(string(out))
+}
+
+// decompRule is one keyword pattern of decompose_task_rules
+type decompRule struct {
+	keywords []string // lower case; ASCII words match whole words, others substrings
+	subtasks []Subtask
+}
+
+// decompRules are tried in order; the first match wins. Descriptions get
+// the task appended.
+var decompRules = []decompRule{
+	{[]string{"認証", "auth", "login", "ログイン"}, []Subtask{
+		{Description: "認証APIの実装", Agent: "backend", Rationale: "サーバー側の認証処理"},
+		{Description: "セッション・トークン管理の実装", Agent: "backend", Rationale: "認証状態の保持", Dependencies: []int{0}},
+		{Description: "ログイン画面の実装", Agent: "frontend", Rationale: "UIの実装", Dependencies: []int{0}},
+		{Description: "認証APIのテスト", Agent: "tests", Rationale: "APIの単体テスト", Dependencies: []int{0, 1}},
+		{Description: "ログインフローのE2Eテスト", Agent: "tests", Rationale: "画面を通した結合テスト", Dependencies: []int{2}},
+		{Description: "認証機能のドキュメント", Agent: "docs", Rationale: "使い方と設定の説明", Dependencies: []int{0, 1, 2}},
+	}},
+	{[]string{"ユーザー登録", "register", "signup", "sign-up"}, []Subtask{
+		{Description: "ユーザーモデルと登録APIの実装", Agent: "backend", Rationale: "データと登録処理"},
+		{Description: "入力検証とメール確認の実装", Agent: "backend", Rationale: "サーバー側の検証", Dependencies: []int{0}},
+		{Description: "登録フォームの実装", Agent: "frontend", Rationale: "UIの実装", Dependencies: []int{0}},
+		{Description: "登録フローのテスト", Agent: "tests", Rationale: "APIと画面のテスト", Dependencies: []int{1, 2}},
+		{Description: "登録機能のドキュメント", Agent: "docs", Rationale: "使い方の説明", Dependencies: []int{1, 2}},
+	}},
+	{[]string{"データベース", "database", "db", "スキーマ", "schema"}, []Subtask{
+		{Description: "スキーマ設計", Agent: "backend", Rationale: "テーブルと関連の定義"},
+		{Description: "マイグレーションの作成", Agent: "backend", Rationale: "スキーマの適用", Dependencies: []int{0}},
+		{Description: "モデル・リポジトリ層の実装", Agent: "backend", Rationale: "データアクセス", Dependencies: []int{1}},
+		{Description: "インデックスと性能の調整", Agent: "backend", Rationale: "クエリの最適化", Dependencies: []int{2}},
+		{Description: "データモデルのドキュメント", Agent: "docs", Rationale: "スキーマの説明", Dependencies: []int{0}},
+	}},
+	{[]string{"api", "エンドポイント", "endpoint"}, []Subtask{
+		{Description: "APIの設計", Agent: "backend", Rationale: "リクエストとレスポンスの定義"},
+		{Description: "エンドポイントの実装", Agent: "backend", Rationale: "サーバー側の処理", Dependencies: []int{0}},
+		{Description: "エラーハンドリングと入力検証", Agent: "backend", Rationale: "堅牢性", Dependencies: []int{1}},
+		{Description: "APIのテスト", Agent: "tests", Rationale: "エンドポイントの結合テスト", Dependencies: []int{2}},
+		{Description: "APIドキュメント", Agent: "docs", Rationale: "利用者向けの仕様", Dependencies: []int{0}},
+	}},
+	{[]string{"ui", "画面", "コンポーネント", "component"}, []Subtask{
+		{Description: "画面レイアウトの設計", Agent: "frontend", Rationale: "構成の決定"},
+		{Description: "コンポーネントの実装", Agent: "frontend", Rationale: "UIの実装", Dependencies: []int{0}},
+		{Description: "状態管理とデータ取得", Agent: "frontend", Rationale: "画面の振る舞い", Dependencies: []int{1}},
+		{Description: "スタイルとレスポンシブ対応", Agent: "frontend", Rationale: "見た目の調整", Dependencies: []int{1}},
+		{Description: "コンポーネントのテスト", Agent: "tests", Rationale: "UIのテスト", Dependencies: []int{2, 3}},
+	}},
+	{[]string{"テスト", "test", "tests"}, []Subtask{
+		{Agent: "tests", Rationale: "テスト作業"},
+	}},
+	{[]string{"ドキュメント", "document", "documentation", "readme", "docs"}, []Subtask{
+		{Agent: "docs", Rationale: "ドキュメント作業"},
+	}},
+}
+
+// agentKeywords pick the agent of a task no rule matched, like detect_agent
+var agentKeywords = []struct {
+	agent    string
+	keywords []string
+}{
+	{"tests", []string{"テスト", "スペック", "カバレッジ", "test", "spec", "coverage", "e2e"}},
+	{"docs", []string{"ドキュメント", "仕様書", "マニュアル", "ガイド", "readme", "docs", "guide"}},
+	{"frontend", []string{"ui", "画面", "コンポーネント", "スタイル", "フォーム", "css", "react", "vue", "form"}},
+	{"backend", []string{"api", "サーバー", "データベース", "認証", "ログイン", "モデル", "スキーマ", "server", "model"}},
+}
+
+var asciiWord = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// matchesKeyword reports whether keyword appears in the lower-cased text
+func matchesKeyword(text string, words map[string]bool, keyword string) bool {
+	if asciiWord.MatchString(keyword) {
+		return words[keyword]
+	}
+	return strings.Contains(text, keyword)
+}
+
+// RuleDecomposer splits tasks by keyword, like decompose_task_rules. It
+// needs no network and always proposes the same plan for a task.
+type RuleDecomposer struct {
+	Default string // agent of tasks no keyword matches
+}
+
+func (r *RuleDecomposer) Name() string { return DecomposerRules }
+
+func (r *RuleDecomposer) Decompose(req DecomposeRequest) (Decomposition, error) {
+	text := strings.ToLower(req.Task)
+	words := map[string]bool{}
+	for _, w := range strings.FieldsFunc(text, func(c rune) bool {
+		return !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-')
+	}) {
+		words[w] = true
+	}
+	has := func(keywords []string) bool {
+		for _, k := range keywords {
+			if matchesKeyword(text, words, k) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, rule := range decompRules {
+		if !has(rule.keywords) {
+			continue
+		}
+		d := Decomposition{Subtasks: make([]Subtask, len(rule.subtasks))}
+		for i, st := range rule.subtasks {
+			st.Dependencies = append([]int(nil), st.Dependencies...)
+			if st.Description == "" {
+				st.Description = req.Task
+			} else {
+				st.Description += ": " + req.Task
+			}
+			d.Subtasks[i] = st
+		}
+		return d, nil
+	}
+
+	agent := r.Default
+	if agent == "" {
+		agent = "backend"
+	}
+	for _, a := range agentKeywords {
+		if has(a.keywords) {
+			agent = a.agent
+			break
+		}
+	}
+	return Decomposition{Subtasks: []Subtask{{Description: req.Task, Agent: agent, Rationale: "キーワードから判定"}}}, nil
+}
+
+// ScriptedDecomposer replays canned answers: the Nth attempt gets the Nth
+// answer, and later attempts the last one. Answers are raw model output, so
+// they go through the same parsing as the Claude backend.
+type ScriptedDecomposer struct {
+	Answers []string
+
+	mu       sync.Mutex
+	Requests []DecomposeRequest // received so far
+}
+
+func (s *ScriptedDecomposer) Name() string { return DecomposerScript }
+
+func (s *ScriptedDecomposer) Decompose(req DecomposeRequest) (Decomposition, error) {
+	s.mu.Lock()
+	s.Requests = append(s.Requests, req)
+	s.mu.Unlock()
+	if len(s.Answers) == 0 {
+		return Decomposition{}, fmt.Errorf("script has no answers")
+	}
+	i := clampIndex(req.Attempt-1, len(s.Answers))
+	return ParseDecomposition(s.Answers[i])
+}
+
+func clampIndex(i, n int) int {
+	return max(0, min(i, n-1))
+}
+
+// LoadScriptedDecomposer reads answers from a file holding one decomposition
+// object, or an array of them for successive attempts
+func LoadScriptedDecomposer(path string) (*ScriptedDecomposer, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read decomposition script: %w", err)
+	}
+	var answers []json.RawMessage
+	if json.Unmarshal(b, &answers) != nil {
+		answers = []json.RawMessage{b}
+	}
+	s := &ScriptedDecomposer{}
+	for _, a := range answers {
+		s.Answers = append(s.Answers, string(a))
+	}
+	return s, nil
+}
+
+// DecomposerConfig is .claude/decomposer.json:
+//
+//	{
+//	  "backend": "claude",
+//	  "fallback": "rules",
+//	  "timeout": "2m",
+//	  "script": "decompose-script.json"
+//	}
+//
+// Fallback is used when the backend fails or proposes an invalid plan
+// ("none" disables it). Script is relative to .claude/.
+type DecomposerConfig struct {
+	Backend  string   `json:"backend"`
+	Fallback string   `json:"fallback"`
+	Timeout  Duration `json:"timeout"`
+	Script   string   `json:"script"`
+}
+
+// DefaultDecomposerConfig asks Claude and falls back to the rules. USE_AI=false
+// (as for the bash orchestrator) makes the rules the backend.
+func DefaultDecomposerConfig() DecomposerConfig {
+	cfg := DecomposerConfig{Backend: DecomposerClaude, Fallback: DecomposerRules}
+	if os.Getenv("USE_AI") == "false" {
+		cfg.Backend = DecomposerRules
+	}
+	if secs, err := strconv.Atoi(os.Getenv("CLAUDE_TIMEOUT")); err == nil && secs > 0 {
+		cfg.Timeout = Duration(time.Duration(secs) * time.Second)
+	}
+	return cfg
+}
+
+// DecomposerConfigPath returns .claude/decomposer.json
+func (p *Project) DecomposerConfigPath() string {
+	return p.Path("decomposer.json")
+}
+
+// LoadDecomposerConfig reads decomposer.json; fields left out keep their
+// defaults and a missing file is the default config
+func (p *Project) LoadDecomposerConfig() (DecomposerConfig, error) {
+	cfg := DefaultDecomposerConfig()
+	b, err := os.ReadFile(p.DecomposerConfigPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("failed to read decomposer.json: %w", err)
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return DefaultDecomposerConfig(), fmt.Errorf("failed to parse decomposer.json: %w", err)
+	}
+	if cfg.Backend == "" {
+		cfg.Backend = DecomposerClaude
+	}
+	for _, name := range []string{cfg.Backend, cfg.Fallback} {
+		switch name {
+		case DecomposerClaude, DecomposerRules, DecomposerScript:
+		case "", "none":
+			if name == cfg.Fallback {
+				continue
+			}
+			fallthrough
+		default:
+			return DefaultDecomposerConfig(), fmt.Errorf("decomposer.json: unknown backend %q", name)
+		}
+	}
+	return cfg, nil
+}
+
+// NewDecomposer builds the named backend ("" or "none" gives nil)
+func (p *Project) NewDecomposer(name string, cfg DecomposerConfig, c *AgentCatalog) (Decomposer, error) {
+	switch name {
+	case DecomposerClaude:
+		return &ClaudeDecomposer{Project: p, Agents: c.Names(), Timeout: time.Duration(cfg.Timeout)}, nil
+	case DecomposerRules:
+		return &RuleDecomposer{}, nil
+	case DecomposerScript:
+		path := cfg.Script
+		if path == "" {
+			return nil, fmt.Errorf("decomposer.json: the script backend needs \"script\"")
+		}
+		if !filepath.IsAbs(path) {
+			path = p.Path(path)
+		}
+		return LoadScriptedDecomposer(path)
+	}
+	return nil, nil
+}
+
+// DecomposeTask proposes a plan with the configured backend, then with its
+// fallback. It returns the name of the backend that answered ("" if none
+// did) and why the backends before it, or the config, failed.
+func (p *Project) DecomposeTask(req DecomposeRequest) (Decomposition, string, error) {
+	var errs []error
+	cfg, err := p.LoadDecomposerConfig()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	catalog, _ := p.LoadAgentCatalog()
+	for i, name := range []string{cfg.Backend, cfg.Fallback} {
+		if name == "" || name == "none" || i > 0 && name == cfg.Backend {
+			continue
+		}
+		dec, err := p.NewDecomposer(name, cfg, catalog)
+		if err == nil {
+			var d Decomposition
+			if d, err = Decompose(dec, req, catalog); err == nil {
+				return d, dec.Name(), errors.Join(errs...)
+			}
+		}
+		errs = append(errs, err)
+	}
+	return Decomposition{}, "", errors.Join(errs...)
+}
+
+// DecompositionMsg carries a proposed plan for a task. Err says why the
+// configured backend was not used; when no backend answered, Plan holds
+// the task as a single subtask and Source is "single".
+type DecompositionMsg struct {
+	Task    string
+	Attempt int // Attempt of the request, so stale answers can be told apart
+	Plan    Decomposition
+	Source  string // backend that proposed the plan
+	Err     error
+}
+
+// DecomposeCmd proposes subtasks for a task
+func DecomposeCmd(req DecomposeRequest) tea.Cmd {
+	return func() tea.Msg {
+		p, err := CurrentProject()
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		plan, source, err := p.DecomposeTask(req)
+		if source == "" {
+			plan = Decomposition{Subtasks: []Subtask{{Description: req.Task, Rationale: "proposed as is"}}}
+			source = "single"
+		}
+		return DecompositionMsg{Task: req.Task, Attempt: req.Attempt, Plan: plan, Source: source, Err: err}
+	}
+}
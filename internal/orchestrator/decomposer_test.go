@@ -0,0 +1,103 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRuleDecomposer(t *testing.T) {
+	c := DefaultAgentCatalog()
+	for task, want := range map[string][]string{
+		"ユーザー認証機能の実装":         {"backend", "backend", "frontend", "tests", "tests", "docs"},
+		"Add signup page":     {"backend", "backend", "frontend", "tests", "docs"},
+		"Tune the DB indexes": {"backend", "backend", "backend", "backend", "docs"},
+		"READMEの更新":           {"docs"},
+		"give feedback form":  {"frontend"}, // "db" inside a word does not count
+		"refactor internals":  {"backend"},
+	} {
+		d, err := Decompose(&RuleDecomposer{}, DecomposeRequest{Task: task}, c)
+		if err != nil {
+			t.Fatalf("%s: %v", task, err)
+		}
+		var agents []string
+		for _, s := range d.Subtasks {
+			agents = append(agents, s.Agent)
+			if !strings.Contains(s.Description, task) {
+				t.Errorf("%s: expected the task in %q", task, s.Description)
+			}
+		}
+		if strings.Join(agents, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: expected agents %v, got %v", task, want, agents)
+		}
+	}
+}
+
+func TestScriptedDecomposer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.json")
+	script := `[
+		{"subtasks": [{"description": "first", "agent": "backend", "rationale": "r"}]},
+		{"subtasks": [{"description": "second", "agent": "nobody", "rationale": "r"}]}
+	]`
+	if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadScriptedDecomposer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := DefaultAgentCatalog()
+	d, err := Decompose(s, DecomposeRequest{Task: "x", Attempt: 1}, c)
+	if err != nil || d.Subtasks[0].Description != "first" {
+		t.Fatalf("unexpected first answer: %+v %v", d, err)
+	}
+	// the second answer breaks the schema, and so do later attempts
+	for _, attempt := range []int{2, 5} {
+		if _, err := Decompose(s, DecomposeRequest{Task: "x", Feedback: "more", Attempt: attempt}, c); !errors.Is(err, ErrInvalidDecomposition) {
+			t.Errorf("attempt %d: expected an invalid agent, got %v", attempt, err)
+		}
+	}
+	if len(s.Requests) != 3 || s.Requests[1].Feedback != "more" {
+		t.Errorf("unexpected requests: %+v", s.Requests)
+	}
+}
+
+func TestDecomposeTaskConfig(t *testing.T) {
+	p := &Project{Root: t.TempDir()}
+	if err := os.MkdirAll(p.ClaudeDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, body string) {
+		t.Helper()
+		if err := os.WriteFile(p.Path(name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("plan.json", `{"subtasks": [{"description": "scripted", "agent": "docs", "rationale": "r"}]}`)
+	write("decomposer.json", `{"backend": "script", "script": "plan.json", "fallback": "none"}`)
+	d, source, err := p.DecomposeTask(DecomposeRequest{Task: "x", Attempt: 1})
+	if err != nil || source != DecomposerScript || d.Subtasks[0].Description != "scripted" {
+		t.Fatalf("expected the scripted plan, got %+v from %q (%v)", d, source, err)
+	}
+
+	// a broken backend falls back to the rules, reporting why
+	write("plan.json", `not json`)
+	write("decomposer.json", `{"backend": "script", "script": "plan.json"}`)
+	d, source, err = p.DecomposeTask(DecomposeRequest{Task: "write docs", Attempt: 1})
+	if source != DecomposerRules || len(d.Subtasks) != 1 || !errors.Is(err, ErrInvalidDecomposition) {
+		t.Errorf("expected the rule fallback, got %+v from %q (%v)", d, source, err)
+	}
+
+	write("decomposer.json", `{"backend": "script", "script": "plan.json", "fallback": "none"}`)
+	if _, source, err = p.DecomposeTask(DecomposeRequest{Task: "x"}); source != "" || err == nil {
+		t.Errorf("expected no plan without a fallback, got %q (%v)", source, err)
+	}
+
+	write("decomposer.json", `{"backend": "magic"}`)
+	if _, err := p.LoadDecomposerConfig(); err == nil || !strings.Contains(err.Error(), "magic") {
+		t.Errorf("expected an unknown backend to be rejected, got %v", err)
+	}
+}
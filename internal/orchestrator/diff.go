@@ -0,0 +1,310 @@
+package orchestrator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxUntrackedDiffs bounds the new files shown in a working tree diff
+const maxUntrackedDiffs = 100
+
+// Kinds of DiffLine
+const (
+	DiffContext = ' '
+	DiffAdded   = '+'
+	DiffRemoved = '-'
+	DiffNote    = '\\' // "\ No newline at end of file"
+)
+
+// DiffLine is one line of a hunk with its line numbers (0 when the line
+// does not exist on that side)
+type DiffLine struct {
+	Kind byte
+	Text string
+	Old  int
+	New  int
+}
+
+// Hunk is a block of changes starting with an @@ header
+type Hunk struct {
+	Header string // "@@ -1,3 +1,4 @@ func main", or "" for bare payloads
+	Lines  []DiffLine
+}
+
+// FileDiff is the change of one file
+type FileDiff struct {
+	Path    string
+	OldPath string // set when the file was renamed
+	Status  string // modified, added, deleted, renamed or binary
+	Hunks   []Hunk
+}
+
+// Stats counts the added and removed lines
+func (f FileDiff) Stats() (added, removed int) {
+	for _, h := range f.Hunks {
+		for _, l := range h.Lines {
+			switch l.Kind {
+			case DiffAdded:
+				added++
+			case DiffRemoved:
+				removed++
+			}
+		}
+	}
+	return added, removed
+}
+
+// ParseUnifiedDiff splits the output of git diff (or any unified diff) into
+// files and hunks
+func ParseUnifiedDiff(text string) []FileDiff {
+	var files []FileDiff
+	var f *FileDiff
+	var h *Hunk
+	oldLine, newLine := 0, 0
+
+	start := func(path string) {
+		files = append(files, FileDiff{Path: path, Status: "modified"})
+		f, h = &files[len(files)-1], nil
+	}
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			a, b := gitDiffPaths(strings.TrimPrefix(line, "diff --git "))
+			start(b)
+			if a != b {
+				f.OldPath, f.Status = a, "renamed"
+			}
+			continue
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ") &&
+			(h == nil || i+2 < len(lines) && strings.HasPrefix(lines[i+2], "@@")):
+			// a file header, not a removed line starting with "-- "
+			if f == nil || len(f.Hunks) > 0 {
+				start("")
+			}
+			if old := diffPath(line[4:]); old == "" {
+				f.Status = "added"
+			} else if f.Path == "" {
+				f.Path = old
+			}
+			continue
+		case h == nil && f != nil && strings.HasPrefix(line, "+++ "):
+			if p := diffPath(line[4:]); p == "" {
+				f.Status = "deleted"
+			} else {
+				f.Path = p
+			}
+			continue
+		case strings.HasPrefix(line, "@@"):
+			if f == nil {
+				start("")
+			}
+			oldLine, newLine = hunkStart(line)
+			f.Hunks = append(f.Hunks, Hunk{Header: line})
+			h = &f.Hunks[len(f.Hunks)-1]
+			continue
+		}
+
+		if h == nil {
+			// git extended headers between "diff --git" and the first hunk
+			if f != nil {
+				switch {
+				case strings.HasPrefix(line, "new file mode"):
+					f.Status = "added"
+				case strings.HasPrefix(line, "deleted file mode"):
+					f.Status = "deleted"
+				case strings.HasPrefix(line, "Binary files"):
+					f.Status = "binary"
+				}
+			}
+			continue
+		}
+		h.Lines = append(h.Lines, numberLine(line, &oldLine, &newLine))
+	}
+	return files
+}
+
+// ApprovalDiffs parses the diffs attached to an approval request. Payloads
+// without @@ headers become a single hunk.
+func ApprovalDiffs(files []ApprovalFile) []FileDiff {
+	var out []FileDiff
+	for _, af := range files {
+		parsed := ParseUnifiedDiff(af.Diff)
+		if len(parsed) == 1 && len(parsed[0].Hunks) > 0 {
+			parsed[0].Path = af.Path
+			out = append(out, parsed[0])
+			continue
+		}
+		f := FileDiff{Path: af.Path, Status: "modified"}
+		if strings.TrimSpace(af.Diff) != "" {
+			oldLine, newLine := 1, 1
+			h := Hunk{}
+			for _, line := range strings.Split(strings.TrimRight(af.Diff, "\n"), "\n") {
+				h.Lines = append(h.Lines, numberLine(line, &oldLine, &newLine))
+			}
+			f.Hunks = []Hunk{h}
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// numberLine classifies a hunk line and advances the line counters
+func numberLine(line string, oldLine, newLine *int) DiffLine {
+	l := DiffLine{Kind: DiffContext, Text: line}
+	if line != "" {
+		switch line[0] {
+		case '+', '-', ' ', '\\':
+			l.Kind, l.Text = line[0], line[1:]
+		}
+	}
+	switch l.Kind {
+	case DiffAdded:
+		l.New = *newLine
+		*newLine++
+	case DiffRemoved:
+		l.Old = *oldLine
+		*oldLine++
+	case DiffContext:
+		l.Old, l.New = *oldLine, *newLine
+		*oldLine++
+		*newLine++
+	}
+	return l
+}
+
+// hunkStart reads the first old and new line numbers of "@@ -a,b +c,d @@"
+func hunkStart(header string) (oldLine, newLine int) {
+	fields := strings.Fields(header)
+	for _, f := range fields[1:] {
+		n, _ := strconv.Atoi(strings.SplitN(f[1:], ",", 2)[0])
+		switch f[0] {
+		case '-':
+			oldLine = n
+		case '+':
+			newLine = n
+			return oldLine, newLine
+		}
+	}
+	return oldLine, newLine
+}
+
+// gitDiffPaths splits "a/old b/new" of a diff --git line
+func gitDiffPaths(s string) (a, b string) {
+	if i := strings.Index(s, " b/"); i >= 0 {
+		return strings.TrimPrefix(s[:i], "a/"), s[i+3:]
+	}
+	return s, s
+}
+
+// diffPath strips the a/ b/ prefix and timestamps of a ---/+++ line;
+// /dev/null is ""
+func diffPath(s string) string {
+	if i := strings.IndexByte(s, '\t'); i >= 0 {
+		s = s[:i]
+	}
+	if s == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(s, "a/") || strings.HasPrefix(s, "b/") {
+		return s[2:]
+	}
+	return s
+}
+
+// ErrNotGitRepo is returned when the project is not a git working tree
+var ErrNotGitRepo = errors.New("not a git repository")
+
+// git runs git in the project root and returns its standard output
+func (p *Project) git(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", p.Root}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(stderr.String(), "not a git repository") {
+			return "", ErrNotGitRepo
+		}
+		return string(out), fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// HeadCommit returns the commit checked out in the project
+func (p *Project) HeadCommit() (string, error) {
+	out, err := p.git("rev-parse", "HEAD")
+	return strings.TrimSpace(out), err
+}
+
+// WorkingTreeDiff diffs the working tree, staged or not, against base,
+// including files git does not track yet
+func (p *Project) WorkingTreeDiff(base string) ([]FileDiff, error) {
+	out, err := p.git("diff", "--no-color", "--no-ext-diff", "-M", base, "--")
+	if err != nil {
+		return nil, err
+	}
+	files := ParseUnifiedDiff(out)
+
+	untracked, err := p.git("ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range strings.Split(strings.TrimRight(untracked, "\x00"), "\x00") {
+		if name == "" {
+			continue
+		}
+		if i == maxUntrackedDiffs {
+			break
+		}
+		// --no-index exits 1 when the files differ, which they always do here
+		out, _ := p.git("diff", "--no-color", "--no-index", "--", "/dev/null", name)
+		if parsed := ParseUnifiedDiff(out); len(parsed) > 0 {
+			parsed[0].Path, parsed[0].OldPath, parsed[0].Status = name, "", "added"
+			files = append(files, parsed[0])
+		}
+	}
+	return files, nil
+}
+
+// TaskDiff diffs the working tree against the commit the task started
+// from, or against HEAD when it was not recorded. It returns the base used.
+func (p *Project) TaskDiff(t Task) (string, []FileDiff, error) {
+	base := t.BaseCommit
+	if base == "" {
+		base = "HEAD"
+	}
+	files, err := p.WorkingTreeDiff(base)
+	return base, files, err
+}
+
+// DiffLoadMsg carries a diff to review
+type DiffLoadMsg struct {
+	Title string
+	Files []FileDiff
+	Err   error
+}
+
+// TaskDiffCmd diffs the working tree for a task
+func TaskDiffCmd(id int) tea.Cmd {
+	return func() tea.Msg {
+		p, err := CurrentProject()
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		t, err := p.Store().Get(id)
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		base, files, err := p.TaskDiff(t)
+		if len(base) > 12 {
+			base = base[:12]
+		}
+		return DiffLoadMsg{Title: fmt.Sprintf("task #%d vs %s", id, base), Files: files, Err: err}
+	}
+}
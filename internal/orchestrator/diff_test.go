@@ -0,0 +1,146 @@
+package orchestrator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+const sampleGitDiff = `diff --git a/api/login.go b/api/login.go
+index 83db48f..bf269f4 100644
+--- a/api/login.go
++++ b/api/login.go
+@@ -10,4 +10,5 @@ func Login() {
+ 	user := lookup()
+-	return nil
++	if user == nil {
++		return ErrNoUser
++	}
+ }
+@@ -40,2 +41,2 @@ func Logout() {
+--- old comment
++++ new comment
+diff --git a/old.txt b/new.txt
+similarity index 90%
+rename from old.txt
+rename to new.txt
+diff --git a/docs/NEW.md b/docs/NEW.md
+new file mode 100644
+index 0000000..e69de29
+--- /dev/null
++++ b/docs/NEW.md
+@@ -0,0 +1 @@
++# New
+\ No newline at end of file
+`
+
+func TestParseUnifiedDiff(t *testing.T) {
+	files := ParseUnifiedDiff(sampleGitDiff)
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %+v", files)
+	}
+
+	f := files[0]
+	if f.Path != "api/login.go" || f.Status != "modified" || len(f.Hunks) != 2 {
+		t.Fatalf("unexpected first file: %+v", f)
+	}
+	if added, removed := f.Stats(); added != 4 || removed != 2 {
+		t.Errorf("expected +4 -2, got +%d -%d", added, removed)
+	}
+	lines := f.Hunks[0].Lines
+	if l := lines[0]; l.Kind != DiffContext || l.Old != 10 || l.New != 10 {
+		t.Errorf("unexpected context line: %+v", l)
+	}
+	if l := lines[1]; l.Kind != DiffRemoved || l.Old != 11 || l.New != 0 {
+		t.Errorf("unexpected removed line: %+v", l)
+	}
+	if l := lines[4]; l.Kind != DiffAdded || l.New != 13 || l.Text != "\t}" {
+		t.Errorf("unexpected added line: %+v", l)
+	}
+	// "--- old comment" inside a hunk is a removed line, not a file header
+	if l := f.Hunks[1].Lines[0]; l.Kind != DiffRemoved || l.Text != "-- old comment" || l.Old != 40 {
+		t.Errorf("unexpected line in second hunk: %+v", l)
+	}
+
+	if f := files[1]; f.Path != "new.txt" || f.OldPath != "old.txt" || f.Status != "renamed" {
+		t.Errorf("unexpected rename: %+v", f)
+	}
+	if f := files[2]; f.Path != "docs/NEW.md" || f.Status != "added" || f.Hunks[0].Lines[1].Kind != DiffNote {
+		t.Errorf("unexpected new file: %+v", f)
+	}
+}
+
+func TestApprovalDiffs(t *testing.T) {
+	files := ApprovalDiffs([]ApprovalFile{
+		{Path: "src/app.ts", Diff: "@@ -1 +1 @@\n-a\n+b\n"},
+		{Path: "src/new.ts", Diff: "+export const x = 1\n+export const y = 2"},
+		{Path: "README.md"},
+	})
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %+v", files)
+	}
+	if files[0].Path != "src/app.ts" || len(files[0].Hunks) != 1 || files[0].Hunks[0].Header != "@@ -1 +1 @@" {
+		t.Errorf("unexpected unified payload: %+v", files[0])
+	}
+	if h := files[1].Hunks; len(h) != 1 || h[0].Header != "" || h[0].Lines[1].New != 2 {
+		t.Errorf("expected a bare payload as one hunk, got %+v", h)
+	}
+	if len(files[2].Hunks) != 0 {
+		t.Errorf("expected no hunks without a diff, got %+v", files[2])
+	}
+}
+
+func TestWorkingTreeDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	p := &Project{Root: t.TempDir()}
+	if _, err := p.HeadCommit(); err != ErrNotGitRepo {
+		t.Fatalf("expected ErrNotGitRepo outside a repository, got %v", err)
+	}
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", p.Root, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(p.Root, name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("init", "-q")
+	write("main.go", "package main\n")
+	run("add", ".")
+	run("commit", "-qm", "init")
+	base, err := p.HeadCommit()
+	if err != nil || len(base) != 40 {
+		t.Fatalf("HeadCommit: %q %v", base, err)
+	}
+
+	// a committed change, an unstaged change and a new file all count
+	write("main.go", "package main\n\nfunc main() {}\n")
+	run("commit", "-qam", "agent work")
+	write("main.go", "package main\n\nfunc main() { run() }\n")
+	write("run.go", "package main\n")
+
+	_, files, err := p.TaskDiff(Task{BaseCommit: base})
+	if err != nil {
+		t.Fatalf("TaskDiff: %v", err)
+	}
+	if len(files) != 2 || files[0].Path != "main.go" || files[1].Path != "run.go" || files[1].Status != "added" {
+		t.Fatalf("unexpected files: %+v", files)
+	}
+	if added, removed := files[0].Stats(); added != 2 || removed != 0 {
+		t.Errorf("expected +2 -0 against the base commit, got +%d -%d", added, removed)
+	}
+
+	_, files, err = p.TaskDiff(Task{})
+	if err != nil || len(files) != 2 {
+		t.Errorf("expected the diff against HEAD without a base commit, got %+v (%v)", files, err)
+	}
+}
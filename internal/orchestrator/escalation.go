@@ -0,0 +1,366 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// What happens to the task of a request nobody answered in time
+const (
+	ExpiryRequeue = "requeue" // back to pending for another run
+	ExpiryFail    = "fail"    // marked failed
+)
+
+// Kinds of ApprovalEvent
+const (
+	EscalationExpiring     = "expiring"      // deadline is within WarnBefore
+	EscalationExpired      = "expired"       // deadline passed, the task was settled by policy
+	EscalationAutoApproved = "auto_approved" // low-risk operation approved by policy
+)
+
+const (
+	defaultApprovalExpiry = 24 * time.Hour
+	defaultApprovalWarn   = time.Hour
+	// policyResponder answers the requests decided by the policy
+	policyResponder = "policy"
+)
+
+// Duration is a time.Duration written as "24h" / "90m", or as seconds
+type Duration time.Duration
+
+// UnmarshalJSON accepts a duration string or a number of seconds
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		*d = Duration(v)
+		return nil
+	}
+	var secs float64
+	if err := json.Unmarshal(b, &secs); err != nil {
+		return fmt.Errorf("invalid duration %s", b)
+	}
+	*d = Duration(secs * float64(time.Second))
+	return nil
+}
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// ApprovalPolicy is .claude/approval-policy.json:
+//
+//	{
+//	  "expiry": "24h",
+//	  "on_expiry": "requeue",
+//	  "warn_before": "1h",
+//	  "auto_approve": ["file_read", "test_run"],
+//	  "require_approval_paths": [".env*", "migrations/**"]
+//	}
+//
+// Requests without expires_at get one at creation + Expiry. Operation types
+// in AutoApprove are approved right away unless they touch a path matching
+// RequireApprovalPaths, which always waits for a person.
+type ApprovalPolicy struct {
+	Expiry               Duration `json:"expiry"`
+	OnExpiry             string   `json:"on_expiry"` // requeue or fail
+	WarnBefore           Duration `json:"warn_before"`
+	AutoApprove          []string `json:"auto_approve"`
+	RequireApprovalPaths []string `json:"require_approval_paths"`
+}
+
+// DefaultApprovalPolicy expires requests after 24h and re-queues their task
+func DefaultApprovalPolicy() ApprovalPolicy {
+	return ApprovalPolicy{
+		Expiry:     Duration(defaultApprovalExpiry),
+		OnExpiry:   ExpiryRequeue,
+		WarnBefore: Duration(defaultApprovalWarn),
+	}
+}
+
+// ApprovalPolicyPath returns .claude/approval-policy.json
+func (p *Project) ApprovalPolicyPath() string {
+	return p.Path("approval-policy.json")
+}
+
+// LoadApprovalPolicy reads the approval policy; fields left out keep their
+// defaults and a missing file is the default policy
+func (p *Project) LoadApprovalPolicy() (ApprovalPolicy, error) {
+	pol := DefaultApprovalPolicy()
+	b, err := os.ReadFile(p.ApprovalPolicyPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return pol, nil
+		}
+		return pol, fmt.Errorf("failed to read approval-policy.json: %w", err)
+	}
+	if err := json.Unmarshal(b, &pol); err != nil {
+		return DefaultApprovalPolicy(), fmt.Errorf("failed to parse approval-policy.json: %w", err)
+	}
+	if pol.Expiry <= 0 {
+		pol.Expiry = Duration(defaultApprovalExpiry)
+	}
+	switch pol.OnExpiry {
+	case ExpiryRequeue, ExpiryFail:
+	case "":
+		pol.OnExpiry = ExpiryRequeue
+	default:
+		return DefaultApprovalPolicy(), fmt.Errorf("approval-policy.json: on_expiry must be %q or %q, got %q", ExpiryRequeue, ExpiryFail, pol.OnExpiry)
+	}
+	return pol, nil
+}
+
+// AutoApproves reports whether the policy approves a request without asking
+func (pol ApprovalPolicy) AutoApproves(a Approval) bool {
+	listed := false
+	for _, op := range pol.AutoApprove {
+		if op == a.OperationType {
+			listed = true
+		}
+	}
+	if !listed {
+		return false
+	}
+	for _, f := range a.Files {
+		if pol.RequiresApproval(f.Path) {
+			return false
+		}
+	}
+	return true
+}
+
+// RequiresApproval reports whether a path matches require_approval_paths.
+// "dir/**" matches everything under dir; patterns without a slash also
+// match the base name, so "*.env" covers config/prod.env.
+func (pol ApprovalPolicy) RequiresApproval(name string) bool {
+	name = strings.TrimPrefix(path.Clean(strings.ReplaceAll(name, "\\", "/")), "./")
+	for _, pattern := range pol.RequireApprovalPaths {
+		if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+			if name == dir || strings.HasPrefix(name, dir+"/") {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(name)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deadline is when a request without expires_at runs out: Expiry after it
+// was created, or after now when the creation time is unknown
+func (pol ApprovalPolicy) deadline(a Approval, now time.Time) time.Time {
+	created, err := time.Parse(time.RFC3339, a.CreatedAt)
+	if err != nil {
+		created = now
+	}
+	return created.Add(time.Duration(pol.Expiry)).UTC()
+}
+
+// verdict returns what the policy decides for a pending request, or "" to
+// keep waiting
+func (pol ApprovalPolicy) verdict(a Approval, now time.Time) (action, comment string) {
+	switch {
+	case pol.AutoApproves(a):
+		return ApprovalApproved, fmt.Sprintf("auto-approved: %s is a low-risk operation", a.OperationType)
+	case !a.Deadline().IsZero() && !now.Before(a.Deadline()):
+		return ApprovalExpired, fmt.Sprintf("no answer by %s", a.ExpiresAt)
+	}
+	return "", ""
+}
+
+// ApprovalEvent is something the policy did or is about to do
+type ApprovalEvent struct {
+	Kind       string
+	Approval   Approval
+	Remaining  time.Duration // time left, for EscalationExpiring
+	TaskStatus string        // status the task moved to, "" if it did not move
+}
+
+// Sweep applies the policy to every pending request: it gives requests
+// without expires_at a deadline, approves low-risk operations and expires
+// requests past their deadline, settling their tasks like an answer would
+func (s *ApprovalStore) Sweep(pol ApprovalPolicy) ([]ApprovalEvent, error) {
+	lock, err := LockFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	file, err := s.loadFile()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	stamp := now.Format(time.RFC3339)
+	fileChanged := false
+	var fromFile []Approval
+	for i, raw := range file {
+		a, err := decodeApprovalEntry(raw)
+		if err != nil || a.Status != ApprovalPending {
+			continue
+		}
+		if a.ExpiresAt == "" {
+			a.ExpiresAt = pol.deadline(a, now).Format(time.RFC3339)
+			setRaw(raw, "expires_at", a.ExpiresAt)
+			fileChanged = true
+		}
+		action, comment := pol.verdict(a, now)
+		if action == "" {
+			continue
+		}
+		resp := &ApprovalResponse{Action: action, RespondedAt: stamp, RespondedBy: policyResponder, Comment: comment}
+		setRaw(raw, "status", action)
+		setRaw(raw, "completed_at", stamp)
+		setRaw(raw, "response", resp)
+		file[i] = raw
+		fileChanged = true
+		a.Status, a.CompletedAt, a.Response = action, stamp, resp
+		fromFile = append(fromFile, a)
+	}
+
+	// approvals.json goes first so a failed tasks.json save can put it back,
+	// as in decide
+	var prev []byte
+	if fileChanged {
+		if prev, err = os.ReadFile(s.path); err != nil {
+			return nil, fmt.Errorf("failed to read approvals.json: %w", err)
+		}
+		if err := s.saveFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	var events []ApprovalEvent
+	err = s.tasks.Update(func(data *TasksData) error {
+		changed := false
+		decided := slices.Clone(fromFile)
+
+		for _, a := range taskApprovals(data) {
+			if a.Status != ApprovalPending {
+				continue
+			}
+			if a.ExpiresAt == "" {
+				a.ExpiresAt = pol.deadline(a, now).Format(time.RFC3339)
+				expires := a.ExpiresAt
+				updateTaskApproval(data, a.ID, func(r *ApprovalRequest) { r.ExpiresAt = expires },
+					map[string]any{"expires_at": expires})
+				changed = true
+			}
+			action, comment := pol.verdict(a, now)
+			if action == "" {
+				continue
+			}
+			resp := &ApprovalResponse{Action: action, RespondedAt: stamp, RespondedBy: policyResponder, Comment: comment}
+			answerTaskApproval(data, a.ID, action, resp)
+			changed = true
+			a.Status, a.CompletedAt, a.Response = action, stamp, resp
+			decided = append(decided, a)
+		}
+
+		for _, a := range decided {
+			ev := ApprovalEvent{Kind: EscalationExpired, Approval: a}
+			if a.Status == ApprovalApproved {
+				ev.Kind = EscalationAutoApproved
+			}
+			if settleTask(data, a, file, stamp, pol.OnExpiry) {
+				ev.TaskStatus = data.Find(a.TaskID).Status
+				changed = true
+			}
+			events = append(events, ev)
+		}
+		if !changed {
+			return errUnchanged
+		}
+		return nil
+	})
+	if err != nil && err != errUnchanged {
+		if fileChanged {
+			if werr := WriteFileAtomic(s.path, prev, 0644); werr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to restore approvals.json: %w", werr))
+			}
+		}
+		return nil, err
+	}
+	return events, nil
+}
+
+// ApprovalEngine enforces the approval policy of a project. Each Sweep
+// reloads approval-policy.json, applies it, and warns once per request
+// when its deadline comes within warn_before.
+type ApprovalEngine struct {
+	project *Project
+	now     func() time.Time
+
+	mu     sync.Mutex
+	warned map[string]bool
+}
+
+// NewApprovalEngine returns an engine for the requests of project
+func NewApprovalEngine(project *Project) *ApprovalEngine {
+	return &ApprovalEngine{project: project, now: time.Now, warned: make(map[string]bool)}
+}
+
+// Sweep applies the policy once and returns what happened. A broken policy
+// file is reported, and the default policy is applied meanwhile.
+func (e *ApprovalEngine) Sweep() ([]ApprovalEvent, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	pol, polErr := e.project.LoadApprovalPolicy()
+	store := e.project.Approvals()
+	store.now = e.now
+	events, err := store.Sweep(pol)
+	if err != nil {
+		return events, err
+	}
+	list, err := store.List()
+	if err != nil {
+		return events, err
+	}
+	now := e.now()
+	for _, a := range list {
+		deadline := a.Deadline()
+		if a.Status != ApprovalPending || deadline.IsZero() || e.warned[a.ID] {
+			continue
+		}
+		if left := deadline.Sub(now); left <= time.Duration(pol.WarnBefore) {
+			e.warned[a.ID] = true
+			events = append(events, ApprovalEvent{Kind: EscalationExpiring, Approval: a, Remaining: left})
+		}
+	}
+	return events, polErr
+}
+
+// ApprovalSweepMsg carries the result of an ApprovalEngine sweep
+type ApprovalSweepMsg struct {
+	Events []ApprovalEvent
+	Err    error
+}
+
+// ApprovalSweepCmd runs one sweep of the engine
+func ApprovalSweepCmd(e *ApprovalEngine) tea.Cmd {
+	return func() tea.Msg {
+		events, err := e.Sweep()
+		return ApprovalSweepMsg{Events: events, Err: err}
+	}
+}
@@ -0,0 +1,180 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadApprovalPolicy(t *testing.T) {
+	p := &Project{Root: t.TempDir()}
+	pol, err := p.LoadApprovalPolicy()
+	if err != nil || time.Duration(pol.Expiry) != 24*time.Hour || pol.OnExpiry != ExpiryRequeue {
+		t.Fatalf("expected the default policy without a file, got %+v (%v)", pol, err)
+	}
+
+	if err := os.MkdirAll(p.ClaudeDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(body string) {
+		if err := os.WriteFile(p.ApprovalPolicyPath(), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{"expiry": "2h", "on_expiry": "fail", "warn_before": 600, "auto_approve": ["file_read"]}`)
+	pol, err = p.LoadApprovalPolicy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Duration(pol.Expiry) != 2*time.Hour || time.Duration(pol.WarnBefore) != 10*time.Minute || pol.OnExpiry != ExpiryFail {
+		t.Errorf("unexpected policy: %+v", pol)
+	}
+
+	write(`{"on_expiry": "retry"}`)
+	if pol, err = p.LoadApprovalPolicy(); err == nil || pol.OnExpiry != ExpiryRequeue {
+		t.Errorf("expected an error and the default policy, got %+v (%v)", pol, err)
+	}
+}
+
+func TestRequiresApproval(t *testing.T) {
+	pol := ApprovalPolicy{
+		AutoApprove:          []string{"file_write"},
+		RequireApprovalPaths: []string{"*.env", "migrations/**", "src/auth/*.go"},
+	}
+	for name, want := range map[string]bool{
+		"config/prod.env":      true,
+		"./migrations/001.sql": true,
+		"migrations":           true,
+		"src/auth/login.go":    true,
+		"src/auth/oauth/x.go":  false,
+		"src/app.ts":           false,
+	} {
+		if got := pol.RequiresApproval(name); got != want {
+			t.Errorf("RequiresApproval(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	write := Approval{OperationType: "file_write", Files: []ApprovalFile{{Path: "src/app.ts"}}}
+	if !pol.AutoApproves(write) {
+		t.Error("expected a low-risk write to be auto-approved")
+	}
+	write.Files = append(write.Files, ApprovalFile{Path: ".env"})
+	if pol.AutoApproves(write) {
+		t.Error("expected a write touching .env to wait for approval")
+	}
+	if pol.AutoApproves(Approval{OperationType: "command_exec"}) {
+		t.Error("expected unlisted operations to wait for approval")
+	}
+}
+
+func TestApprovalSweep(t *testing.T) {
+	// now is 2026-01-02T12:00:00Z
+	s := newTestApprovals(t, `{"tasks": [
+		{"id": 1, "description": "Login API", "status": "pending_approval", "agent": "backend"},
+		{"id": 2, "description": "Signup form", "status": "pending_approval", "agent": "frontend"},
+		{"id": 3, "description": "Lint", "status": "pending_approval", "agent": "tests",
+		 "approval_requests": [
+			{"id": "req-1", "operation_type": "file_read", "requested_at": "2026-01-02T11:00:00Z", "status": "pending", "response": null}
+		 ]},
+		{"id": 4, "description": "Deploy", "status": "pending_approval", "agent": "devops",
+		 "approval_requests": [
+			{"id": "req-2", "operation_type": "command_exec", "requested_at": "2026-01-01T10:00:00Z", "status": "pending", "response": null}
+		 ]}
+	], "last_id": 4}`, `{"approvals": [
+		{"id": 1, "task_id": 1, "status": "pending", "created_at": "2026-01-01T11:00:00Z"},
+		{"id": 2, "task_id": 2, "status": "pending", "created_at": "2026-01-02T11:30:00Z"}
+	]}`)
+
+	pol := DefaultApprovalPolicy()
+	pol.AutoApprove = []string{"file_read"}
+	events, err := s.Sweep(pol)
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	got := map[string]string{}
+	for _, ev := range events {
+		got[ev.Approval.ID] = ev.Kind + ":" + ev.TaskStatus
+	}
+	want := map[string]string{
+		"1":     EscalationExpired + ":" + StatusPending,
+		"req-1": EscalationAutoApproved + ":" + StatusInProgress,
+		"req-2": EscalationExpired + ":" + StatusPending,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+	for id, w := range want {
+		if got[id] != w {
+			t.Errorf("request %s: expected %s, got %s", id, w, got[id])
+		}
+	}
+
+	a, err := s.Get("2")
+	if err != nil || a.Status != ApprovalPending || a.ExpiresAt != "2026-01-03T11:30:00Z" {
+		t.Errorf("expected request 2 to wait with a 24h deadline, got %+v (%v)", a, err)
+	}
+	if a, _ := s.Get("1"); a.Status != ApprovalExpired || a.Response.RespondedBy != policyResponder {
+		t.Errorf("expected request 1 expired by policy, got %+v", a)
+	}
+	b, _ := os.ReadFile(s.tasks.Path())
+	if !strings.Contains(string(b), `"expires_at": "2026-01-02T10:00:00Z"`) {
+		t.Errorf("expected the deadline of req-2 in tasks.json:\n%s", b)
+	}
+
+	// a second sweep has nothing left to do
+	if events, err := s.Sweep(pol); err != nil || len(events) != 0 {
+		t.Errorf("expected no events on the second sweep, got %v (%v)", events, err)
+	}
+
+	// with on_expiry fail, the expired task fails instead
+	s.now = func() time.Time { return time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC) }
+	pol.OnExpiry = ExpiryFail
+	events, err = s.Sweep(pol)
+	if err != nil || len(events) != 1 || events[0].TaskStatus != StatusFailed {
+		t.Fatalf("expected request 2 to fail its task, got %+v (%v)", events, err)
+	}
+}
+
+func TestSweepRollsBackOnFailedSave(t *testing.T) {
+	// a schema newer than this build refuses to be saved
+	s := newTestApprovals(t, `{"schema_version": 99, "tasks": [
+		{"id": 1, "description": "Login API", "status": "pending_approval", "agent": "backend"}
+	], "last_id": 1}`, `{"approvals": [
+		{"id": 1, "task_id": 1, "status": "pending", "created_at": "2026-01-01T11:00:00Z"}
+	]}`)
+
+	if _, err := s.Sweep(DefaultApprovalPolicy()); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("expected ErrSchemaTooNew, got %v", err)
+	}
+	a, err := s.Get("1")
+	if err != nil || a.Status != ApprovalPending || a.ExpiresAt != "" {
+		t.Errorf("a failed tasks.json save should leave approvals.json as it was, got %+v (%v)", a, err)
+	}
+}
+
+func TestApprovalEngineWarnsOnce(t *testing.T) {
+	p := &Project{Root: t.TempDir()}
+	if err := os.MkdirAll(p.ClaudeDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p.TasksPath(), []byte(`{"tasks": [{"id": 1, "status": "pending_approval"}], "last_id": 1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p.ApprovalsPath(), []byte(`{"approvals": [
+		{"id": 1, "task_id": 1, "status": "pending", "expires_at": "2026-01-02T12:30:00Z"}
+	]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewApprovalEngine(p)
+	e.now = func() time.Time { return time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC) }
+	events, err := e.Sweep()
+	if err != nil || len(events) != 1 || events[0].Kind != EscalationExpiring || events[0].Remaining != 30*time.Minute {
+		t.Fatalf("expected one expiring warning, got %+v (%v)", events, err)
+	}
+	if events, _ := e.Sweep(); len(events) != 0 {
+		t.Errorf("expected the warning only once, got %+v", events)
+	}
+}
@@ -0,0 +1,245 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrBlocked is returned when starting a task whose dependencies are not completed
+	ErrBlocked = errors.New("task is blocked by unfinished dependencies")
+	// ErrDependencyCycle is returned when dependencies form a cycle
+	ErrDependencyCycle = errors.New("dependency cycle")
+)
+
+// Graph is the dependency graph between tasks. An edge runs from a task to
+// each task it depends on. Dependencies on IDs that no longer exist are
+// ignored, so removing a prerequisite never blocks its dependents forever.
+type Graph struct {
+	order      []int // task IDs in file order, used to keep results stable
+	tasks      map[int]Task
+	deps       map[int][]int
+	dependents map[int][]int
+}
+
+// NewGraph builds the dependency graph for tasks
+func NewGraph(tasks []Task) *Graph {
+	g := &Graph{
+		tasks:      make(map[int]Task, len(tasks)),
+		deps:       make(map[int][]int),
+		dependents: make(map[int][]int),
+	}
+	for _, t := range tasks {
+		g.order = append(g.order, t.ID)
+		g.tasks[t.ID] = t
+	}
+	for _, t := range tasks {
+		seen := make(map[int]bool)
+		for _, dep := range t.Dependencies {
+			if _, ok := g.tasks[dep]; !ok || seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			g.deps[t.ID] = append(g.deps[t.ID], dep)
+			g.dependents[dep] = append(g.dependents[dep], t.ID)
+		}
+	}
+	return g
+}
+
+// Task returns the task with the given ID
+func (g *Graph) Task(id int) (Task, bool) {
+	t, ok := g.tasks[id]
+	return t, ok
+}
+
+// IDs returns every task ID in file order
+func (g *Graph) IDs() []int {
+	return g.order
+}
+
+// Dependencies returns the existing tasks id depends on
+func (g *Graph) Dependencies(id int) []int {
+	return g.deps[id]
+}
+
+// Dependents returns the tasks that depend on id
+func (g *Graph) Dependents(id int) []int {
+	return g.dependents[id]
+}
+
+// Blockers returns the dependencies of id that are not completed yet
+func (g *Graph) Blockers(id int) []int {
+	var blockers []int
+	for _, dep := range g.deps[id] {
+		if g.tasks[dep].Status != StatusCompleted {
+			blockers = append(blockers, dep)
+		}
+	}
+	return blockers
+}
+
+// IsBlocked reports whether id has unfinished dependencies
+func (g *Graph) IsBlocked(id int) bool {
+	return len(g.Blockers(id)) > 0
+}
+
+// TopoOrder returns task IDs so that every task comes after its
+// dependencies. Ties keep file order. It fails with ErrDependencyCycle.
+func (g *Graph) TopoOrder() ([]int, error) {
+	indegree := make(map[int]int, len(g.order))
+	for _, id := range g.order {
+		indegree[id] = len(g.deps[id])
+	}
+	position := make(map[int]int, len(g.order))
+	for i, id := range g.order {
+		position[id] = i
+	}
+
+	var ready []int
+	for _, id := range g.order {
+		if indegree[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+	result := make([]int, 0, len(g.order))
+	for len(ready) > 0 {
+		id := ready[0]
+		ready = ready[1:]
+		result = append(result, id)
+		for _, next := range g.dependents[id] {
+			indegree[next]--
+			if indegree[next] == 0 {
+				ready = append(ready, next)
+				sort.Slice(ready, func(i, j int) bool { return position[ready[i]] < position[ready[j]] })
+			}
+		}
+	}
+
+	if len(result) != len(g.order) {
+		return result, fmt.Errorf("%w: %s", ErrDependencyCycle, formatCycles(g.Cycles()))
+	}
+	return result, nil
+}
+
+// Cycles returns every group of tasks that depend on each other, using
+// Tarjan's strongly connected components algorithm.
+func (g *Graph) Cycles() [][]int {
+	index := 0
+	indices := make(map[int]int)
+	lowlink := make(map[int]int)
+	onStack := make(map[int]bool)
+	var stack []int
+	var cycles [][]int
+
+	var visit func(id int)
+	visit = func(id int) {
+		indices[id] = index
+		lowlink[id] = index
+		index++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, dep := range g.deps[id] {
+			if _, seen := indices[dep]; !seen {
+				visit(dep)
+				lowlink[id] = min(lowlink[id], lowlink[dep])
+			} else if onStack[dep] {
+				lowlink[id] = min(lowlink[id], indices[dep])
+			}
+		}
+
+		if lowlink[id] == indices[id] {
+			var component []int
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == id {
+					break
+				}
+			}
+			if len(component) > 1 || g.dependsOn(id, id) {
+				sort.Ints(component)
+				cycles = append(cycles, component)
+			}
+		}
+	}
+
+	for _, id := range g.order {
+		if _, seen := indices[id]; !seen {
+			visit(id)
+		}
+	}
+	return cycles
+}
+
+func (g *Graph) dependsOn(id, dep int) bool {
+	for _, d := range g.deps[id] {
+		if d == dep {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckDependencies validates setting deps on task id: every dependency must
+// exist and, when deps change, the result must not put id in a cycle. Cycles
+// elsewhere in the graph are left alone, so they do not block every change.
+func CheckDependencies(tasks []Task, id int, deps []int) error {
+	exists := make(map[int]bool, len(tasks))
+	for _, t := range tasks {
+		exists[t.ID] = true
+	}
+	for _, dep := range deps {
+		if !exists[dep] {
+			return fmt.Errorf("%w: dependency #%d", ErrTaskNotFound, dep)
+		}
+	}
+	if current := findTask(tasks, id); current != nil && sameIDs(current.Dependencies, deps) {
+		return nil
+	}
+
+	candidate := make([]Task, len(tasks))
+	copy(candidate, tasks)
+	for i := range candidate {
+		if candidate[i].ID == id {
+			candidate[i].Dependencies = deps
+		}
+	}
+	for _, cycle := range NewGraph(candidate).Cycles() {
+		if slices.Contains(cycle, id) {
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, FormatIDs(cycle))
+		}
+	}
+	return nil
+}
+
+// sameIDs reports whether a and b hold the same IDs, in any order
+func sameIDs(a, b []int) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+// FormatIDs renders IDs as "#1, #2"
+func FormatIDs(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprintf("#%d", id)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatCycles(cycles [][]int) string {
+	parts := make([]string, len(cycles))
+	for i, c := range cycles {
+		parts[i] = FormatIDs(c)
+	}
+	return strings.Join(parts, "; ")
+}
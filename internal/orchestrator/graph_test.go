@@ -0,0 +1,89 @@
+package orchestrator
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGraphOrderAndBlockers(t *testing.T) {
+	tasks := []Task{
+		{ID: 4, Status: StatusPending, Dependencies: []int{2, 3}},
+		{ID: 2, Status: StatusCompleted, Dependencies: []int{1}},
+		{ID: 1, Status: StatusCompleted},
+		{ID: 3, Status: StatusInProgress, Dependencies: []int{1, 99}}, // #99 was removed
+	}
+	g := NewGraph(tasks)
+
+	order, err := g.TopoOrder()
+	if err != nil {
+		t.Fatalf("TopoOrder: %v", err)
+	}
+	if want := []int{1, 2, 3, 4}; !reflect.DeepEqual(order, want) {
+		t.Errorf("expected order %v, got %v", want, order)
+	}
+
+	if got := g.Blockers(4); !reflect.DeepEqual(got, []int{3}) {
+		t.Errorf("expected #4 blocked by [3], got %v", got)
+	}
+	if g.IsBlocked(3) {
+		t.Error("missing dependency #99 should not block #3")
+	}
+	if got := g.Dependents(1); !reflect.DeepEqual(got, []int{2, 3}) {
+		t.Errorf("unexpected dependents of #1: %v", got)
+	}
+}
+
+func TestGraphCycles(t *testing.T) {
+	tasks := []Task{
+		{ID: 1, Dependencies: []int{3}},
+		{ID: 2, Dependencies: []int{1}},
+		{ID: 3, Dependencies: []int{2}},
+		{ID: 4, Dependencies: []int{4}},
+		{ID: 5},
+	}
+	g := NewGraph(tasks)
+	if got := g.Cycles(); !reflect.DeepEqual(got, [][]int{{1, 2, 3}, {4}}) {
+		t.Errorf("unexpected cycles %v", got)
+	}
+	if _, err := g.TopoOrder(); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("expected ErrDependencyCycle, got %v", err)
+	}
+
+	if err := CheckDependencies(tasks[4:], 5, []int{5}); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("self dependency should be rejected, got %v", err)
+	}
+	// an existing cycle only blocks changes that keep a task in one
+	if err := CheckDependencies(tasks, 5, []int{1, 4}); err != nil {
+		t.Errorf("depending on tasks in a cycle should be allowed, got %v", err)
+	}
+	if err := CheckDependencies(tasks, 1, []int{3}); err != nil {
+		t.Errorf("unchanged dependencies should be allowed, got %v", err)
+	}
+	if err := CheckDependencies(tasks, 1, []int{3, 5}); !errors.Is(err, ErrDependencyCycle) || !strings.Contains(err.Error(), "#1, #2, #3") || strings.Contains(err.Error(), "#4") {
+		t.Errorf("expected only the cycle of #1 reported, got %v", err)
+	}
+	if err := CheckDependencies(tasks, 1, nil); err != nil {
+		t.Errorf("breaking the cycle should be allowed, got %v", err)
+	}
+}
+
+func TestStartRespectsDependencies(t *testing.T) {
+	s := newTestStore(t, `{"tasks": [], "last_id": 0}`)
+	first, _ := s.Add("schema", "backend", "")
+	second, err := s.AddTask(Task{Description: "api", Agent: "backend", Dependencies: []int{first.ID}})
+	if err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+
+	if _, err := s.Start(second.ID); !errors.Is(err, ErrBlocked) {
+		t.Fatalf("expected ErrBlocked, got %v", err)
+	}
+	if _, err := s.ForceStart(second.ID); err != nil {
+		t.Fatalf("ForceStart: %v", err)
+	}
+	if _, err := s.SetDependencies(first.ID, []int{second.ID}); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("expected ErrDependencyCycle, got %v", err)
+	}
+}
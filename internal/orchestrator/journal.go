@@ -0,0 +1,329 @@
+package orchestrator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	// ErrNothingToUndo is returned when the journal has no entry left to undo
+	ErrNothingToUndo = errors.New("nothing to undo")
+	// ErrNothingToRedo is returned when no undone entry can be redone
+	ErrNothingToRedo = errors.New("nothing to redo")
+	// ErrJournalConflict is returned when a task changed since the journal
+	// entry being undone or redone, e.g. because an agent worked on it
+	ErrJournalConflict = errors.New("task changed since")
+)
+
+// journalLimit is the number of entries kept; older ones are dropped
+const journalLimit = 200
+
+// JournalEntry is one mutation of tasks.json made from the control center,
+// holding every task it changed as it was before and after
+type JournalEntry struct {
+	ID     int    `json:"id"`
+	Time   string `json:"time"`
+	Op     string `json:"op"`
+	Before []Task `json:"before"` // a task missing here was added
+	After  []Task `json:"after"`  // a task missing here was removed
+}
+
+// IDs returns the tasks the entry changed, in ID order
+func (e JournalEntry) IDs() []int {
+	seen := map[int]bool{}
+	var ids []int
+	for _, list := range [][]Task{e.Before, e.After} {
+		for _, t := range list {
+			if !seen[t.ID] {
+				seen[t.ID] = true
+				ids = append(ids, t.ID)
+			}
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// journalFile is the content of .claude/journal.json
+type journalFile struct {
+	LastID  int            `json:"last_id"`
+	Entries []JournalEntry `json:"entries"`
+	Undone  int            `json:"undone"` // entries at the end that can be redone
+}
+
+// JournalPath returns .claude/journal.json
+func (p *Project) JournalPath() string {
+	return p.Path("journal.json")
+}
+
+// Journal returns the operation journal of this project
+func (p *Project) Journal() *Journal {
+	return &Journal{path: p.JournalPath(), tasks: p.Store(), now: time.Now}
+}
+
+// Journal records the task mutations of the control center so they can be
+// undone and redone across restarts. Writes take the journal lock before the
+// tasks.json lock.
+type Journal struct {
+	path  string
+	tasks *TaskStore
+	now   func() time.Time
+}
+
+// Record runs fn against the task store and journals what it changed under
+// op. fn returns the IDs of the tasks it touched; only those are compared, so
+// agents writing other tasks meanwhile do not end up in the entry. Nothing is
+// recorded when no task changed. Changes made before fn failed are still
+// recorded, so a partly applied bulk action can be undone.
+func (j *Journal) Record(op string, fn func(s *TaskStore) ([]int, error)) error {
+	lock, err := LockFile(j.path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	before, err := j.tasks.Load()
+	if err != nil {
+		return err
+	}
+	ids, ferr := fn(j.tasks)
+	after, err := j.tasks.Load()
+	if err != nil {
+		return errors.Join(ferr, err)
+	}
+	e := diffTasks(pickTasks(before.Tasks, ids), pickTasks(after.Tasks, ids))
+	if len(e.Before) == 0 && len(e.After) == 0 {
+		return ferr
+	}
+
+	file, err := j.load()
+	if err != nil {
+		return errors.Join(ferr, err)
+	}
+	// a new change forgets what was undone
+	file.Entries = file.Entries[:len(file.Entries)-file.Undone]
+	file.Undone = 0
+	file.LastID++
+	e.ID, e.Op, e.Time = file.LastID, op, j.now().UTC().Format(time.RFC3339)
+	file.Entries = append(file.Entries, e)
+	if n := len(file.Entries) - journalLimit; n > 0 {
+		file.Entries = file.Entries[n:]
+	}
+	return errors.Join(ferr, j.save(file))
+}
+
+// Undo reverts the latest entry that is not undone yet
+func (j *Journal) Undo() (JournalEntry, error) {
+	return j.step(true)
+}
+
+// Redo reapplies the latest undone entry
+func (j *Journal) Redo() (JournalEntry, error) {
+	return j.step(false)
+}
+
+// History returns the entries, oldest first, and how many of the last ones
+// are undone
+func (j *Journal) History() ([]JournalEntry, int, error) {
+	file, err := j.load()
+	if err != nil {
+		return nil, 0, err
+	}
+	return file.Entries, file.Undone, nil
+}
+
+func (j *Journal) step(undo bool) (JournalEntry, error) {
+	lock, err := LockFile(j.path)
+	if err != nil {
+		return JournalEntry{}, err
+	}
+	defer lock.Unlock()
+
+	file, err := j.load()
+	if err != nil {
+		return JournalEntry{}, err
+	}
+	var e JournalEntry
+	var current, target []Task
+	switch {
+	case undo && file.Undone >= len(file.Entries):
+		return JournalEntry{}, ErrNothingToUndo
+	case undo:
+		e = file.Entries[len(file.Entries)-1-file.Undone]
+		current, target = e.After, e.Before
+	case file.Undone == 0:
+		return JournalEntry{}, ErrNothingToRedo
+	default:
+		e = file.Entries[len(file.Entries)-file.Undone]
+		current, target = e.Before, e.After
+	}
+
+	if err := j.tasks.Update(func(data *TasksData) error {
+		return applyTasks(data, e.IDs(), current, target)
+	}); err != nil {
+		return e, err
+	}
+	if undo {
+		file.Undone++
+	} else {
+		file.Undone--
+	}
+	return e, j.save(file)
+}
+
+// applyTasks replaces the tasks ids, which must still be as in current, by
+// their versions in target. A task missing from target is removed.
+func applyTasks(data *TasksData, ids []int, current, target []Task) error {
+	for _, id := range ids {
+		if !sameTask(data.Find(id), findTask(current, id)) {
+			return fmt.Errorf("%w: task #%d was modified afterwards", ErrJournalConflict, id)
+		}
+	}
+	for _, id := range ids {
+		t := findTask(target, id)
+		existing := data.Find(id)
+		switch {
+		case t == nil:
+			for i := range data.Tasks {
+				if data.Tasks[i].ID == id {
+					data.Tasks = append(data.Tasks[:i], data.Tasks[i+1:]...)
+					break
+				}
+			}
+		case existing != nil:
+			*existing = *t
+		default:
+			// restored tasks go back to their place in ID order
+			i := sort.Search(len(data.Tasks), func(i int) bool { return data.Tasks[i].ID > id })
+			data.Tasks = append(data.Tasks[:i], append([]Task{*t}, data.Tasks[i:]...)...)
+			data.LastID = max(data.LastID, id)
+		}
+	}
+	return nil
+}
+
+// diffTasks returns the tasks that differ between two snapshots of tasks.json
+func diffTasks(before, after []Task) JournalEntry {
+	var e JournalEntry
+	for _, t := range before {
+		if !sameTask(&t, findTask(after, t.ID)) {
+			e.Before = append(e.Before, t)
+		}
+	}
+	for _, t := range after {
+		if !sameTask(&t, findTask(before, t.ID)) {
+			e.After = append(e.After, t)
+		}
+	}
+	return e
+}
+
+// pickTasks returns the tasks among ids
+func pickTasks(tasks []Task, ids []int) []Task {
+	var picked []Task
+	for _, id := range ids {
+		if t := findTask(tasks, id); t != nil {
+			picked = append(picked, *t)
+		}
+	}
+	return picked
+}
+
+func findTask(tasks []Task, id int) *Task {
+	for i := range tasks {
+		if tasks[i].ID == id {
+			return &tasks[i]
+		}
+	}
+	return nil
+}
+
+// sameTask compares two tasks field by field, including unmodelled fields.
+// nil stands for a task that does not exist.
+func sameTask(a, b *Task) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// load reads journal.json. A missing file is an empty journal.
+func (j *Journal) load() (*journalFile, error) {
+	b, err := os.ReadFile(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &journalFile{}, nil
+		}
+		return nil, fmt.Errorf("failed to read journal.json: %w", err)
+	}
+	var file journalFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse journal.json: %w", err)
+	}
+	file.Undone = max(0, min(file.Undone, len(file.Entries)))
+	return &file, nil
+}
+
+func (j *Journal) save(file *journalFile) error {
+	out, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := WriteFileAtomic(j.path, append(out, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write journal.json: %w", err)
+	}
+	return nil
+}
+
+// journaled runs a control-center mutation of the current project through
+// its journal, so it can be undone
+func journaled(op string, fn func(s *TaskStore) ([]int, error)) error {
+	p, err := CurrentProject()
+	if err != nil {
+		return err
+	}
+	return p.Journal().Record(op, fn)
+}
+
+// JournalMsg reports an undone or redone entry
+type JournalMsg struct {
+	Entry JournalEntry
+	Undo  bool
+}
+
+// UndoCmd undoes the latest control-center mutation
+func UndoCmd() tea.Cmd {
+	return journalCmd(true)
+}
+
+// RedoCmd redoes the latest undone mutation
+func RedoCmd() tea.Cmd {
+	return journalCmd(false)
+}
+
+func journalCmd(undo bool) tea.Cmd {
+	return func() tea.Msg {
+		p, err := CurrentProject()
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		step := p.Journal().Redo
+		if undo {
+			step = p.Journal().Undo
+		}
+		e, err := step()
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		return JournalMsg{Entry: e, Undo: undo}
+	}
+}
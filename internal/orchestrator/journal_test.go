@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournalUndoRedo(t *testing.T) {
+	s := newTestStore(t, `{"tasks": [
+		{"id": 1, "description": "a", "status": "pending", "priority": "normal"},
+		{"id": 2, "description": "b", "status": "pending", "priority": "normal", "dependencies": [1]},
+		{"id": 3, "description": "c", "status": "pending", "priority": "normal"}
+	], "last_id": 3}`)
+	// a fresh Journal for each step, as after a restart
+	journal := func() *Journal {
+		return &Journal{path: filepath.Join(filepath.Dir(s.Path()), "journal.json"), tasks: s, now: time.Now}
+	}
+	descriptions := func() string {
+		t.Helper()
+		data, err := s.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := ""
+		for _, task := range data.Tasks {
+			out += task.Description
+		}
+		return out
+	}
+
+	if _, err := journal().Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("expected ErrNothingToUndo, got %v", err)
+	}
+	if err := journal().Record("remove #1", func(s *TaskStore) ([]int, error) { return []int{1}, s.Remove(1) }); err != nil {
+		t.Fatal(err)
+	}
+	if err := journal().Record("edit #3", func(s *TaskStore) ([]int, error) {
+		// an agent finishing another task meanwhile stays out of the entry
+		if _, err := s.Complete(2); err != nil {
+			return nil, err
+		}
+		_, err := s.Edit(3, "C")
+		return []int{3}, err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// failures that changed nothing are not journaled
+	if err := journal().Record("remove #9", func(s *TaskStore) ([]int, error) { return []int{9}, s.Remove(9) }); !errors.Is(err, ErrTaskNotFound) {
+		t.Fatalf("expected ErrTaskNotFound, got %v", err)
+	}
+	if entries, _, _ := journal().History(); len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+
+	e, err := journal().Undo()
+	if err != nil || e.Op != "edit #3" || FormatIDs(e.IDs()) != "#3" || descriptions() != "bc" {
+		t.Fatalf("unexpected undo of the edit: %+v %v (%s)", e, err, descriptions())
+	}
+	e, err = journal().Undo()
+	if err != nil || e.Op != "remove #1" || descriptions() != "abc" {
+		t.Fatalf("unexpected undo of the removal: %+v %v (%s)", e, err, descriptions())
+	}
+	if task, _ := s.Get(2); len(task.Dependencies) != 1 {
+		t.Errorf("expected #2 to keep its dependency, got %+v", task)
+	}
+	if _, err := journal().Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("expected ErrNothingToUndo, got %v", err)
+	}
+
+	if e, err := journal().Redo(); err != nil || e.Op != "remove #1" || descriptions() != "bc" {
+		t.Fatalf("unexpected redo: %+v %v (%s)", e, err, descriptions())
+	}
+	// a change after undoing drops what could be redone
+	if err := journal().Record("edit #2", func(s *TaskStore) ([]int, error) {
+		_, err := s.Edit(2, "B")
+		return []int{2}, err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := journal().Redo(); !errors.Is(err, ErrNothingToRedo) {
+		t.Fatalf("expected ErrNothingToRedo, got %v", err)
+	}
+
+	// a task changed outside the journal is never overwritten
+	if _, err := s.Edit(2, "changed by an agent"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := journal().Undo(); !errors.Is(err, ErrJournalConflict) {
+		t.Fatalf("expected ErrJournalConflict, got %v", err)
+	}
+	if task, _ := s.Get(2); task.Status != StatusCompleted || task.Description != "changed by an agent" {
+		t.Errorf("expected #2 untouched, got %+v", task)
+	}
+}
+
+func TestDiffTasks(t *testing.T) {
+	before := []Task{{ID: 1, Description: "a"}, {ID: 2, Description: "b"}}
+	after := []Task{{ID: 2, Description: "B"}, {ID: 3, Description: "c"}}
+	e := diffTasks(before, after)
+	if len(e.Before) != 2 || len(e.After) != 2 || FormatIDs(e.IDs()) != "#1, #2, #3" {
+		t.Errorf("unexpected diff: %+v", e)
+	}
+	if e := diffTasks(before, before); len(e.Before)+len(e.After) != 0 {
+		t.Errorf("expected no change, got %+v", e)
+	}
+}
@@ -0,0 +1,193 @@
+package orchestrator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Sizes used when tailing logs
+const (
+	logTailBytes  = 64 * 1024   // how far back a log is read when it is opened
+	logChunkBytes = 1024 * 1024 // most bytes read at once while following
+)
+
+// Log levels as found in "[LEVEL]" tags, in increasing severity
+const (
+	LevelNone = iota // no tag: the line continues the previous one
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// LogChunk is a run of complete lines read from a log file
+type LogChunk struct {
+	From  int64 // offset the read started at
+	Next  int64 // offset to continue from
+	Lines []string
+	Reset bool // the file was truncated or replaced; drop what was read before
+	More  bool // the file has more data past Next
+}
+
+// ReadLogChunk reads the complete lines of path starting at from. A negative
+// from opens the log: only about the last logTailBytes are returned, starting
+// at a line boundary. A trailing line without newline is left for the next
+// read, since the writer is probably still in the middle of it, unless it
+// fills the whole chunk: such a line is returned in pieces of logChunkBytes.
+func ReadLogChunk(path string, from int64) (LogChunk, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return LogChunk{From: from}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return LogChunk{From: from}, err
+	}
+	size := info.Size()
+
+	chunk := LogChunk{From: from}
+	start := from
+	if from < 0 {
+		start = max(0, size-logTailBytes)
+		chunk.Reset = true
+	} else if size < from {
+		start = 0
+		chunk.Reset = true
+	}
+	end := min(size, start+logChunkBytes)
+
+	buf := make([]byte, end-start)
+	if _, err := f.ReadAt(buf, start); err != nil && err != io.EOF {
+		return chunk, err
+	}
+	skip := 0
+	if from < 0 && start > 0 {
+		// started in the middle of a line
+		if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+			skip = i + 1
+		} else {
+			skip = len(buf)
+		}
+	}
+	last := bytes.LastIndexByte(buf, '\n')
+	if last < skip && skip == 0 && len(buf) == logChunkBytes {
+		// a line longer than a chunk; cut it at a character boundary
+		cut := len(buf)
+		for i := len(buf) - 1; i >= max(0, len(buf)-utf8.UTFMax); i-- {
+			if utf8.RuneStart(buf[i]) {
+				if !utf8.FullRune(buf[i:]) {
+					cut = i
+				}
+				break
+			}
+		}
+		line := string(buf[:cut])
+		chunk.Next = start + int64(cut)
+		// do not start the next piece with the newline ending this one
+		nl := make([]byte, 1)
+		if _, err := f.ReadAt(nl, chunk.Next); err == nil && nl[0] == '\n' {
+			line = strings.TrimSuffix(line, "\r")
+			chunk.Next++
+		}
+		chunk.Lines = []string{line}
+		chunk.More = chunk.Next < size
+		return chunk, nil
+	}
+	if last < skip {
+		chunk.Next = start + int64(skip)
+		chunk.More = chunk.Next < size && end < size
+		return chunk, nil
+	}
+	text := strings.TrimSuffix(string(buf[skip:last]), "\r")
+	for _, line := range strings.Split(text, "\n") {
+		chunk.Lines = append(chunk.Lines, strings.TrimSuffix(line, "\r"))
+	}
+	chunk.Next = start + int64(last) + 1
+	chunk.More = end < size
+	return chunk, nil
+}
+
+// LogLevel returns the level tagged in a log line ([DEBUG], [INFO], [WARN],
+// [WARNING], [ERROR]), or LevelNone
+func LogLevel(line string) int {
+	for {
+		i := strings.IndexByte(line, '[')
+		if i < 0 {
+			return LevelNone
+		}
+		line = line[i+1:]
+		j := strings.IndexByte(line, ']')
+		if j < 0 {
+			return LevelNone
+		}
+		switch strings.ToUpper(line[:j]) {
+		case "DEBUG", "TRACE":
+			return LevelDebug
+		case "INFO":
+			return LevelInfo
+		case "WARN", "WARNING":
+			return LevelWarn
+		case "ERROR", "FATAL":
+			return LevelError
+		}
+	}
+}
+
+// TaskLogPath returns .claude/logs/task-<id>.log, where the raw output of a
+// task run is kept
+func (p *Project) TaskLogPath(id int) string {
+	return filepath.Join(p.LogsDir(), fmt.Sprintf("task-%d.log", id))
+}
+
+// OrchestratorLogFile returns the newest orchestrator-YYYY-MM-DD.log, or ""
+func (p *Project) OrchestratorLogFile() string {
+	matches, _ := filepath.Glob(filepath.Join(p.LogsDir(), "orchestrator-*.log"))
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Strings(matches)
+	return matches[len(matches)-1]
+}
+
+// TaskLogFile picks the log to show for a task: its own run log, otherwise
+// the log of its agent, otherwise the orchestrator log. "" if none exists.
+func (p *Project) TaskLogFile(t Task) string {
+	if path := p.TaskLogPath(t.ID); fileExists(path) {
+		return path
+	}
+	if t.Agent != "" {
+		if path := p.AgentLogFile(t.Agent); path != "" {
+			return path
+		}
+	}
+	return p.OrchestratorLogFile()
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// LogChunkMsg carries lines read from a log file
+type LogChunkMsg struct {
+	Path string
+	LogChunk
+	Err error
+}
+
+// TailLogCmd reads the lines appended to path since from (negative to open it)
+func TailLogCmd(path string, from int64) tea.Cmd {
+	return func() tea.Msg {
+		chunk, err := ReadLogChunk(path, from)
+		return LogChunkMsg{Path: path, LogChunk: chunk, Err: err}
+	}
+}
@@ -0,0 +1,122 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadLogChunk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "task-1.log")
+	write := func(s string, flag int) {
+		f, err := os.OpenFile(path, flag|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("one\r\ntwo\nthr", os.O_TRUNC)
+	c, err := ReadLogChunk(path, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Lines, []string{"one", "two"}) || !c.Reset || c.Next != 9 {
+		t.Fatalf("unexpected first chunk %+v", c)
+	}
+
+	// the partial line is picked up once it is finished
+	write("ee\n", os.O_APPEND)
+	c, err = ReadLogChunk(path, c.Next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Lines, []string{"three"}) || c.Reset {
+		t.Fatalf("unexpected appended chunk %+v", c)
+	}
+
+	// a truncated file is read again from the start
+	write("new\n", os.O_TRUNC)
+	c, err = ReadLogChunk(path, c.Next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Lines, []string{"new"}) || !c.Reset {
+		t.Fatalf("unexpected chunk after truncation %+v", c)
+	}
+
+	// opening a large log only reads its tail, from a line boundary
+	write(strings.Repeat("0123456789abcdef\n", 2*logTailBytes/17), os.O_TRUNC)
+	c, err = ReadLogChunk(path, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Lines) > logTailBytes/17+1 || c.Lines[0] != "0123456789abcdef" {
+		t.Fatalf("expected only whole lines of the tail, got %d lines starting %q", len(c.Lines), c.Lines[0])
+	}
+
+	// a line longer than a chunk is returned in pieces instead of stalling
+	write(strings.Repeat("x", 2*1024*1024)+"\nafter\n", os.O_TRUNC)
+	var got []string
+	for from, reads := int64(0), 0; ; reads++ {
+		if reads > 5 {
+			t.Fatalf("no progress past offset %d", from)
+		}
+		c, err := ReadLogChunk(path, from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, c.Lines...)
+		from = c.Next
+		if !c.More {
+			break
+		}
+	}
+	if len(got) != 3 || len(got[0])+len(got[1]) != 2*1024*1024 || got[2] != "after" {
+		t.Fatalf("unexpected pieces of the long line: %d lines", len(got))
+	}
+}
+
+func TestLogLevel(t *testing.T) {
+	for line, want := range map[string]int{
+		"[2025-02-07 12:34:56] [INFO] started": LevelInfo,
+		"18:44:02 [DEBUG] Fetching tasks":      LevelDebug,
+		"[2025-02-07] [WARN] fallback":         LevelWarn,
+		"[2025-02-07] [error] failed":          LevelError,
+		"    at main.go:12":                    LevelNone,
+		"[tool] Edit src/api.go [not a level":  LevelNone,
+	} {
+		if got := LogLevel(line); got != want {
+			t.Errorf("LogLevel(%q) = %d, want %d", line, got, want)
+		}
+	}
+}
+
+func TestTaskLogFile(t *testing.T) {
+	p := &Project{Root: t.TempDir()}
+	if err := os.MkdirAll(p.LogsDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	task := Task{ID: 4, Agent: "backend"}
+	if got := p.TaskLogFile(task); got != "" {
+		t.Errorf("expected no log, got %s", got)
+	}
+	for _, step := range []struct{ create, want string }{
+		{"orchestrator-2026-02-15.log", "orchestrator-2026-02-15.log"},
+		{"orchestrator-2026-02-14.log", "orchestrator-2026-02-15.log"},
+		{"agent-backend.log", "agent-backend.log"},
+		{"task-4.log", "task-4.log"},
+	} {
+		if err := os.WriteFile(filepath.Join(p.LogsDir(), step.create), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := filepath.Base(p.TaskLogFile(task)); got != step.want {
+			t.Errorf("after creating %s expected %s, got %s", step.create, step.want, got)
+		}
+	}
+}
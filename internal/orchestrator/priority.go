@@ -0,0 +1,137 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Task priorities, from the most urgent
+const (
+	PriorityCritical = "critical"
+	PriorityHigh     = "high"
+	PriorityNormal   = "normal"
+	PriorityLow      = "low"
+)
+
+// Priorities lists the priorities from the most urgent
+var Priorities = []string{PriorityCritical, PriorityHigh, PriorityNormal, PriorityLow}
+
+// ErrNoTask is returned when no task is ready for an agent
+var ErrNoTask = errors.New("no task ready")
+
+// PriorityRank orders priorities: 0 is critical. Empty and unknown values
+// rank as normal.
+func PriorityRank(p string) int {
+	for i, name := range Priorities {
+		if p == name {
+			return i
+		}
+	}
+	return 2
+}
+
+// ValidPriority reports whether p is one of Priorities
+func ValidPriority(p string) bool {
+	for _, name := range Priorities {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ShiftPriority raises (delta > 0) or lowers (delta < 0) a priority by
+// delta steps, stopping at critical and low
+func ShiftPriority(p string, delta int) string {
+	i := PriorityRank(p) - delta
+	return Priorities[max(0, min(i, len(Priorities)-1))]
+}
+
+// SortByPriority orders tasks by priority, then by age (oldest first)
+func SortByPriority(tasks []Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if ra, rb := PriorityRank(a.Priority), PriorityRank(b.Priority); ra != rb {
+			return ra < rb
+		}
+		if a.CreatedAt != b.CreatedAt {
+			return a.CreatedAt < b.CreatedAt
+		}
+		return a.ID < b.ID
+	})
+}
+
+// NextTask picks the task an agent should work on next: the most urgent,
+// then oldest, pending task assigned to it whose dependencies are done.
+// Unassigned tasks are picked when the agent has none of its own.
+func NextTask(tasks []Task, agent string) (Task, bool) {
+	graph := NewGraph(tasks)
+	var own, unassigned []Task
+	for _, t := range tasks {
+		if t.Status != StatusPending || graph.IsBlocked(t.ID) {
+			continue
+		}
+		switch t.Agent {
+		case agent:
+			own = append(own, t)
+		case "":
+			unassigned = append(unassigned, t)
+		}
+	}
+	for _, ready := range [][]Task{own, unassigned} {
+		if len(ready) > 0 {
+			SortByPriority(ready)
+			return ready[0], true
+		}
+	}
+	return Task{}, false
+}
+
+// ClaimNext starts the next task of an agent (see NextTask), assigning it
+// when it was unassigned. It fails with ErrNoTask when nothing is ready.
+func (s *TaskStore) ClaimNext(agent string) (Task, error) {
+	var claimed Task
+	err := s.Update(func(data *TasksData) error {
+		next, ok := NextTask(data.Tasks, agent)
+		if !ok {
+			return fmt.Errorf("%w for %s", ErrNoTask, agent)
+		}
+		now := s.timestamp()
+		t := data.Find(next.ID)
+		t.Agent = agent
+		t.Status = StatusInProgress
+		t.StartedAt = now
+		t.UpdatedAt = now
+		claimed = *t
+		return nil
+	})
+	return claimed, err
+}
+
+// SetPriority changes the priority of a task
+func (s *TaskStore) SetPriority(id int, priority string) (Task, error) {
+	if !ValidPriority(priority) {
+		return Task{}, fmt.Errorf("invalid priority %q (want critical, high, normal or low)", priority)
+	}
+	return s.Modify(id, func(t *Task) error {
+		t.Priority = priority
+		return nil
+	})
+}
+
+// SetPriorityCmd changes the priority of a task and reloads the list
+func SetPriorityCmd(id int, priority string) tea.Cmd {
+	return func() tea.Msg {
+		err := journaled(fmt.Sprintf("set #%d to %s", id, priority), func(s *TaskStore) ([]int, error) {
+			_, err := s.SetPriority(id, priority)
+			return []int{id}, err
+		})
+		if err != nil {
+			return ErrorMsg(fmt.Errorf("priority change failed: %w", err))
+		}
+		return FetchTasksCmd()()
+	}
+}
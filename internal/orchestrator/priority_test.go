@@ -0,0 +1,60 @@
+package orchestrator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSortByPriority(t *testing.T) {
+	tasks := []Task{
+		{ID: 1, Priority: "low", CreatedAt: "2026-01-01T00:00:00Z"},
+		{ID: 2, Priority: "", CreatedAt: "2026-01-03T00:00:00Z"},
+		{ID: 3, Priority: "critical", CreatedAt: "2026-01-04T00:00:00Z"},
+		{ID: 4, Priority: "normal", CreatedAt: "2026-01-02T00:00:00Z"},
+		{ID: 5, Priority: "high", CreatedAt: "2026-01-05T00:00:00Z"},
+	}
+	SortByPriority(tasks)
+	var ids []int
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	if FormatIDs(ids) != FormatIDs([]int{3, 5, 4, 2, 1}) {
+		t.Errorf("unexpected order %v", ids)
+	}
+	if ShiftPriority("normal", 1) != "high" || ShiftPriority("critical", 1) != "critical" || ShiftPriority("", -1) != "low" || ShiftPriority("low", -1) != "low" {
+		t.Error("unexpected ShiftPriority results")
+	}
+}
+
+func TestClaimNext(t *testing.T) {
+	s := newTestStore(t, `{"tasks": [
+		{"id": 1, "description": "schema", "status": "pending", "agent": "backend", "priority": "normal"},
+		{"id": 2, "description": "api", "status": "pending", "agent": "backend", "priority": "critical", "dependencies": [1]},
+		{"id": 3, "description": "cleanup", "status": "pending", "agent": "backend", "priority": "low"},
+		{"id": 4, "description": "ui", "status": "pending", "agent": "frontend", "priority": "critical"}
+	], "last_id": 4}`)
+
+	// the critical task is blocked, so the normal one goes first
+	for _, want := range []int{1, 3} {
+		got, err := s.ClaimNext("backend")
+		if err != nil || got.ID != want || got.Status != StatusInProgress || got.StartedAt == "" {
+			t.Fatalf("expected #%d, got %+v (%v)", want, got, err)
+		}
+	}
+	if _, err := s.ClaimNext("backend"); !errors.Is(err, ErrNoTask) {
+		t.Errorf("expected ErrNoTask while #2 is blocked, got %v", err)
+	}
+	if _, err := s.Complete(1); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.ClaimNext("backend"); err != nil || got.ID != 2 {
+		t.Errorf("expected #2 once unblocked, got %+v (%v)", got, err)
+	}
+
+	if _, err := s.SetPriority(4, "urgent"); err == nil {
+		t.Error("expected an unknown priority to be refused")
+	}
+	if got, err := s.SetPriority(4, "low"); err != nil || got.Priority != "low" {
+		t.Errorf("SetPriority: %+v %v", got, err)
+	}
+}
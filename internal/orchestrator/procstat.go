@@ -0,0 +1,36 @@
+package orchestrator
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrProcStatUnsupported is returned where /proc is not available
+var ErrProcStatUnsupported = errors.New("process statistics are not supported on this platform")
+
+// ProcStat is a snapshot of a process's resource usage
+type ProcStat struct {
+	PID       int           `json:"pid"`
+	StartTime time.Time     `json:"start_time"`
+	CPUTime   time.Duration `json:"cpu_time_ns"` // user + system time consumed so far
+	RSS       int64         `json:"rss_bytes"`   // resident set size
+	SampledAt time.Time     `json:"sampled_at"`
+}
+
+// Uptime returns how long the process has been running at the time of the sample
+func (s ProcStat) Uptime() time.Duration {
+	return s.SampledAt.Sub(s.StartTime)
+}
+
+// CPUPercent returns CPU usage between prev and s, or the average over the
+// process lifetime when prev is not an earlier sample of the same process
+func (s ProcStat) CPUPercent(prev *ProcStat) float64 {
+	cpu, wall := s.CPUTime, s.Uptime()
+	if prev != nil && prev.PID == s.PID && prev.SampledAt.Before(s.SampledAt) {
+		cpu, wall = s.CPUTime-prev.CPUTime, s.SampledAt.Sub(prev.SampledAt)
+	}
+	if wall <= 0 {
+		return 0
+	}
+	return 100 * cpu.Seconds() / wall.Seconds()
+}
@@ -0,0 +1,73 @@
+//go:build linux
+
+package orchestrator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks is USER_HZ, which is 100 on every Linux architecture we run on
+const clockTicks = 100
+
+// ReadProcStat reads /proc/<pid>/stat
+func ReadProcStat(pid int) (ProcStat, error) {
+	raw, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return ProcStat{}, err
+	}
+	// The command name may contain spaces and parentheses; fields resume after the last ")"
+	s := string(raw)
+	end := strings.LastIndexByte(s, ')')
+	if end < 0 {
+		return ProcStat{}, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+	fields := strings.Fields(s[end+1:])
+	// fields[0] is field 3 (state): utime=14, stime=15, starttime=22, rss=24
+	if len(fields) < 22 {
+		return ProcStat{}, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+	num := func(field int) int64 {
+		n, _ := strconv.ParseInt(fields[field-3], 10, 64)
+		return n
+	}
+
+	boot, err := bootTime()
+	if err != nil {
+		return ProcStat{}, err
+	}
+	ticks := func(n int64) time.Duration {
+		return time.Duration(n) * time.Second / clockTicks
+	}
+	return ProcStat{
+		PID:       pid,
+		StartTime: boot.Add(ticks(num(22))),
+		CPUTime:   ticks(num(14) + num(15)),
+		RSS:       num(24) * int64(os.Getpagesize()),
+		SampledAt: time.Now(),
+	}, nil
+}
+
+// bootTime reads btime from /proc/stat
+func bootTime() (time.Time, error) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if v, ok := strings.CutPrefix(scanner.Text(), "btime "); ok {
+			sec, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return time.Unix(sec, 0), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("btime not found in /proc/stat")
+}
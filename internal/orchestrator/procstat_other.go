@@ -0,0 +1,8 @@
+//go:build !linux
+
+package orchestrator
+
+// ReadProcStat is only implemented on Linux
+func ReadProcStat(pid int) (ProcStat, error) {
+	return ProcStat{}, ErrProcStatUnsupported
+}
@@ -0,0 +1,171 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ProjectEnvVar overrides project discovery when set
+const ProjectEnvVar = "CLAUDE_ORCHESTRA_HOME"
+
+// ErrProjectNotFound is returned when no .claude/ directory could be located
+var ErrProjectNotFound = errors.New("no Claude Orchestra project found")
+
+// Project is a directory containing an installed .claude/ orchestra
+type Project struct {
+	Root string
+}
+
+var (
+	projectMu      sync.Mutex
+	currentProject *Project
+)
+
+// SetProject sets the project used by the dashboard commands
+func SetProject(p *Project) {
+	projectMu.Lock()
+	defer projectMu.Unlock()
+	currentProject = p
+}
+
+// CurrentProject returns the project set with SetProject, discovering it on first use
+func CurrentProject() (*Project, error) {
+	projectMu.Lock()
+	defer projectMu.Unlock()
+	if currentProject != nil {
+		return currentProject, nil
+	}
+	p, err := FindProject("")
+	if err != nil {
+		return nil, err
+	}
+	currentProject = p
+	return p, nil
+}
+
+// FindProject resolves the project root. In order of precedence it uses the
+// explicit override (e.g. --project), $CLAUDE_ORCHESTRA_HOME, the nearest
+// ancestor of the working directory and the nearest ancestor of the executable
+// (install.sh puts the binary in .claude/bin).
+func FindProject(override string) (*Project, error) {
+	if override != "" {
+		return projectAt(override, "--project")
+	}
+	if env := os.Getenv(ProjectEnvVar); env != "" {
+		return projectAt(env, ProjectEnvVar)
+	}
+
+	var searched []string
+	if cwd, err := os.Getwd(); err == nil {
+		if root, ok := walkUp(cwd); ok {
+			return &Project{Root: root}, nil
+		}
+		searched = append(searched, cwd)
+	}
+	if exe, err := os.Executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+		dir := filepath.Dir(exe)
+		if root, ok := walkUp(dir); ok {
+			return &Project{Root: root}, nil
+		}
+		searched = append(searched, dir)
+	}
+	return nil, fmt.Errorf("%w: no .claude/ directory above %v (use --project or set %s)", ErrProjectNotFound, searched, ProjectEnvVar)
+}
+
+// projectAt accepts either a project root or its .claude directory
+func projectAt(path, source string) (*Project, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Base(abs) == ".claude" && isDir(abs) {
+		abs = filepath.Dir(abs)
+	}
+	if !isDir(filepath.Join(abs, ".claude")) {
+		return nil, fmt.Errorf("%w: %s (from %s) has no .claude/ directory", ErrProjectNotFound, abs, source)
+	}
+	return &Project{Root: abs}, nil
+}
+
+// walkUp looks for a project root at dir or any of its parents
+func walkUp(dir string) (string, bool) {
+	dir = filepath.Clean(dir)
+	for {
+		if isProjectRoot(dir) {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// isProjectRoot requires an orchestra marker inside .claude/, because
+// ~/.claude also exists for every Claude Code user.
+func isProjectRoot(dir string) bool {
+	claude := filepath.Join(dir, ".claude")
+	for _, marker := range []string{"tasks.json", "scripts/orchestrator.sh", "agent.sh"} {
+		if _, err := os.Stat(filepath.Join(claude, marker)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// ClaudeDir returns the .claude directory
+func (p *Project) ClaudeDir() string {
+	return filepath.Join(p.Root, ".claude")
+}
+
+// Path joins elem onto the .claude directory
+func (p *Project) Path(elem ...string) string {
+	return filepath.Join(append([]string{p.ClaudeDir()}, elem...)...)
+}
+
+// TasksPath returns .claude/tasks.json
+func (p *Project) TasksPath() string {
+	return p.Path("tasks.json")
+}
+
+// ScriptPath returns .claude/scripts/orchestrator.sh
+func (p *Project) ScriptPath() string {
+	return p.Path("scripts", "orchestrator.sh")
+}
+
+// AgentScriptPath returns .claude/agent.sh
+func (p *Project) AgentScriptPath() string {
+	return p.Path("agent.sh")
+}
+
+// PidsDir returns .claude/pids
+func (p *Project) PidsDir() string {
+	return p.Path("pids")
+}
+
+// LogsDir returns .claude/logs
+func (p *Project) LogsDir() string {
+	return p.Path("logs")
+}
+
+// AgentsDir returns .claude/agents
+func (p *Project) AgentsDir() string {
+	return p.Path("agents")
+}
+
+// Store returns a TaskStore for this project's tasks.json
+func (p *Project) Store() *TaskStore {
+	return NewTaskStore(p.TasksPath())
+}
@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindProject(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".claude"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".claude", "tasks.json"), []byte(`{"tasks": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(root, "src", "deep", "pkg")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(ProjectEnvVar, "")
+	t.Chdir(sub)
+	p, err := FindProject("")
+	if err != nil {
+		t.Fatalf("walk up from subdirectory: %v", err)
+	}
+	if p.Root != root {
+		t.Errorf("expected root %s, got %s", root, p.Root)
+	}
+	if p.TasksPath() != filepath.Join(root, ".claude", "tasks.json") {
+		t.Errorf("unexpected tasks path %s", p.TasksPath())
+	}
+
+	// The override may name the .claude directory itself
+	p, err = FindProject(filepath.Join(root, ".claude"))
+	if err != nil || p.Root != root {
+		t.Errorf("override with .claude dir: got %v, %v", p, err)
+	}
+
+	t.Setenv(ProjectEnvVar, root)
+	t.Chdir(os.TempDir())
+	p, err = FindProject("")
+	if err != nil || p.Root != root {
+		t.Errorf("env override: got %v, %v", p, err)
+	}
+
+	if _, err := FindProject(sub); !errors.Is(err, ErrProjectNotFound) {
+		t.Errorf("expected ErrProjectNotFound for a directory without .claude, got %v", err)
+	}
+}
@@ -0,0 +1,466 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	// ErrInvalidQuery is returned for a search query that cannot be parsed
+	ErrInvalidQuery = errors.New("invalid query")
+	// ErrSearchNotFound is returned when no saved search has the given name
+	ErrSearchNotFound = errors.New("saved search not found")
+)
+
+// queryFields lists the fields a query term may name
+var queryFields = []string{"agent", "status", "prio", "id", "dep", "after", "before"}
+
+// statusAliases are the shorter names status: accepts
+var statusAliases = map[string]string{
+	"running":  StatusInProgress,
+	"active":   StatusInProgress,
+	"approval": StatusPendingApproval,
+	"done":     StatusCompleted,
+}
+
+var allStatuses = []string{StatusPending, StatusInProgress, StatusPendingApproval, StatusCompleted, StatusFailed, StatusStopped}
+
+// Query is a parsed search such as
+//
+//	agent:backend status:failed,stopped prio:high+ "login form" -flaky
+//
+// Every term must match. A bare word or a quoted phrase matches the
+// description, agent, status, priority or dates, ignoring case. Fields take
+// values separated by commas, any of which may match:
+//
+//	agent:NAME     assigned to NAME (agent:none for unassigned)
+//	status:S       pending, in_progress (running), pending_approval
+//	               (approval), completed (done), failed or stopped
+//	prio:P         critical, high, normal or low; high+ is high or above
+//	id:N or #N     the task N
+//	dep:N          tasks that depend on N
+//	after:DATE     changed on or after DATE (YYYY-MM-DD)
+//	before:DATE    changed before DATE
+//
+// A leading - negates a term.
+type Query struct {
+	Text  string
+	terms []queryTerm
+}
+
+type queryTerm struct {
+	field  string // "" for text
+	values []string
+	saved  *Query // a saved search used as @name; field and values are unset
+	negate bool
+}
+
+// ParseQuery parses a search query; an empty text matches every task
+func ParseQuery(text string) (Query, error) {
+	return parseQuery(text, nil)
+}
+
+// parseQuery parses text, looking up @name words with saved when it is set
+func parseQuery(text string, saved func(name string) (*Query, error)) (Query, error) {
+	q := Query{Text: strings.TrimSpace(text)}
+	tokens, err := splitQuery(q.Text)
+	if err != nil {
+		return Query{}, err
+	}
+	for _, tok := range tokens {
+		if name, ok := strings.CutPrefix(tok.text, "@"); ok && name != "" && !tok.phrase && saved != nil {
+			sub, err := saved(name)
+			if err != nil {
+				return Query{}, err
+			}
+			q.terms = append(q.terms, queryTerm{saved: sub, negate: tok.negate})
+			continue
+		}
+		term, err := parseTerm(tok)
+		if err != nil {
+			return Query{}, err
+		}
+		q.terms = append(q.terms, term)
+	}
+	return q, nil
+}
+
+// queryToken is a word of a query; phrase is set when it was quoted
+type queryToken struct {
+	text   string
+	phrase bool
+	negate bool
+}
+
+// splitQuery splits text at spaces outside double quotes
+func splitQuery(text string) ([]queryToken, error) {
+	var tokens []queryToken
+	var cur strings.Builder
+	var tok queryToken
+	inQuote, started := false, false
+	flush := func() {
+		if started {
+			tok.text = cur.String()
+			tokens = append(tokens, tok)
+		}
+		cur.Reset()
+		tok, started = queryToken{}, false
+	}
+	for _, r := range text {
+		switch {
+		case r == '"':
+			if !inQuote && !started {
+				tok.phrase = true
+			}
+			inQuote, started = !inQuote, true
+		case r == '-' && !started && !tok.negate:
+			tok.negate = true
+		case (r == ' ' || r == '\t') && !inQuote:
+			if tok.negate && !started {
+				// a lone - is a word of its own
+				cur.WriteRune('-')
+				tok.negate, started = false, true
+			}
+			flush()
+		default:
+			cur.WriteRune(r)
+			started = true
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidQuery)
+	}
+	if tok.negate && !started {
+		cur.WriteRune('-')
+		tok.negate, started = false, true
+	}
+	flush()
+	return tokens, nil
+}
+
+func parseTerm(tok queryToken) (queryTerm, error) {
+	term := queryTerm{negate: tok.negate}
+	text := tok.text
+	if !tok.phrase {
+		if id, ok := strings.CutPrefix(text, "#"); ok && id != "" {
+			text = "id:" + id
+		}
+		if field, value, ok := strings.Cut(text, ":"); ok {
+			field = strings.ToLower(field)
+			if field == "priority" {
+				field = "prio"
+			}
+			known := false
+			for _, f := range queryFields {
+				known = known || f == field
+			}
+			if !known {
+				return term, fmt.Errorf("%w: unknown field %q (want %s)", ErrInvalidQuery, field, strings.Join(queryFields, ", "))
+			}
+			term.field = field
+			text = value
+		}
+	}
+	if term.field == "" {
+		term.values = []string{strings.ToLower(text)}
+		return term, nil
+	}
+	for _, v := range strings.Split(strings.ToLower(text), ",") {
+		if v == "" {
+			continue
+		}
+		v, err := checkValue(term.field, v)
+		if err != nil {
+			return term, err
+		}
+		term.values = append(term.values, v)
+	}
+	if len(term.values) == 0 {
+		return term, fmt.Errorf("%w: %s: needs a value", ErrInvalidQuery, term.field)
+	}
+	return term, nil
+}
+
+// checkValue validates and normalizes the value of a field
+func checkValue(field, v string) (string, error) {
+	switch field {
+	case "status":
+		if s, ok := statusAliases[v]; ok {
+			return s, nil
+		}
+		for _, s := range allStatuses {
+			if v == s {
+				return v, nil
+			}
+		}
+		return "", fmt.Errorf("%w: unknown status %q", ErrInvalidQuery, v)
+	case "prio":
+		if !ValidPriority(strings.TrimSuffix(v, "+")) {
+			return "", fmt.Errorf("%w: unknown priority %q (want %s)", ErrInvalidQuery, v, strings.Join(Priorities, ", "))
+		}
+	case "id", "dep":
+		if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+			return "", fmt.Errorf("%w: %s: invalid task ID %q", ErrInvalidQuery, field, v)
+		}
+	case "after", "before":
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			return "", fmt.Errorf("%w: %s: want a date like 2026-01-31, got %q", ErrInvalidQuery, field, v)
+		}
+	}
+	return v, nil
+}
+
+// Empty reports whether the query matches every task
+func (q Query) Empty() bool {
+	return len(q.terms) == 0
+}
+
+// Words returns the words and phrases the query looks for in the text of a
+// task, for highlighting
+func (q Query) Words() []string {
+	var words []string
+	for _, term := range q.terms {
+		switch {
+		case term.negate:
+		case term.saved != nil:
+			words = append(words, term.saved.Words()...)
+		case term.field == "":
+			words = append(words, term.values...)
+		}
+	}
+	return words
+}
+
+// Match reports whether t matches every term of the query
+func (q Query) Match(t Task) bool {
+	for _, term := range q.terms {
+		if term.match(t) == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (term queryTerm) match(t Task) bool {
+	if term.saved != nil {
+		return term.saved.Match(t)
+	}
+	for _, v := range term.values {
+		if term.matchValue(t, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (term queryTerm) matchValue(t Task, v string) bool {
+	switch term.field {
+	case "":
+		text := strings.ToLower(strings.Join([]string{
+			t.Description, t.Agent, t.Status, t.Priority, t.CreatedAt, t.UpdatedAt, t.CompletedAt,
+		}, "\n"))
+		return strings.Contains(text, v)
+	case "agent":
+		return strings.EqualFold(t.Agent, v) || v == "none" && t.Agent == ""
+	case "status":
+		return t.Status == v
+	case "prio":
+		if p, ok := strings.CutSuffix(v, "+"); ok {
+			return PriorityRank(t.Priority) <= PriorityRank(p)
+		}
+		return PriorityRank(t.Priority) == PriorityRank(v)
+	case "id":
+		return strconv.Itoa(t.ID) == v
+	case "dep":
+		for _, dep := range t.Dependencies {
+			if strconv.Itoa(dep) == v {
+				return true
+			}
+		}
+	case "after", "before":
+		day, _ := time.ParseInLocation("2006-01-02", v, time.Local)
+		at := lastChanged(t)
+		if at.IsZero() {
+			return false
+		}
+		if term.field == "after" {
+			return !at.Before(day)
+		}
+		return at.Before(day)
+	}
+	return false
+}
+
+// lastChanged returns when the task last changed; zero when unknown
+func lastChanged(t Task) time.Time {
+	for _, ts := range []string{t.UpdatedAt, t.CompletedAt, t.CreatedAt} {
+		if at, err := time.Parse(time.RFC3339, ts); err == nil {
+			return at
+		}
+	}
+	return time.Time{}
+}
+
+// SavedSearch is a named query, used as @name in other queries
+type SavedSearch struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// savedSearchesFile is the content of .claude/searches.json
+type savedSearchesFile struct {
+	Searches []SavedSearch `json:"searches"`
+}
+
+// SavedSearchesPath returns .claude/searches.json
+func (p *Project) SavedSearchesPath() string {
+	return p.Path("searches.json")
+}
+
+// LoadSavedSearches reads the saved searches; a missing file has none
+func (p *Project) LoadSavedSearches() ([]SavedSearch, error) {
+	b, err := os.ReadFile(p.SavedSearchesPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read searches.json: %w", err)
+	}
+	var file savedSearchesFile
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse searches.json: %w", err)
+	}
+	return file.Searches, nil
+}
+
+// SaveSearch saves query under name, replacing a search of the same name
+func (p *Project) SaveSearch(name, query string) ([]SavedSearch, error) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+	if name == "" || strings.ContainsAny(name, " \t\":") {
+		return nil, fmt.Errorf("%w: invalid search name %q", ErrInvalidQuery, name)
+	}
+	if _, err := ParseQuery(query); err != nil {
+		return nil, err
+	}
+	return p.updateSavedSearches(func(saved []SavedSearch) ([]SavedSearch, error) {
+		for i := range saved {
+			if saved[i].Name == name {
+				saved[i].Query = query
+				return saved, nil
+			}
+		}
+		return append(saved, SavedSearch{Name: name, Query: query}), nil
+	})
+}
+
+// DeleteSearch removes the saved search name
+func (p *Project) DeleteSearch(name string) ([]SavedSearch, error) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+	return p.updateSavedSearches(func(saved []SavedSearch) ([]SavedSearch, error) {
+		for i := range saved {
+			if saved[i].Name == name {
+				return append(saved[:i], saved[i+1:]...), nil
+			}
+		}
+		return nil, fmt.Errorf("%w: @%s", ErrSearchNotFound, name)
+	})
+}
+
+func (p *Project) updateSavedSearches(fn func([]SavedSearch) ([]SavedSearch, error)) ([]SavedSearch, error) {
+	lock, err := LockFile(p.SavedSearchesPath())
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	saved, err := p.LoadSavedSearches()
+	if err != nil {
+		return nil, err
+	}
+	if saved, err = fn(saved); err != nil {
+		return nil, err
+	}
+	if saved == nil {
+		saved = []SavedSearch{}
+	}
+	out, err := json.MarshalIndent(savedSearchesFile{Searches: saved}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := WriteFileAtomic(p.SavedSearchesPath(), append(out, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write searches.json: %w", err)
+	}
+	return saved, nil
+}
+
+// ParseSearch parses text where each @name outside quotes stands for the
+// saved search of that name, as a whole: -@name excludes what it matches.
+// Saved searches are not expanded inside saved searches.
+func ParseSearch(text string, saved []SavedSearch) (Query, error) {
+	return parseQuery(text, func(name string) (*Query, error) {
+		for _, s := range saved {
+			if s.Name == name {
+				q, err := ParseQuery(s.Query)
+				if err != nil {
+					return nil, fmt.Errorf("@%s: %w", name, err)
+				}
+				return &q, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: @%s", ErrSearchNotFound, name)
+	})
+}
+
+// Search parses text with the saved searches of this project
+func (p *Project) Search(text string) (Query, error) {
+	saved, err := p.LoadSavedSearches()
+	if err != nil {
+		return Query{}, err
+	}
+	return ParseSearch(text, saved)
+}
+
+// SavedSearchesMsg carries the saved searches of the current project
+type SavedSearchesMsg struct {
+	Searches []SavedSearch
+	Err      error
+}
+
+// LoadSavedSearchesCmd reads the saved searches of the current project
+func LoadSavedSearchesCmd() tea.Cmd {
+	return func() tea.Msg {
+		p, err := CurrentProject()
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		saved, err := p.LoadSavedSearches()
+		return SavedSearchesMsg{Searches: saved, Err: err}
+	}
+}
+
+// SaveSearchCmd saves a query under name; an empty query deletes the search
+func SaveSearchCmd(name, query string) tea.Cmd {
+	return func() tea.Msg {
+		p, err := CurrentProject()
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		var saved []SavedSearch
+		if strings.TrimSpace(query) == "" {
+			saved, err = p.DeleteSearch(name)
+		} else {
+			saved, err = p.SaveSearch(name, query)
+		}
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		return SavedSearchesMsg{Searches: saved}
+	}
+}
@@ -0,0 +1,116 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestQueryMatch(t *testing.T) {
+	tasks := []Task{
+		{ID: 1, Description: "Login form", Status: "failed", Agent: "frontend", Priority: "high", UpdatedAt: "2026-01-03T10:00:00Z"},
+		{ID: 2, Description: "Login API", Status: "in_progress", Agent: "backend", Priority: "critical", UpdatedAt: "2026-01-05T10:00:00Z"},
+		{ID: 3, Description: "Flaky login test", Status: "failed", Agent: "backend", Priority: "low", Dependencies: []int{2}, UpdatedAt: "2026-01-06T10:00:00Z"},
+		{ID: 4, Description: "Write docs", Status: "pending", Priority: "normal"},
+	}
+	cases := []struct {
+		query string
+		want  []int
+	}{
+		{"", []int{1, 2, 3, 4}},
+		{"login", []int{1, 2, 3}},
+		{`"login form"`, []int{1}},
+		{"agent:backend status:failed", []int{3}},
+		{"AGENT:Backend,frontend -flaky", []int{1, 2}},
+		{"status:running", []int{2}},
+		{"prio:high+", []int{1, 2}},
+		{"priority:low", []int{3}},
+		{"agent:none", []int{4}},
+		{"#2", []int{2}},
+		{"id:1,4", []int{1, 4}},
+		{"dep:2", []int{3}},
+		{"-status:failed", []int{2, 4}},
+		{`-"login api" login`, []int{1, 3}},
+		{"after:2026-01-05 before:2026-01-06", []int{2}},
+		{"log-in", nil},
+		{"-", []int{1, 2, 3}}, // a lone - is a word, found in the dates
+	}
+	for _, c := range cases {
+		q, err := ParseQuery(c.query)
+		if err != nil {
+			t.Errorf("%q: %v", c.query, err)
+			continue
+		}
+		var got []int
+		for _, task := range tasks {
+			if q.Match(task) {
+				got = append(got, task.ID)
+			}
+		}
+		if FormatIDs(got) != FormatIDs(c.want) {
+			t.Errorf("%q: got %v, want %v", c.query, got, c.want)
+		}
+	}
+
+	q, _ := ParseQuery(`agent:backend login "api key" -flaky`)
+	if words := q.Words(); len(words) != 2 || words[0] != "login" || words[1] != "api key" {
+		t.Errorf("unexpected words %q", words)
+	}
+	for _, bad := range []string{"owner:me", "status:broken", "prio:urgent", "id:x", "after:yesterday", `"open`, "agent:"} {
+		if _, err := ParseQuery(bad); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%q: expected ErrInvalidQuery, got %v", bad, err)
+		}
+	}
+}
+
+func TestSavedSearches(t *testing.T) {
+	p := &Project{Root: t.TempDir()}
+	if err := os.MkdirAll(p.ClaudeDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if saved, err := p.LoadSavedSearches(); err != nil || len(saved) != 0 {
+		t.Fatalf("expected no saved searches, got %+v (%v)", saved, err)
+	}
+	if _, err := p.SaveSearch("triage", "status:failed"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.SaveSearch("@triage", "status:failed,stopped"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.SaveSearch("bad name", "x"); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("expected an invalid name to be refused, got %v", err)
+	}
+	if _, err := p.SaveSearch("broken", "status:nope"); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("expected an invalid query to be refused, got %v", err)
+	}
+	saved, err := p.SaveSearch("mine", "agent:backend")
+	if err != nil || len(saved) != 2 || saved[0].Query != "status:failed,stopped" {
+		t.Fatalf("expected triage replaced and mine added, got %+v (%v)", saved, err)
+	}
+
+	q, err := p.Search("@triage @mine")
+	if err != nil || q.Text != "@triage @mine" {
+		t.Fatalf("unexpected search: %+v %v", q, err)
+	}
+	if !q.Match(Task{Status: "stopped", Agent: "backend"}) || q.Match(Task{Status: "stopped", Agent: "frontend"}) {
+		t.Errorf("expected both saved searches applied")
+	}
+	// only whole words outside quotes are saved searches; -@name excludes one
+	q, err = p.Search(`"mail  @mine" -@triage`)
+	if err != nil || len(q.Words()) != 1 || q.Words()[0] != "mail  @mine" {
+		t.Fatalf("expected the phrase kept as is, got %q %v", q.Words(), err)
+	}
+	if !q.Match(Task{Description: "Mail  @mine", Status: "pending"}) || q.Match(Task{Description: "mail  @mine", Status: "failed"}) {
+		t.Errorf("expected -@triage to exclude what the saved search matches")
+	}
+	if _, err := p.Search("@nope"); !errors.Is(err, ErrSearchNotFound) {
+		t.Errorf("expected ErrSearchNotFound, got %v", err)
+	}
+
+	if saved, err := p.DeleteSearch("mine"); err != nil || len(saved) != 1 {
+		t.Errorf("unexpected delete: %+v %v", saved, err)
+	}
+	if _, err := p.DeleteSearch("mine"); !errors.Is(err, ErrSearchNotFound) {
+		t.Errorf("expected ErrSearchNotFound, got %v", err)
+	}
+}
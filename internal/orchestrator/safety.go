@@ -0,0 +1,288 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Confirmation levels of a SafetyPolicy, from the weakest
+const (
+	ConfirmNone  = "none"  // run right away
+	ConfirmYes   = "yes"   // answer y/N (CLI: --yes)
+	ConfirmTyped = "typed" // type the task ID (CLI: --confirm ID)
+	ConfirmDeny  = "deny"  // refused
+)
+
+// Actions covered by a SafetyPolicy
+const (
+	ActionStart    = "start"
+	ActionStop     = "stop"
+	ActionComplete = "complete"
+	ActionRemove   = "remove"
+	ActionEdit     = "edit"
+	ActionAssign   = "assign"
+	ActionPriority = "priority"
+)
+
+var (
+	// ErrConfirmationRequired is returned when an action needs a confirmation
+	// that was not given
+	ErrConfirmationRequired = errors.New("confirmation required")
+	// ErrProtected is returned when the safety policy refuses an action
+	ErrProtected = errors.New("refused by the safety policy")
+)
+
+var confirmRank = map[string]int{ConfirmNone: 0, ConfirmYes: 1, ConfirmTyped: 2, ConfirmDeny: 3}
+
+var safetyActions = []string{ActionStart, ActionStop, ActionComplete, ActionRemove, ActionEdit, ActionAssign, ActionPriority}
+
+// SafetyPolicy is .claude/safety-policy.json:
+//
+//	{
+//	  "actions": {"remove": "yes", "stop": "yes", "complete": "none"},
+//	  "running_agent": "typed",
+//	  "dependents": "typed",
+//	  "pending_approval": "deny"
+//	}
+//
+// Actions sets the confirmation of each action. Removing a task whose agent
+// is working on it needs at least RunningAgent, removing a task unfinished
+// tasks depend on needs at least Dependents, and any action on a task in
+// pending_approval needs at least PendingApproval.
+type SafetyPolicy struct {
+	Actions         map[string]string `json:"actions"`
+	RunningAgent    string            `json:"running_agent"`
+	Dependents      string            `json:"dependents"`
+	PendingApproval string            `json:"pending_approval"`
+}
+
+// DefaultSafetyPolicy asks before removing and stopping, asks for the ID
+// before removing a task with a running agent or dependents, and protects
+// tasks waiting for approval
+func DefaultSafetyPolicy() SafetyPolicy {
+	return SafetyPolicy{
+		Actions:         map[string]string{ActionRemove: ConfirmYes, ActionStop: ConfirmYes},
+		RunningAgent:    ConfirmTyped,
+		Dependents:      ConfirmTyped,
+		PendingApproval: ConfirmDeny,
+	}
+}
+
+// SafetyPolicyPath returns .claude/safety-policy.json
+func (p *Project) SafetyPolicyPath() string {
+	return p.Path("safety-policy.json")
+}
+
+// LoadSafetyPolicy reads the safety policy; fields and actions left out
+// keep their defaults and a missing file is the default policy
+func (p *Project) LoadSafetyPolicy() (SafetyPolicy, error) {
+	pol := DefaultSafetyPolicy()
+	b, err := os.ReadFile(p.SafetyPolicyPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return pol, nil
+		}
+		return pol, fmt.Errorf("failed to read safety-policy.json: %w", err)
+	}
+	if err := json.Unmarshal(b, &pol); err != nil {
+		return DefaultSafetyPolicy(), fmt.Errorf("failed to parse safety-policy.json: %w", err)
+	}
+	if err := pol.validate(); err != nil {
+		return DefaultSafetyPolicy(), fmt.Errorf("safety-policy.json: %w", err)
+	}
+	return pol, nil
+}
+
+func (pol SafetyPolicy) validate() error {
+	for action, level := range pol.Actions {
+		known := false
+		for _, a := range safetyActions {
+			known = known || a == action
+		}
+		if !known {
+			return fmt.Errorf("unknown action %q (want %s)", action, strings.Join(safetyActions, ", "))
+		}
+		if _, ok := confirmRank[level]; !ok {
+			return fmt.Errorf("actions.%s: invalid level %q (want none, yes, typed or deny)", action, level)
+		}
+	}
+	for name, level := range map[string]string{"running_agent": pol.RunningAgent, "dependents": pol.Dependents, "pending_approval": pol.PendingApproval} {
+		if _, ok := confirmRank[level]; !ok {
+			return fmt.Errorf("%s: invalid level %q (want none, yes, typed or deny)", name, level)
+		}
+	}
+	return nil
+}
+
+// Guard is what the safety policy asks before an action on some tasks
+type Guard struct {
+	Action  string
+	Level   string
+	Reasons []string // why the level is above the action's own
+	Phrase  string   // text to type for ConfirmTyped
+}
+
+// Check returns the confirmation action needs on the tasks ids. running
+// reports whether an agent process is alive.
+func (pol SafetyPolicy) Check(action string, tasks []Task, ids []int, running func(agent string) bool) Guard {
+	g := Guard{Action: action, Level: pol.Actions[action]}
+	if g.Level == "" {
+		g.Level = ConfirmNone
+	}
+	raise := func(level, reason string) {
+		if confirmRank[level] > confirmRank[ConfirmNone] {
+			g.Reasons = append(g.Reasons, reason)
+		}
+		if confirmRank[level] > confirmRank[g.Level] {
+			g.Level = level
+		}
+	}
+	for _, id := range ids {
+		t := findTask(tasks, id)
+		if t == nil {
+			continue
+		}
+		if t.Status == StatusPendingApproval {
+			raise(pol.PendingApproval, fmt.Sprintf("#%d waits for approval", id))
+		}
+		if action != ActionRemove {
+			continue
+		}
+		if t.Status == StatusInProgress && t.Agent != "" && running != nil && running(t.Agent) {
+			raise(pol.RunningAgent, fmt.Sprintf("agent %s is working on #%d", t.Agent, id))
+		}
+		if deps := dependents(tasks, id); len(deps) > 0 {
+			raise(pol.Dependents, fmt.Sprintf("%s depend on #%d", FormatIDs(deps), id))
+		}
+	}
+	g.Phrase = action
+	if len(ids) == 1 {
+		g.Phrase = strconv.Itoa(ids[0])
+	}
+	return g
+}
+
+// dependents returns the unfinished tasks that depend on id
+func dependents(tasks []Task, id int) []int {
+	var ids []int
+	for _, t := range tasks {
+		if t.Status == StatusCompleted {
+			continue
+		}
+		for _, dep := range t.Dependencies {
+			if dep == id {
+				ids = append(ids, t.ID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// AtLeast raises the guard to level when it asks for less
+func (g Guard) AtLeast(level string) Guard {
+	if confirmRank[level] > confirmRank[g.Level] {
+		g.Level = level
+	}
+	return g
+}
+
+// Err returns the refusal of a denied action, or nil
+func (g Guard) Err() error {
+	if g.Level != ConfirmDeny {
+		return nil
+	}
+	if len(g.Reasons) == 0 {
+		return fmt.Errorf("%w: %s is disabled", ErrProtected, g.Action)
+	}
+	return fmt.Errorf("%w: %s", ErrProtected, strings.Join(g.Reasons, "; "))
+}
+
+// Confirmed checks the confirmation given on a command line: yes for
+// ConfirmYes, typed equal to Phrase for either
+func (g Guard) Confirmed(yes bool, typed string) error {
+	if err := g.Err(); err != nil {
+		return err
+	}
+	switch {
+	case g.satisfied(yes, typed):
+		return nil
+	case g.Level == ConfirmTyped:
+		return fmt.Errorf("%w: %s%s; pass --confirm %s", ErrConfirmationRequired, g.Action, g.why(), g.Phrase)
+	default:
+		return fmt.Errorf("%w: %s%s; pass --yes", ErrConfirmationRequired, g.Action, g.why())
+	}
+}
+
+// ConfirmedParam checks the confirm parameter of an API request: any value
+// for ConfirmYes, Phrase for ConfirmTyped
+func (g Guard) ConfirmedParam(confirm string) error {
+	if err := g.Err(); err != nil {
+		return err
+	}
+	if g.satisfied(confirm != "", confirm) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s%s; repeat with ?confirm=%s", ErrConfirmationRequired, g.Action, g.why(), g.Phrase)
+}
+
+// satisfied reports whether the confirmation given is enough; a denied
+// action never is
+func (g Guard) satisfied(yes bool, typed string) bool {
+	switch g.Level {
+	case ConfirmDeny:
+		return false
+	case ConfirmTyped:
+		return typed == g.Phrase
+	case ConfirmYes:
+		return yes || typed == g.Phrase
+	}
+	return true
+}
+
+func (g Guard) why() string {
+	if len(g.Reasons) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(g.Reasons, "; ") + ")"
+}
+
+// CheckSafety loads the safety policy and the tasks and checks action on ids
+func (p *Project) CheckSafety(action string, ids []int) (Guard, error) {
+	pol, err := p.LoadSafetyPolicy()
+	if err != nil {
+		return Guard{}, err
+	}
+	data, err := p.Store().Load()
+	if err != nil {
+		return Guard{}, err
+	}
+	return pol.Check(action, data.Tasks, ids, func(agent string) bool {
+		_, alive := p.AgentPID(agent)
+		return alive
+	}), nil
+}
+
+// SafetyPolicyMsg carries the safety policy of the current project
+type SafetyPolicyMsg struct {
+	Policy SafetyPolicy
+	Err    error // the default policy is used when the file is invalid
+}
+
+// LoadSafetyPolicyCmd reads the safety policy of the current project
+func LoadSafetyPolicyCmd() tea.Cmd {
+	return func() tea.Msg {
+		p, err := CurrentProject()
+		if err != nil {
+			return ErrorMsg(err)
+		}
+		pol, err := p.LoadSafetyPolicy()
+		return SafetyPolicyMsg{Policy: pol, Err: err}
+	}
+}
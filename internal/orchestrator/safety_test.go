@@ -0,0 +1,93 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestSafetyPolicyCheck(t *testing.T) {
+	tasks := []Task{
+		{ID: 1, Status: StatusInProgress, Agent: "backend"},
+		{ID: 2, Status: StatusPending, Dependencies: []int{1}},
+		{ID: 3, Status: StatusPendingApproval, Agent: "frontend"},
+		{ID: 4, Status: StatusPending},
+		{ID: 5, Status: StatusCompleted, Dependencies: []int{4}},
+	}
+	running := func(agent string) bool { return agent == "backend" }
+	pol := DefaultSafetyPolicy()
+
+	tests := []struct {
+		action  string
+		ids     []int
+		level   string
+		reasons int
+	}{
+		{ActionStart, []int{4}, ConfirmNone, 0},
+		{ActionStop, []int{1}, ConfirmYes, 0},
+		{ActionRemove, []int{4}, ConfirmYes, 0}, // its only dependent is done
+		{ActionRemove, []int{2}, ConfirmYes, 0},
+		{ActionRemove, []int{1}, ConfirmTyped, 2}, // running agent and a dependent
+		{ActionEdit, []int{3}, ConfirmDeny, 1},
+		{ActionRemove, []int{3, 4}, ConfirmDeny, 1},
+	}
+	for _, tt := range tests {
+		g := pol.Check(tt.action, tasks, tt.ids, running)
+		if g.Level != tt.level || len(g.Reasons) != tt.reasons {
+			t.Errorf("%s %v: expected %s with %d reason(s), got %+v", tt.action, tt.ids, tt.level, tt.reasons, g)
+		}
+	}
+
+	g := pol.Check(ActionRemove, tasks, []int{1}, running)
+	if g.Phrase != "1" {
+		t.Errorf("expected the ID as phrase, got %q", g.Phrase)
+	}
+	if err := g.Confirmed(true, ""); !errors.Is(err, ErrConfirmationRequired) {
+		t.Errorf("expected --yes to be too weak, got %v", err)
+	}
+	if err := g.Confirmed(false, "1"); err != nil {
+		t.Errorf("expected the typed ID to confirm, got %v", err)
+	}
+	if err := pol.Check(ActionStop, tasks, []int{1}, running).Confirmed(false, ""); !errors.Is(err, ErrConfirmationRequired) {
+		t.Errorf("expected stop to need --yes, got %v", err)
+	}
+	if err := pol.Check(ActionEdit, tasks, []int{3}, running).Confirmed(true, "3"); !errors.Is(err, ErrProtected) {
+		t.Errorf("expected a protected task, got %v", err)
+	}
+	if g := pol.Check(ActionRemove, tasks, []int{2, 4}, running); g.Phrase != ActionRemove {
+		t.Errorf("expected the action as phrase for several tasks, got %q", g.Phrase)
+	}
+	if g := pol.Check(ActionStart, tasks, []int{4}, running).AtLeast(ConfirmYes); g.Level != ConfirmYes {
+		t.Errorf("expected AtLeast to raise the level, got %s", g.Level)
+	}
+}
+
+func TestLoadSafetyPolicy(t *testing.T) {
+	p := &Project{Root: t.TempDir()}
+	pol, err := p.LoadSafetyPolicy()
+	if err != nil || pol.Actions[ActionRemove] != ConfirmYes || pol.PendingApproval != ConfirmDeny {
+		t.Fatalf("expected the default policy without a file, got %+v (%v)", pol, err)
+	}
+
+	if err := os.MkdirAll(p.ClaudeDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(body string) {
+		t.Helper()
+		if err := os.WriteFile(p.SafetyPolicyPath(), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{"actions": {"complete": "typed"}, "pending_approval": "typed"}`)
+	pol, err = p.LoadSafetyPolicy()
+	if err != nil || pol.Actions[ActionComplete] != ConfirmTyped || pol.Actions[ActionRemove] != ConfirmYes || pol.PendingApproval != ConfirmTyped || pol.Dependents != ConfirmTyped {
+		t.Errorf("expected the file merged over the defaults, got %+v (%v)", pol, err)
+	}
+
+	for _, body := range []string{`{"actions": {"remove": "maybe"}}`, `{"actions": {"delete": "yes"}}`, `{"dependents": ""}`, `{`} {
+		write(body)
+		if pol, err := p.LoadSafetyPolicy(); err == nil || pol.Actions[ActionStop] != ConfirmYes {
+			t.Errorf("%s: expected an error and the default policy, got %+v (%v)", body, pol, err)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Task status values stored in tasks.json
+const (
+	StatusPending    = "pending"
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+	StatusStopped    = "stopped"
+
+	// StatusPendingApproval is set while a task waits on approval requests
+	StatusPendingApproval = "pending_approval"
+)
+
+var (
+	// ErrTaskNotFound is returned when no task has the requested ID
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrInvalidTransition is returned when a task cannot move to the requested status
+	ErrInvalidTransition = errors.New("invalid status transition")
+)
+
+// allowedFrom lists the statuses each transition may start from
+var allowedFrom = map[string][]string{
+	StatusInProgress: {StatusPending, StatusStopped, StatusFailed},
+	StatusCompleted:  {StatusPending, StatusInProgress, StatusStopped, StatusFailed},
+	StatusStopped:    {StatusPending, StatusInProgress, StatusPendingApproval},
+}
+
+// TaskStore provides typed load/save/transition operations over tasks.json
+type TaskStore struct {
+	path string
+	now  func() time.Time
+}
+
+// NewTaskStore returns a store backed by the tasks.json at path
+func NewTaskStore(path string) *TaskStore {
+	return &TaskStore{path: path, now: time.Now}
+}
+
+// Path returns the tasks.json path this store operates on
+func (s *TaskStore) Path() string {
+	return s.path
+}
+
+// Load reads tasks.json. A missing file is treated as an empty task list.
+// Writers replace the file atomically, so reads do not need the lock.
+func (s *TaskStore) Load() (*TasksData, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &TasksData{SchemaVersion: CurrentSchemaVersion, Tasks: []Task{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read tasks.json: %w", err)
+	}
+
+	var tasksData TasksData
+	if err := json.Unmarshal(data, &tasksData); err != nil {
+		return nil, fmt.Errorf("failed to parse tasks.json: %w", err)
+	}
+	if tasksData.Tasks == nil {
+		tasksData.Tasks = []Task{}
+	}
+	migrateTasksData(&tasksData)
+	return &tasksData, nil
+}
+
+// Save writes data back to tasks.json while holding the tasks.json lock
+func (s *TaskStore) Save(data *TasksData) error {
+	lock, err := LockFile(s.path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	return s.save(data)
+}
+
+// Update locks tasks.json, loads it, applies fn and saves the result before
+// releasing the lock. Nothing is written when fn returns an error.
+func (s *TaskStore) Update(fn func(data *TasksData) error) error {
+	lock, err := LockFile(s.path)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	data, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(data); err != nil {
+		return err
+	}
+	return s.save(data)
+}
+
+func (s *TaskStore) save(data *TasksData) error {
+	if data.SchemaVersion > CurrentSchemaVersion {
+		return fmt.Errorf("refusing to write tasks.json: %w (version %d, supported %d)", ErrSchemaTooNew, data.SchemaVersion, CurrentSchemaVersion)
+	}
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal tasks: %w", err)
+	}
+	out = append(out, '\n')
+	if err := WriteFileAtomic(s.path, out, 0644); err != nil {
+		return fmt.Errorf("failed to write tasks.json: %w", err)
+	}
+	return nil
+}
+
+// Get returns the task with the given ID
+func (s *TaskStore) Get(id int) (Task, error) {
+	data, err := s.Load()
+	if err != nil {
+		return Task{}, err
+	}
+	t := data.Find(id)
+	if t == nil {
+		return Task{}, fmt.Errorf("%w: #%d", ErrTaskNotFound, id)
+	}
+	return *t, nil
+}
+
+// Add appends a new pending task and returns it
+func (s *TaskStore) Add(description, agent, priority string) (Task, error) {
+	return s.AddTask(Task{Description: description, Agent: agent, Priority: priority})
+}
+
+// AddTask appends t as a new pending task, assigning its ID and timestamps.
+// Dependencies must refer to existing tasks.
+func (s *TaskStore) AddTask(t Task) (Task, error) {
+	if t.Priority == "" {
+		t.Priority = "normal"
+	}
+	if t.Dependencies == nil {
+		t.Dependencies = []int{}
+	}
+	now := s.timestamp()
+	err := s.Update(func(data *TasksData) error {
+		if err := CheckDependencies(data.Tasks, 0, t.Dependencies); err != nil {
+			return err
+		}
+		data.LastID++
+		t.ID = data.LastID
+		t.Status = StatusPending
+		t.CreatedAt = now
+		t.UpdatedAt = now
+		data.Tasks = append(data.Tasks, t)
+		return nil
+	})
+	return t, err
+}
+
+// Start moves a task to in_progress. It fails with ErrBlocked while any
+// dependency is not completed.
+func (s *TaskStore) Start(id int) (Task, error) {
+	return s.transition(id, StatusInProgress, func(data *TasksData) error {
+		if blockers := NewGraph(data.Tasks).Blockers(id); len(blockers) > 0 {
+			return fmt.Errorf("%w: task #%d waits for %s", ErrBlocked, id, FormatIDs(blockers))
+		}
+		return nil
+	})
+}
+
+// ForceStart moves a task to in_progress regardless of its dependencies
+func (s *TaskStore) ForceStart(id int) (Task, error) {
+	return s.transition(id, StatusInProgress, nil)
+}
+
+// Complete moves a task to completed
+func (s *TaskStore) Complete(id int) (Task, error) {
+	return s.transition(id, StatusCompleted, nil)
+}
+
+// Stop moves a task to stopped
+func (s *TaskStore) Stop(id int) (Task, error) {
+	return s.transition(id, StatusStopped, nil)
+}
+
+// Edit replaces the description of a task
+func (s *TaskStore) Edit(id int, description string) (Task, error) {
+	var edited Task
+	err := s.Update(func(data *TasksData) error {
+		t := data.Find(id)
+		if t == nil {
+			return fmt.Errorf("%w: #%d", ErrTaskNotFound, id)
+		}
+		t.Description = description
+		t.UpdatedAt = s.timestamp()
+		edited = *t
+		return nil
+	})
+	return edited, err
+}
+
+// Replace overwrites the fields of an existing task with t (matched by ID).
+// The status and its timestamps stay as stored, since only transitions
+// change them, and dependencies are validated like SetDependencies.
+func (s *TaskStore) Replace(t Task) (Task, error) {
+	err := s.Update(func(data *TasksData) error {
+		existing := data.Find(t.ID)
+		if existing == nil {
+			return fmt.Errorf("%w: #%d", ErrTaskNotFound, t.ID)
+		}
+		if t.Dependencies == nil {
+			t.Dependencies = []int{}
+		}
+		if err := CheckDependencies(data.Tasks, t.ID, t.Dependencies); err != nil {
+			return err
+		}
+		t.Status = existing.Status
+		t.CreatedAt, t.StartedAt, t.CompletedAt = existing.CreatedAt, existing.StartedAt, existing.CompletedAt
+		t.UpdatedAt = s.timestamp()
+		*existing = t
+		return nil
+	})
+	return t, err
+}
+
+// Modify applies fn to a task under the lock. Changed dependencies are
+// validated like SetDependencies; nothing is written when fn fails.
+func (s *TaskStore) Modify(id int, fn func(t *Task) error) (Task, error) {
+	var modified Task
+	err := s.Update(func(data *TasksData) error {
+		t := data.Find(id)
+		if t == nil {
+			return fmt.Errorf("%w: #%d", ErrTaskNotFound, id)
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+		t.ID = id
+		if t.Dependencies == nil {
+			t.Dependencies = []int{}
+		}
+		if err := CheckDependencies(data.Tasks, id, t.Dependencies); err != nil {
+			return err
+		}
+		t.UpdatedAt = s.timestamp()
+		modified = *t
+		return nil
+	})
+	return modified, err
+}
+
+// Remove deletes a task from tasks.json
+func (s *TaskStore) Remove(id int) error {
+	return s.Update(func(data *TasksData) error {
+		for i := range data.Tasks {
+			if data.Tasks[i].ID == id {
+				data.Tasks = append(data.Tasks[:i], data.Tasks[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: #%d", ErrTaskNotFound, id)
+	})
+}
+
+// SetDependencies replaces the dependencies of a task, refusing unknown IDs and cycles
+func (s *TaskStore) SetDependencies(id int, deps []int) (Task, error) {
+	if deps == nil {
+		deps = []int{}
+	}
+	var updated Task
+	err := s.Update(func(data *TasksData) error {
+		t := data.Find(id)
+		if t == nil {
+			return fmt.Errorf("%w: #%d", ErrTaskNotFound, id)
+		}
+		if err := CheckDependencies(data.Tasks, id, deps); err != nil {
+			return err
+		}
+		t.Dependencies = deps
+		t.UpdatedAt = s.timestamp()
+		updated = *t
+		return nil
+	})
+	return updated, err
+}
+
+// transition changes a task's status; check, when set, can veto the change
+func (s *TaskStore) transition(id int, to string, check func(data *TasksData) error) (Task, error) {
+	var updated Task
+	err := s.Update(func(data *TasksData) error {
+		t := data.Find(id)
+		if t == nil {
+			return fmt.Errorf("%w: #%d", ErrTaskNotFound, id)
+		}
+		if !canTransition(t.Status, to) {
+			return fmt.Errorf("%w: task #%d is %s, cannot move to %s", ErrInvalidTransition, id, t.Status, to)
+		}
+		if check != nil {
+			if err := check(data); err != nil {
+				return err
+			}
+		}
+		now := s.timestamp()
+		t.Status = to
+		t.UpdatedAt = now
+		switch to {
+		case StatusInProgress:
+			t.StartedAt = now
+			t.CompletedAt = ""
+		case StatusCompleted:
+			t.CompletedAt = now
+			t.Progress = 100
+		}
+		updated = *t
+		return nil
+	})
+	return updated, err
+}
+
+func (s *TaskStore) timestamp() string {
+	return s.now().UTC().Format(time.RFC3339)
+}
+
+func canTransition(from, to string) bool {
+	for _, s := range allowedFrom[to] {
+		if s == from {
+			return true
+		}
+	}
+	return false
+}
+
+// Find returns a pointer to the task with the given ID, or nil
+func (d *TasksData) Find(id int) *Task {
+	for i := range d.Tasks {
+		if d.Tasks[i].ID == id {
+			return &d.Tasks[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func newTestStore(t *testing.T, content string) *TaskStore {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	if content != "" {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewTaskStore(path)
+}
+
+func TestTaskStoreLifecycle(t *testing.T) {
+	s := newTestStore(t, `{"tasks": [], "last_id": 0}`)
+
+	added, err := s.Add("Build login form", "frontend", "")
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if added.ID != 1 || added.Status != StatusPending || added.Priority != "normal" {
+		t.Fatalf("unexpected task after Add: %+v", added)
+	}
+
+	if _, err := s.Start(added.ID); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if _, err := s.Start(added.ID); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("expected ErrInvalidTransition when starting twice, got %v", err)
+	}
+	if _, err := s.Stop(added.ID); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if _, err := s.Edit(added.ID, "Build signup form"); err != nil {
+		t.Fatalf("Edit: %v", err)
+	}
+	done, err := s.Complete(added.ID)
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if done.Status != StatusCompleted || done.Description != "Build signup form" {
+		t.Errorf("unexpected task after Complete: %+v", done)
+	}
+
+	if err := s.Remove(added.ID); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := s.Get(added.ID); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound after Remove, got %v", err)
+	}
+
+	// IDs are never reused
+	next, err := s.Add("Another", "", "high")
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if next.ID != 2 {
+		t.Errorf("expected ID 2, got %d", next.ID)
+	}
+}
+
+func TestTaskStoreReplace(t *testing.T) {
+	s := newTestStore(t, `{"tasks": [
+		{"id": 1, "description": "API", "status": "completed", "created_at": "2026-01-01T00:00:00Z", "completed_at": "2026-01-02T00:00:00Z", "dependencies": []},
+		{"id": 2, "description": "UI", "status": "pending", "created_at": "2026-01-01T00:00:00Z", "dependencies": [1]}
+	], "last_id": 2}`)
+
+	if _, err := s.Replace(Task{ID: 1, Description: "API", Dependencies: []int{2}}); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("expected ErrDependencyCycle, got %v", err)
+	}
+	if _, err := s.Replace(Task{ID: 2, Description: "UI", Dependencies: []int{9}}); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound for an unknown dependency, got %v", err)
+	}
+
+	got, err := s.Replace(Task{ID: 1, Description: "Login API", Status: StatusPending, CreatedAt: "2030-01-01T00:00:00Z"})
+	if err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+	if got.Description != "Login API" || got.Status != StatusCompleted ||
+		got.CreatedAt != "2026-01-01T00:00:00Z" || got.CompletedAt != "2026-01-02T00:00:00Z" {
+		t.Errorf("Replace should edit the fields but keep the status and its timestamps, got %+v", got)
+	}
+}
+
+func TestTaskStoreMissingFile(t *testing.T) {
+	s := newTestStore(t, "")
+	data, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(data.Tasks) != 0 {
+		t.Errorf("expected no tasks, got %d", len(data.Tasks))
+	}
+	if _, err := s.Complete(3); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestTaskStoreConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	if err := os.WriteFile(path, []byte(`{"tasks": [], "last_id": 0}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	const writers = 16
+	const perWriter = 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			// Each writer gets its own store, and therefore its own lock fd
+			s := NewTaskStore(path)
+			for i := 0; i < perWriter; i++ {
+				if _, err := s.Add(fmt.Sprintf("writer %d task %d", w, i), "", ""); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Add: %v", err)
+	}
+
+	data, err := NewTaskStore(path).Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(data.Tasks) != writers*perWriter {
+		t.Fatalf("expected %d tasks, got %d (lost updates)", writers*perWriter, len(data.Tasks))
+	}
+	if data.LastID != writers*perWriter {
+		t.Errorf("expected last_id %d, got %d", writers*perWriter, data.LastID)
+	}
+	seen := make(map[int]bool)
+	for _, task := range data.Tasks {
+		if seen[task.ID] {
+			t.Errorf("duplicate task ID %d", task.ID)
+		}
+		seen[task.ID] = true
+	}
+
+	leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".tasks.json.tmp-*"))
+	if len(leftovers) != 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+}
@@ -0,0 +1,473 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Agent liveness reported by the Supervisor
+const (
+	AgentRunning    = "RUNNING"
+	AgentCrashed    = "CRASHED"    // exited on its own; a restart is scheduled
+	AgentRestarting = "RESTARTING" // being started again after a crash
+	AgentStopped    = "STOPPED"    // stopped on request
+	AgentFailed     = "FAILED"     // crashed too often in a row; no more restarts
+)
+
+// ErrAgentRunning is returned when spawning an agent that is already alive
+var ErrAgentRunning = errors.New("agent is already running")
+
+const (
+	defaultBackoffBase = time.Second
+	defaultBackoffMax  = time.Minute
+	defaultMaxRestarts = 5
+	// stableAfter resets the crash counter once an agent stayed up this long
+	stableAfter = 2 * time.Minute
+	// stopGrace is how long Stop waits after SIGTERM before sending SIGKILL
+	stopGrace = 5 * time.Second
+	// adoptedPoll is how often agents we did not start are checked with kill(pid, 0)
+	adoptedPoll = 2 * time.Second
+)
+
+// AgentState describes one supervised agent
+type AgentState struct {
+	Name      string
+	State     string // RUNNING, CRASHED, RESTARTING, STOPPED, FAILED
+	PID       int
+	ExitCode  int // last exit code, -1 when unknown (adopted process or signal)
+	Restarts  int // crashes in a row since the agent was last stable
+	Since     time.Time
+	RestartAt time.Time // when CRASHED, the time of the next restart
+	Err       string
+}
+
+// AgentStateMsg carries a snapshot of every supervised agent
+type AgentStateMsg []AgentState
+
+// Supervisor starts agent processes, waits on them so they never linger as
+// zombies, records exit codes and restarts crashed agents with exponential
+// backoff. Agents run in their own session so they outlive the dashboard.
+type Supervisor struct {
+	project *Project
+
+	// command builds the process for an agent; tests replace it
+	command     func(name string) *exec.Cmd
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	maxRestarts int
+
+	mu      sync.Mutex
+	agents  map[string]*supervised
+	changed chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+type supervised struct {
+	state    AgentState
+	proc     *os.Process
+	started  time.Time
+	stopping bool
+	exited   chan struct{} // closed when the current process is gone
+	timer    *time.Timer   // pending restart
+}
+
+// NewSupervisor returns a Supervisor for the agents of project
+func NewSupervisor(project *Project) *Supervisor {
+	s := &Supervisor{
+		project:     project,
+		backoffBase: defaultBackoffBase,
+		backoffMax:  defaultBackoffMax,
+		maxRestarts: defaultMaxRestarts,
+		agents:      make(map[string]*supervised),
+		changed:     make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}
+	s.command = func(name string) *exec.Cmd {
+		cmd := exec.Command("bash", project.AgentScriptPath(), "watch", name)
+		cmd.Dir = project.Root
+		return cmd
+	}
+	return s
+}
+
+var (
+	supervisorMu     sync.Mutex
+	activeSupervisor *Supervisor
+)
+
+// SetSupervisor makes SpawnAgent go through s; nil restores detached spawning
+func SetSupervisor(s *Supervisor) {
+	supervisorMu.Lock()
+	defer supervisorMu.Unlock()
+	activeSupervisor = s
+}
+
+func currentSupervisor() *Supervisor {
+	supervisorMu.Lock()
+	defer supervisorMu.Unlock()
+	return activeSupervisor
+}
+
+// Changed is signalled whenever an agent changes state. Use States for the details.
+func (s *Supervisor) Changed() <-chan struct{} {
+	return s.changed
+}
+
+// States returns every supervised agent sorted by name
+func (s *Supervisor) States() []AgentState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	states := make([]AgentState, 0, len(s.agents))
+	for _, a := range s.agents {
+		states = append(states, a.state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// State returns the state of one agent
+func (s *Supervisor) State(name string) (AgentState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.agents[name]
+	if !ok {
+		return AgentState{}, false
+	}
+	return a.state, true
+}
+
+// Spawn starts an agent and supervises it
+func (s *Supervisor) Spawn(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a, ok := s.agents[name]; ok && a.alive() {
+		return fmt.Errorf("%w: %s (pid %d)", ErrAgentRunning, name, a.state.PID)
+	}
+	if pid, alive := s.project.AgentPID(name); alive {
+		return fmt.Errorf("%w: %s (pid %d)", ErrAgentRunning, name, pid)
+	}
+	a := s.agents[name]
+	if a == nil {
+		a = &supervised{state: AgentState{Name: name}}
+		s.agents[name] = a
+	}
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+	a.state.Restarts = 0
+	return s.startLocked(a, AgentRunning)
+}
+
+// Adopt supervises every agent whose pid file points at a live process,
+// e.g. agents left running by a previous dashboard. They cannot be waited on,
+// so they are polled; when one disappears it is restarted like a crash.
+func (s *Supervisor) Adopt() {
+	for _, name := range s.project.AgentNames() {
+		pid, alive := s.project.AgentPID(name)
+		if !alive {
+			continue
+		}
+		s.mu.Lock()
+		if _, ok := s.agents[name]; !ok {
+			proc, _ := os.FindProcess(pid)
+			a := &supervised{
+				state:   AgentState{Name: name, State: AgentRunning, PID: pid, ExitCode: -1, Since: time.Now()},
+				proc:    proc,
+				started: time.Now(),
+				exited:  make(chan struct{}),
+			}
+			s.agents[name] = a
+			go s.pollAdopted(a, pid)
+		}
+		s.mu.Unlock()
+	}
+	s.notify()
+}
+
+// Stop terminates an agent (SIGTERM, then SIGKILL after a grace period)
+// and cancels any pending restart
+func (s *Supervisor) Stop(name string) error {
+	s.mu.Lock()
+	a, ok := s.agents[name]
+	if !ok {
+		s.mu.Unlock()
+		return s.stopUnsupervised(name)
+	}
+	a.stopping = true
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+	if !a.alive() {
+		a.setState(AgentStopped)
+		s.mu.Unlock()
+		s.notify()
+		return nil
+	}
+	proc, exited := a.proc, a.exited
+	s.mu.Unlock()
+
+	// Agents run in their own session: signal the whole process group so
+	// the claude processes they started go away as well
+	signalGroup(proc.Pid, syscall.SIGTERM)
+	select {
+	case <-exited:
+	case <-time.After(stopGrace):
+		signalGroup(proc.Pid, syscall.SIGKILL)
+		<-exited
+	}
+	return nil
+}
+
+// Restart stops an agent if it is running and starts it again
+func (s *Supervisor) Restart(name string) error {
+	if err := s.Stop(name); err != nil {
+		return err
+	}
+	return s.Spawn(name)
+}
+
+// Close cancels pending restarts and stops reporting. Running agents keep
+// running, just as they did when the dashboard spawned them detached.
+func (s *Supervisor) Close() {
+	s.once.Do(func() {
+		close(s.done)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, a := range s.agents {
+			if a.timer != nil {
+				a.timer.Stop()
+			}
+		}
+	})
+}
+
+// startLocked starts the agent process; s.mu must be held
+func (s *Supervisor) startLocked(a *supervised, state string) error {
+	cmd := s.command(a.state.Name)
+	// SysProcAttr.Setsid = true により OS レベルで新しいセッションを作成する。
+	// これにより TUI の終了シグナル（SIGINT/SIGTERM/SIGHUP）が
+	// エージェントプロセスに伝播しなくなる。
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	out, err := openAgentLog(s.project, a.state.Name)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	if err := cmd.Start(); err != nil {
+		a.state.Err = err.Error()
+		a.setState(AgentFailed)
+		s.notify()
+		return fmt.Errorf("failed to spawn agent %s: %w", a.state.Name, err)
+	}
+	writePIDFile(s.project, a.state.Name, cmd.Process.Pid)
+
+	a.proc = cmd.Process
+	a.started = time.Now()
+	a.stopping = false
+	a.exited = make(chan struct{})
+	a.state.PID = cmd.Process.Pid
+	a.state.Err = ""
+	a.state.RestartAt = time.Time{}
+	a.setState(state)
+	go s.wait(a, cmd, a.exited)
+	s.notify()
+	return nil
+}
+
+// wait reaps the process and decides whether to restart it
+func (s *Supervisor) wait(a *supervised, cmd *exec.Cmd, exited chan struct{}) {
+	err := cmd.Wait()
+	code := -1
+	if cmd.ProcessState != nil {
+		code = cmd.ProcessState.ExitCode()
+	}
+	s.exited(a, code, err, exited)
+}
+
+// pollAdopted watches a process we did not start
+func (s *Supervisor) pollAdopted(a *supervised, pid int) {
+	ticker := time.NewTicker(adoptedPoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if syscall.Kill(pid, 0) != nil {
+				s.mu.Lock()
+				exited := a.exited
+				s.mu.Unlock()
+				s.exited(a, -1, nil, exited)
+				return
+			}
+		}
+	}
+}
+
+func (s *Supervisor) exited(a *supervised, code int, err error, exited chan struct{}) {
+	s.mu.Lock()
+	defer s.notify()
+	defer s.mu.Unlock()
+	close(exited)
+
+	a.proc = nil
+	a.state.PID = 0
+	a.state.ExitCode = code
+	removePIDFile(s.project, a.state.Name)
+
+	if a.stopping {
+		a.setState(AgentStopped)
+		return
+	}
+	if err != nil {
+		a.state.Err = err.Error()
+	} else {
+		a.state.Err = fmt.Sprintf("exited with code %d", code)
+	}
+	if time.Since(a.started) >= stableAfter {
+		a.state.Restarts = 0
+	}
+	if a.state.Restarts >= s.maxRestarts {
+		a.setState(AgentFailed)
+		return
+	}
+
+	delay := s.backoff(a.state.Restarts)
+	a.state.Restarts++
+	a.state.RestartAt = time.Now().Add(delay)
+	a.setState(AgentCrashed)
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+	a.timer = time.AfterFunc(delay, func() { s.restart(a) })
+}
+
+func (s *Supervisor) restart(a *supervised) {
+	s.mu.Lock()
+	a.timer = nil
+	if a.stopping || a.alive() {
+		s.mu.Unlock()
+		return
+	}
+	a.setState(AgentRestarting)
+	s.mu.Unlock()
+	s.notify()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a.stopping {
+		return
+	}
+	s.startLocked(a, AgentRunning)
+}
+
+// backoff returns base * 2^n capped at max
+func (s *Supervisor) backoff(n int) time.Duration {
+	d := s.backoffBase
+	for i := 0; i < n && d < s.backoffMax; i++ {
+		d *= 2
+	}
+	return min(d, s.backoffMax)
+}
+
+// stopUnsupervised stops an agent started by someone else through its pid file
+func (s *Supervisor) stopUnsupervised(name string) error {
+	pid, alive := s.project.AgentPID(name)
+	if !alive {
+		return nil
+	}
+	signalGroup(pid, syscall.SIGTERM)
+	return nil
+}
+
+func (s *Supervisor) notify() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (a *supervised) alive() bool {
+	if a.exited == nil {
+		return false
+	}
+	select {
+	case <-a.exited:
+		return false
+	default:
+		return a.proc != nil
+	}
+}
+
+func (a *supervised) setState(state string) {
+	if a.state.State != state {
+		a.state.Since = time.Now()
+	}
+	a.state.State = state
+}
+
+// SupervisorCmd waits for the next agent state change
+func SupervisorCmd(s *Supervisor) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case <-s.changed:
+			return AgentStateMsg(s.States())
+		case <-s.done:
+			return nil
+		}
+	}
+}
+
+// signalGroup signals the process group led by pid, falling back to pid alone
+func signalGroup(pid int, sig syscall.Signal) {
+	if syscall.Kill(-pid, sig) != nil {
+		syscall.Kill(pid, sig)
+	}
+}
+
+// AgentLogPath returns .claude/logs/agent-<name>.log, where the supervisor
+// writes an agent's stdout and stderr
+func (p *Project) AgentLogPath(name string) string {
+	return filepath.Join(p.LogsDir(), "agent-"+name+".log")
+}
+
+func openAgentLog(p *Project, name string) (*os.File, error) {
+	if err := os.MkdirAll(p.LogsDir(), 0755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(p.AgentLogPath(name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+}
+
+func writePIDFile(p *Project, name string, pid int) {
+	if err := os.MkdirAll(p.PidsDir(), 0755); err != nil {
+		return
+	}
+	WriteFileAtomic(filepath.Join(p.PidsDir(), name+".pid"), []byte(strconv.Itoa(pid)+"\n"), 0644)
+}
+
+func removePIDFile(p *Project, name string) {
+	path := filepath.Join(p.PidsDir(), name+".pid")
+	// Only remove the file when it still names a dead process; agent.sh may
+	// have been started again by someone else in the meantime
+	if _, alive := p.AgentPID(name); !alive {
+		os.Remove(path)
+	}
+}
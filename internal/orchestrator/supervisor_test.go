@@ -0,0 +1,118 @@
+package orchestrator
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSupervisor(t *testing.T, script string) (*Supervisor, *Project) {
+	t.Helper()
+	p := &Project{Root: t.TempDir()}
+	s := NewSupervisor(p)
+	s.command = func(name string) *exec.Cmd {
+		return exec.Command("sh", "-c", script)
+	}
+	s.backoffBase = 10 * time.Millisecond
+	s.backoffMax = 40 * time.Millisecond
+	s.maxRestarts = 2
+	t.Cleanup(s.Close)
+	return s, p
+}
+
+// waitForState waits until agent reaches state
+func waitForState(t *testing.T, s *Supervisor, name, state string) AgentState {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		if st, ok := s.State(name); ok && st.State == state {
+			return st
+		}
+		select {
+		case <-s.Changed():
+		case <-time.After(20 * time.Millisecond):
+		case <-deadline:
+			st, _ := s.State(name)
+			t.Fatalf("agent %s never reached %s (last %+v)", name, state, st)
+		}
+	}
+}
+
+func TestSupervisorRestartsCrashedAgent(t *testing.T) {
+	s, p := newTestSupervisor(t, "echo booting; exit 3")
+
+	if err := s.Spawn("backend"); err != nil {
+		t.Fatal(err)
+	}
+	st := waitForState(t, s, "backend", AgentFailed)
+	if st.ExitCode != 3 || st.Restarts != 2 {
+		t.Errorf("expected exit code 3 after 2 restarts, got %+v", st)
+	}
+
+	log, err := os.ReadFile(p.AgentLogPath("backend"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(log), "booting"); n != 3 {
+		t.Errorf("expected output of 3 runs in the agent log, got %d:\n%s", n, log)
+	}
+	if _, alive := p.AgentPID("backend"); alive {
+		t.Error("pid file should not point at a live process")
+	}
+}
+
+func TestSupervisorStop(t *testing.T) {
+	s, p := newTestSupervisor(t, "sleep 30")
+
+	if err := s.Spawn("docs"); err != nil {
+		t.Fatal(err)
+	}
+	st := waitForState(t, s, "docs", AgentRunning)
+	if pid, alive := p.AgentPID("docs"); !alive || pid != st.PID {
+		t.Fatalf("expected pid file with %d, got %d (alive %v)", st.PID, pid, alive)
+	}
+	if err := s.Spawn("docs"); err == nil {
+		t.Error("spawning a running agent should fail")
+	}
+
+	if err := s.Stop("docs"); err != nil {
+		t.Fatal(err)
+	}
+	waitForState(t, s, "docs", AgentStopped)
+	if _, err := os.Stat(p.Path("pids", "docs.pid")); !os.IsNotExist(err) {
+		t.Errorf("pid file should be removed after stop, got %v", err)
+	}
+}
+
+func TestStopTaskStopsIdleAgent(t *testing.T) {
+	s, p := newTestSupervisor(t, "sleep 30")
+	SetSupervisor(s)
+	t.Cleanup(func() { SetSupervisor(nil) })
+	if err := os.MkdirAll(p.ClaudeDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p.TasksPath(), []byte(`{"tasks": [
+		{"id": 1, "description": "a", "status": "in_progress", "agent": "backend"},
+		{"id": 2, "description": "b", "status": "in_progress", "agent": "backend"}
+	], "last_id": 2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Spawn("backend"); err != nil {
+		t.Fatal(err)
+	}
+	waitForState(t, s, "backend", AgentRunning)
+
+	// the agent still has #2 to work on
+	if task, err := p.StopTask(1); err != nil || task.Status != StatusStopped {
+		t.Fatalf("unexpected stop: %+v %v", task, err)
+	}
+	if st, _ := s.State("backend"); st.State != AgentRunning {
+		t.Fatalf("expected the agent kept running, got %+v", st)
+	}
+	if _, err := p.StopTask(2); err != nil {
+		t.Fatal(err)
+	}
+	waitForState(t, s, "backend", AgentStopped)
+}
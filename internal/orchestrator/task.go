@@ -0,0 +1,271 @@
+package orchestrator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CurrentSchemaVersion is the tasks.json layout written by this package.
+// Files without schema_version are version 0 and are migrated on load.
+const CurrentSchemaVersion = 1
+
+// ErrSchemaTooNew is returned when tasks.json was written by a newer version
+var ErrSchemaTooNew = errors.New("tasks.json schema is newer than this binary")
+
+// Task represents a task in the system
+type Task struct {
+	ID               int               `json:"id"`
+	Description      string            `json:"description"`
+	Status           string            `json:"status"` // pending, in_progress, pending_approval, completed, failed, stopped
+	Agent            string            `json:"agent"`
+	Priority         string            `json:"priority"` // critical, high, normal, low
+	Dependencies     []int             `json:"dependencies"`
+	Progress         int               `json:"progress"` // 0-100
+	CreatedAt        string            `json:"created_at"`
+	UpdatedAt        string            `json:"updated_at"`
+	StartedAt        string            `json:"started_at"`            // written as null when empty
+	CompletedAt      string            `json:"completed_at"`          // written as null when empty
+	BaseCommit       string            `json:"base_commit,omitempty"` // HEAD when the task was first started
+	ApprovalRequests []ApprovalRequest `json:"approval_requests,omitempty"`
+
+	// Extra keeps fields this struct does not model so they survive a round trip
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// TasksData is the top level of tasks.json
+type TasksData struct {
+	SchemaVersion int    `json:"schema_version"`
+	Tasks         []Task `json:"tasks"`
+	LastID        int    `json:"last_id"`
+
+	// Extra keeps top-level fields such as "approvals"
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// taskFields / tasksDataFields have the same layout without the JSON methods
+type taskFields Task
+type tasksDataFields TasksData
+
+// UnmarshalJSON accepts the loosely typed values the bash scripts write
+// (string dependencies, fractional progress) and keeps unknown fields.
+func (t *Task) UnmarshalJSON(b []byte) error {
+	var aux struct {
+		*taskFields
+		Dependencies json.RawMessage `json:"dependencies"`
+		Progress     json.RawMessage `json:"progress"`
+	}
+	aux.taskFields = (*taskFields)(t)
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	deps, err := parseDependencies(aux.Dependencies)
+	if err != nil {
+		return fmt.Errorf("task #%d: %w", t.ID, err)
+	}
+	t.Dependencies = deps
+	t.Progress = parseProgress(aux.Progress)
+
+	extra, err := unknownFields(b, knownTaskFields)
+	if err != nil {
+		return err
+	}
+	t.Extra = extra
+	return nil
+}
+
+// MarshalJSON writes the modeled fields followed by the preserved unknown ones
+func (t Task) MarshalJSON() ([]byte, error) {
+	fields := taskFields(t)
+	if fields.Dependencies == nil {
+		fields.Dependencies = []int{}
+	}
+	aux := struct {
+		taskFields
+		StartedAt   *string `json:"started_at"`
+		CompletedAt *string `json:"completed_at"`
+	}{
+		taskFields:  fields,
+		StartedAt:   nullable(t.StartedAt),
+		CompletedAt: nullable(t.CompletedAt),
+	}
+	b, err := json.Marshal(aux)
+	if err != nil {
+		return nil, err
+	}
+	return appendExtra(b, t.Extra, knownTaskFields)
+}
+
+// UnmarshalJSON keeps unknown top-level fields
+func (d *TasksData) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, (*tasksDataFields)(d)); err != nil {
+		return err
+	}
+	extra, err := unknownFields(b, knownTasksDataFields)
+	if err != nil {
+		return err
+	}
+	d.Extra = extra
+	return nil
+}
+
+// MarshalJSON writes the modeled fields followed by the preserved unknown ones
+func (d TasksData) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(tasksDataFields(d))
+	if err != nil {
+		return nil, err
+	}
+	return appendExtra(b, d.Extra, knownTasksDataFields)
+}
+
+var knownTaskFields = map[string]bool{
+	"id": true, "description": true, "status": true, "agent": true, "priority": true,
+	"dependencies": true, "progress": true, "created_at": true, "updated_at": true,
+	"started_at": true, "completed_at": true, "approval_requests": true, "base_commit": true,
+}
+
+var knownTasksDataFields = map[string]bool{
+	"schema_version": true, "tasks": true, "last_id": true,
+}
+
+// migrateTasksData upgrades data loaded from an older schema in place.
+// Data from a newer schema is left alone; it can be read but not saved.
+func migrateTasksData(d *TasksData) {
+	if d.SchemaVersion > CurrentSchemaVersion {
+		return
+	}
+
+	if d.SchemaVersion < 1 {
+		// v0 → v1: the API spec documented next_id while the scripts write last_id
+		if raw, ok := d.Extra["next_id"]; ok {
+			var next int
+			if json.Unmarshal(raw, &next) == nil && next-1 > d.LastID {
+				d.LastID = next - 1
+			}
+			delete(d.Extra, "next_id")
+		}
+		for i := range d.Tasks {
+			t := &d.Tasks[i]
+			if t.Priority == "" {
+				t.Priority = "normal"
+			}
+			if t.CreatedAt == "" {
+				t.CreatedAt = t.UpdatedAt
+			}
+			if t.Status == StatusCompleted && t.Progress == 0 {
+				t.Progress = 100
+			}
+		}
+		d.SchemaVersion = 1
+	}
+
+	// last_id must never fall behind existing IDs, or new tasks would collide
+	for _, t := range d.Tasks {
+		if t.ID > d.LastID {
+			d.LastID = t.ID
+		}
+	}
+}
+
+func parseDependencies(raw json.RawMessage) ([]int, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var ids []int
+	if err := json.Unmarshal(raw, &ids); err == nil {
+		return ids, nil
+	}
+
+	// "1,2" or ["1", "2"]
+	var parts []string
+	var joined string
+	if err := json.Unmarshal(raw, &joined); err == nil {
+		parts = strings.Split(joined, ",")
+	} else if err := json.Unmarshal(raw, &parts); err != nil {
+		return nil, fmt.Errorf("invalid dependencies %s", raw)
+	}
+	for _, p := range parts {
+		p = strings.TrimPrefix(strings.TrimSpace(p), "#")
+		if p == "" {
+			continue
+		}
+		id, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dependency %q", p)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func parseProgress(raw json.RawMessage) int {
+	var f float64
+	if err := json.Unmarshal(raw, &f); err == nil {
+		return int(f)
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if f, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64); err == nil {
+			return int(f)
+		}
+	}
+	return 0
+}
+
+func unknownFields(b []byte, known map[string]bool) (map[string]json.RawMessage, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	for key := range raw {
+		if known[key] {
+			delete(raw, key)
+		}
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	return raw, nil
+}
+
+func nullable(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// appendExtra adds extra keys (sorted, skipping modeled ones) to an encoded object
+func appendExtra(obj []byte, extra map[string]json.RawMessage, known map[string]bool) ([]byte, error) {
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		if !known[key] {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return obj, nil
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.Write(bytes.TrimSuffix(bytes.TrimSpace(obj), []byte("}")))
+	for _, key := range keys {
+		if buf.Bytes()[buf.Len()-1] != '{' {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(extra[key])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
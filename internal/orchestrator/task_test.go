@@ -0,0 +1,88 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+const legacyTasksJSON = `{
+  "tasks": [
+    {
+      "id": 3,
+      "description": "Implement login API",
+      "status": "completed",
+      "agent": "backend",
+      "updated_at": "2026-02-07T10:00:00Z",
+      "dependencies": "1, 2",
+      "worktree": ".claude/worktrees/task-3",
+      "approval_requests": [
+        {"id": "req-001", "operation_type": "file_write", "details": {"file": "src/app.ts"},
+         "requested_at": "2026-02-07T13:30:00Z", "requested_by": "agent:backend", "status": "pending", "response": null}
+      ]
+    }
+  ],
+  "next_id": 7,
+  "approvals": [{"id": "req-001", "task_id": 3}]
+}`
+
+func TestTasksDataMigrationAndRoundTrip(t *testing.T) {
+	s := newTestStore(t, legacyTasksJSON)
+
+	data, err := s.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if data.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("expected schema %d after migration, got %d", CurrentSchemaVersion, data.SchemaVersion)
+	}
+	if data.LastID != 6 {
+		t.Errorf("expected next_id 7 to migrate to last_id 6, got %d", data.LastID)
+	}
+	task := data.Tasks[0]
+	if len(task.Dependencies) != 2 || task.Dependencies[0] != 1 || task.Dependencies[1] != 2 {
+		t.Errorf("unexpected dependencies %v", task.Dependencies)
+	}
+	if task.Priority != "normal" || task.CreatedAt != task.UpdatedAt || task.Progress != 100 {
+		t.Errorf("v0 defaults not applied: %+v", task)
+	}
+	if len(task.ApprovalRequests) != 1 || task.ApprovalRequests[0].OperationType != "file_write" {
+		t.Errorf("approval_requests not decoded: %+v", task.ApprovalRequests)
+	}
+
+	if _, err := s.Edit(3, "Implement login and logout API"); err != nil {
+		t.Fatalf("Edit: %v", err)
+	}
+
+	raw, err := os.ReadFile(s.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("written file is not JSON: %v", err)
+	}
+	if _, ok := out["approvals"]; !ok {
+		t.Error("top-level approvals field was dropped")
+	}
+	if _, ok := out["next_id"]; ok {
+		t.Error("next_id should have been migrated away")
+	}
+	for _, want := range []string{`"worktree": ".claude/worktrees/task-3"`, `"started_at": null`, `"req-001"`} {
+		if !strings.Contains(string(raw), want) {
+			t.Errorf("expected %s in saved file:\n%s", want, raw)
+		}
+	}
+}
+
+func TestNewerSchemaIsReadOnly(t *testing.T) {
+	s := newTestStore(t, `{"schema_version": 99, "tasks": [{"id": 1, "status": "pending"}], "last_id": 1}`)
+	if _, err := s.Load(); err != nil {
+		t.Fatalf("Load should still work: %v", err)
+	}
+	if _, err := s.Start(1); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("expected ErrSchemaTooNew, got %v", err)
+	}
+}
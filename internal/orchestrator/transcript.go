@@ -0,0 +1,364 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kinds of transcript sections
+const (
+	SectionSystem   = "system"   // session start
+	SectionText     = "text"     // assistant message
+	SectionThinking = "thinking" // extended thinking
+	SectionTool     = "tool"     // tool call and its result
+	SectionEdit     = "edit"     // Edit, MultiEdit and Write calls
+	SectionBash     = "bash"     // Bash calls
+	SectionResult   = "result"   // final result of the run
+	SectionRaw      = "raw"      // a line that is not stream-json
+)
+
+// transcriptMaxBody bounds the body lines kept per section
+const transcriptMaxBody = 200
+
+// Section is one collapsible block of a transcript
+type Section struct {
+	Kind  string
+	Title string   // one-line summary shown when collapsed
+	Body  []string // details shown when expanded
+	Error bool     // failed tool call or error result
+}
+
+// Usage is the token usage reported by the Claude API
+type Usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+func (u *Usage) add(o Usage) {
+	u.InputTokens += o.InputTokens
+	u.OutputTokens += o.OutputTokens
+	u.CacheCreationInputTokens += o.CacheCreationInputTokens
+	u.CacheReadInputTokens += o.CacheReadInputTokens
+}
+
+// Transcript is a Claude CLI run parsed from its --output-format stream-json
+// output. Lines are added as they arrive; tool results are attached to the
+// section of the call they answer.
+type Transcript struct {
+	Sections []Section
+	Usage    Usage   // summed over assistant messages, or taken from the result
+	CostUSD  float64 // from the result, 0 until the run finished
+
+	tools    map[string]int  // tool_use id -> section index
+	messages map[string]bool // assistant message ids whose usage was counted
+}
+
+// streamEvent is one line of stream-json output
+type streamEvent struct {
+	Type      string         `json:"type"`
+	Subtype   string         `json:"subtype"`
+	Model     string         `json:"model"`
+	Cwd       string         `json:"cwd"`
+	Tools     []string       `json:"tools"`
+	SessionID string         `json:"session_id"`
+	Message   *streamMessage `json:"message"`
+	Result    string         `json:"result"`
+	IsError   bool           `json:"is_error"`
+	NumTurns  int            `json:"num_turns"`
+	Duration  int64          `json:"duration_ms"`
+	CostUSD   float64        `json:"total_cost_usd"`
+	Usage     *Usage         `json:"usage"`
+}
+
+type streamMessage struct {
+	ID      string          `json:"id"`
+	Content json.RawMessage `json:"content"`
+	Usage   *Usage          `json:"usage"`
+}
+
+type contentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	Thinking  string          `json:"thinking"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+// ParseTranscript parses a whole transcript
+func ParseTranscript(lines []string) *Transcript {
+	t := &Transcript{}
+	for _, line := range lines {
+		t.Add(line)
+	}
+	return t
+}
+
+// Add parses one line of output. Lines that are not stream-json become raw
+// sections, so stderr noise mixed into the log is still shown.
+func (t *Transcript) Add(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	var ev streamEvent
+	if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &ev) != nil || ev.Type == "" {
+		t.Sections = append(t.Sections, Section{Kind: SectionRaw, Title: line})
+		return
+	}
+
+	switch ev.Type {
+	case "system":
+		if ev.Subtype != "init" {
+			return
+		}
+		title := "Session started"
+		if ev.Model != "" {
+			title += " · " + ev.Model
+		}
+		var body []string
+		if ev.Cwd != "" {
+			body = append(body, "cwd: "+ev.Cwd)
+		}
+		if ev.SessionID != "" {
+			body = append(body, "session: "+ev.SessionID)
+		}
+		if len(ev.Tools) > 0 {
+			body = append(body, "tools: "+strings.Join(ev.Tools, ", "))
+		}
+		t.Sections = append(t.Sections, Section{Kind: SectionSystem, Title: title, Body: body})
+	case "assistant":
+		if ev.Message == nil {
+			return
+		}
+		if ev.Message.Usage != nil && !t.messages[ev.Message.ID] {
+			// every content block of a message repeats the message usage
+			if t.messages == nil {
+				t.messages = make(map[string]bool)
+			}
+			t.messages[ev.Message.ID] = true
+			t.Usage.add(*ev.Message.Usage)
+		}
+		for _, b := range contentBlocks(ev.Message.Content) {
+			t.addAssistantBlock(b)
+		}
+	case "user":
+		if ev.Message == nil {
+			return
+		}
+		for _, b := range contentBlocks(ev.Message.Content) {
+			if b.Type == "tool_result" {
+				t.addToolResult(b)
+			}
+		}
+	case "result":
+		if ev.Usage != nil {
+			t.Usage = *ev.Usage
+		}
+		t.CostUSD = ev.CostUSD
+		status := ev.Subtype
+		if status == "" {
+			status = "done"
+		}
+		title := fmt.Sprintf("Result · %s · %d turns · %s", status, ev.NumTurns, (time.Duration(ev.Duration) * time.Millisecond).Round(100*time.Millisecond))
+		if ev.CostUSD > 0 {
+			title += fmt.Sprintf(" · $%.4f", ev.CostUSD)
+		}
+		t.Sections = append(t.Sections, Section{Kind: SectionResult, Title: title, Body: bodyLines(ev.Result), Error: ev.IsError})
+	}
+}
+
+func (t *Transcript) addAssistantBlock(b contentBlock) {
+	switch b.Type {
+	case "text":
+		if strings.TrimSpace(b.Text) == "" {
+			return
+		}
+		body := bodyLines(b.Text)
+		t.Sections = append(t.Sections, Section{Kind: SectionText, Title: body[0], Body: body})
+	case "thinking":
+		body := bodyLines(b.Thinking)
+		t.Sections = append(t.Sections, Section{Kind: SectionThinking, Title: "Thinking · " + body[0], Body: body})
+	case "tool_use":
+		if t.tools == nil {
+			t.tools = make(map[string]int)
+		}
+		t.tools[b.ID] = len(t.Sections)
+		t.Sections = append(t.Sections, toolSection(b.Name, b.Input))
+	}
+}
+
+func (t *Transcript) addToolResult(b contentBlock) {
+	i, ok := t.tools[b.ToolUseID]
+	if !ok {
+		t.Sections = append(t.Sections, Section{Kind: SectionTool, Title: "Tool result", Body: bodyLines(resultText(b.Content)), Error: b.IsError})
+		return
+	}
+	s := &t.Sections[i]
+	s.Error = b.IsError
+	out := bodyLines(resultText(b.Content))
+	if len(out) == 1 && out[0] == "" {
+		return
+	}
+	marker := "→ result"
+	if b.IsError {
+		marker = "→ error"
+	}
+	s.Body = clipBody(append(append(s.Body, marker), out...))
+}
+
+// toolSection summarizes a tool call by what it touches
+func toolSection(name string, raw json.RawMessage) Section {
+	var in struct {
+		Command     string `json:"command"`
+		Description string `json:"description"`
+		FilePath    string `json:"file_path"`
+		Path        string `json:"path"`
+		Pattern     string `json:"pattern"`
+		URL         string `json:"url"`
+		Query       string `json:"query"`
+		Content     string `json:"content"`
+		OldString   string `json:"old_string"`
+		NewString   string `json:"new_string"`
+		Edits       []struct {
+			OldString string `json:"old_string"`
+			NewString string `json:"new_string"`
+		} `json:"edits"`
+		Todos []struct {
+			Content string `json:"content"`
+			Status  string `json:"status"`
+		} `json:"todos"`
+	}
+	json.Unmarshal(raw, &in)
+
+	s := Section{Kind: SectionTool, Title: name}
+	switch name {
+	case "Bash":
+		s.Kind = SectionBash
+		s.Title = "$ " + firstLine(in.Command)
+		if in.Description != "" {
+			s.Body = append(s.Body, "# "+in.Description)
+		}
+		s.Body = append(s.Body, bodyLines(in.Command)...)
+	case "Edit":
+		s.Kind = SectionEdit
+		s.Title = "Edit " + in.FilePath
+		s.Body = diffLines(in.OldString, in.NewString)
+	case "MultiEdit":
+		s.Kind = SectionEdit
+		s.Title = fmt.Sprintf("Edit %s (%d changes)", in.FilePath, len(in.Edits))
+		for i, e := range in.Edits {
+			if i > 0 {
+				s.Body = append(s.Body, "")
+			}
+			s.Body = append(s.Body, diffLines(e.OldString, e.NewString)...)
+		}
+	case "Write":
+		s.Kind = SectionEdit
+		s.Title = "Write " + in.FilePath
+		for _, l := range bodyLines(in.Content) {
+			s.Body = append(s.Body, "+ "+l)
+		}
+	case "TodoWrite":
+		s.Title = fmt.Sprintf("Todos (%d)", len(in.Todos))
+		for _, todo := range in.Todos {
+			mark := "[ ]"
+			switch todo.Status {
+			case "completed":
+				mark = "[x]"
+			case "in_progress":
+				mark = "[~]"
+			}
+			s.Body = append(s.Body, mark+" "+todo.Content)
+		}
+	default:
+		// the most telling argument of the common tools, else the raw input
+		for _, arg := range []string{in.FilePath, in.Pattern, in.Path, in.URL, in.Query, in.Description} {
+			if arg != "" {
+				s.Title = name + " " + firstLine(arg)
+				break
+			}
+		}
+		if len(raw) > 0 && string(raw) != "{}" {
+			s.Body = append(s.Body, string(raw))
+		}
+	}
+	s.Body = clipBody(s.Body)
+	return s
+}
+
+// contentBlocks decodes message content, which is either text or a list of blocks
+func contentBlocks(raw json.RawMessage) []contentBlock {
+	var blocks []contentBlock
+	if json.Unmarshal(raw, &blocks) == nil {
+		return blocks
+	}
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return []contentBlock{{Type: "text", Text: text}}
+	}
+	return nil
+}
+
+// resultText flattens tool_result content to text
+func resultText(raw json.RawMessage) string {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+	var parts []string
+	for _, b := range contentBlocks(raw) {
+		switch b.Type {
+		case "text":
+			parts = append(parts, b.Text)
+		default:
+			parts = append(parts, "["+b.Type+"]")
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// diffLines shows a replacement as removed and added lines
+func diffLines(before, after string) []string {
+	var lines []string
+	if before != "" {
+		for _, l := range bodyLines(before) {
+			lines = append(lines, "- "+l)
+		}
+	}
+	for _, l := range bodyLines(after) {
+		lines = append(lines, "+ "+l)
+	}
+	return lines
+}
+
+// bodyLines splits text into lines; there is always at least one
+func bodyLines(text string) []string {
+	text = strings.ReplaceAll(strings.TrimRight(text, "\n"), "\t", "    ")
+	return clipBody(strings.Split(text, "\n"))
+}
+
+// clipBody keeps the first transcriptMaxBody lines
+func clipBody(lines []string) []string {
+	if len(lines) <= transcriptMaxBody {
+		return lines
+	}
+	more := len(lines) - transcriptMaxBody
+	return append(lines[:transcriptMaxBody:transcriptMaxBody], fmt.Sprintf("… %d more lines", more))
+}
+
+// firstLine returns the first line of s, marking that more follows
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i] + " …"
+	}
+	return s
+}
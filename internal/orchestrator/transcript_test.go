@@ -0,0 +1,53 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleTranscript = `{"type":"system","subtype":"init","cwd":"/work","session_id":"s1","model":"claude-sonnet","tools":["Bash","Edit"]}
+{"type":"assistant","message":{"id":"m1","content":[{"type":"text","text":"I'll run the tests.\nThen fix them."}],"usage":{"input_tokens":10,"output_tokens":5}}}
+{"type":"assistant","message":{"id":"m1","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./...","description":"Run tests"}}],"usage":{"input_tokens":10,"output_tokens":5}}}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"FAIL\tpkg 0.1s","is_error":true}]}}
+{"type":"assistant","message":{"id":"m2","content":[{"type":"tool_use","id":"t2","name":"Edit","input":{"file_path":"pkg/a.go","old_string":"return 1","new_string":"return 2"}}],"usage":{"input_tokens":20,"output_tokens":7}}}
+{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t2","content":[{"type":"text","text":"ok"}]}]}}
+npm warn something on stderr
+{"type":"result","subtype":"success","is_error":false,"duration_ms":12300,"num_turns":3,"result":"Fixed.","total_cost_usd":0.0123,"usage":{"input_tokens":30,"output_tokens":12,"cache_read_input_tokens":100}}`
+
+func TestParseTranscript(t *testing.T) {
+	tr := ParseTranscript(strings.Split(sampleTranscript, "\n"))
+
+	kinds := []string{SectionSystem, SectionText, SectionBash, SectionEdit, SectionRaw, SectionResult}
+	if len(tr.Sections) != len(kinds) {
+		t.Fatalf("expected %d sections, got %+v", len(kinds), tr.Sections)
+	}
+	for i, kind := range kinds {
+		if tr.Sections[i].Kind != kind {
+			t.Errorf("section %d: expected %s, got %s (%q)", i, kind, tr.Sections[i].Kind, tr.Sections[i].Title)
+		}
+	}
+
+	bash := tr.Sections[2]
+	if bash.Title != "$ go test ./..." || !bash.Error || !strings.Contains(strings.Join(bash.Body, "\n"), "→ error\nFAIL    pkg 0.1s") {
+		t.Errorf("unexpected bash section %+v", bash)
+	}
+	edit := tr.Sections[3]
+	if edit.Title != "Edit pkg/a.go" || strings.Join(edit.Body, "|") != "- return 1|+ return 2|→ result|ok" {
+		t.Errorf("unexpected edit section %+v", edit)
+	}
+	if res := tr.Sections[5]; res.Title != "Result · success · 3 turns · 12.3s · $0.0123" || res.Body[0] != "Fixed." {
+		t.Errorf("unexpected result section %+v", res)
+	}
+	if tr.Usage.OutputTokens != 12 || tr.Usage.CacheReadInputTokens != 100 || tr.CostUSD != 0.0123 {
+		t.Errorf("expected the usage of the result, got %+v $%v", tr.Usage, tr.CostUSD)
+	}
+}
+
+func TestTranscriptUsageWhileRunning(t *testing.T) {
+	// before the result arrives, usage is summed once per message
+	lines := strings.Split(sampleTranscript, "\n")
+	tr := ParseTranscript(lines[:len(lines)-1])
+	if tr.Usage.InputTokens != 30 || tr.Usage.OutputTokens != 12 {
+		t.Errorf("expected usage of m1 and m2, got %+v", tr.Usage)
+	}
+}
@@ -0,0 +1,271 @@
+package orchestrator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Timing used by the watcher
+const (
+	watchDebounce     = 100 * time.Millisecond
+	watchPollInterval = time.Second
+)
+
+// FileChangeMsg is sent when watched files change
+type FileChangeMsg struct {
+	Paths []string
+}
+
+// watchTarget is a directory to watch. When name is set only that entry of
+// the directory is reported, which keeps atomic renames of a single file
+// (tasks.json) visible while ignoring its lock and temp files.
+type watchTarget struct {
+	dir  string
+	name string
+}
+
+// watchBackend delivers raw change notifications for a set of targets.
+// add registers the targets before NewWatcher returns, so no change made
+// after that point is missed, and returns the ones it could not watch; run
+// then reports changes until done is closed.
+type watchBackend interface {
+	add(targets []watchTarget) []watchTarget
+	run(changed func(path string), done <-chan struct{})
+	close() error
+}
+
+// Watcher reports changes to files and directories, using inotify where
+// available and falling back to polling, also for the paths inotify cannot
+// watch and those missing at startup.
+type Watcher struct {
+	events   chan []string
+	done     chan struct{}
+	backends []watchBackend
+	once     sync.Once
+
+	mu      sync.Mutex
+	pending map[string]bool
+	timer   *time.Timer
+}
+
+// WatchPaths returns the paths the dashboard reacts to
+func (p *Project) WatchPaths() []string {
+	return []string{p.TasksPath(), p.ApprovalsPath(), p.PidsDir(), p.LogsDir(), p.AgentsDir()}
+}
+
+// NewWatcher watches the given files and directories
+func NewWatcher(paths []string) (*Watcher, error) {
+	var targets, polled []watchTarget
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(abs)
+		switch {
+		case err != nil:
+			// a directory or a file yet to be created; polling finds either
+			polled = append(polled, watchTarget{dir: abs})
+		case info.IsDir():
+			targets = append(targets, watchTarget{dir: abs})
+		default:
+			targets = append(targets, watchTarget{dir: filepath.Dir(abs), name: filepath.Base(abs)})
+		}
+	}
+
+	w := &Watcher{
+		events:  make(chan []string, 1),
+		done:    make(chan struct{}),
+		pending: make(map[string]bool),
+	}
+	if backend, err := newNativeBackend(); err == nil {
+		polled = append(polled, backend.add(targets)...)
+		w.backends = append(w.backends, backend)
+	} else {
+		polled = append(polled, targets...)
+	}
+	if len(polled) > 0 {
+		backend := newPollBackend(watchPollInterval)
+		backend.add(polled)
+		w.backends = append(w.backends, backend)
+	}
+	for _, backend := range w.backends {
+		go backend.run(w.notify, w.done)
+	}
+	return w, nil
+}
+
+// Events delivers batches of changed paths. It is closed by Close.
+func (w *Watcher) Events() <-chan []string {
+	return w.events
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		for _, backend := range w.backends {
+			err = errors.Join(err, backend.close())
+		}
+		w.mu.Lock()
+		if w.timer != nil {
+			w.timer.Stop()
+		}
+		close(w.events)
+		w.mu.Unlock()
+	})
+	return err
+}
+
+// notify collects changes and flushes them after a short quiet period, since
+// a single save shows up as several create/modify/rename events.
+func (w *Watcher) notify(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	select {
+	case <-w.done:
+		return
+	default:
+	}
+	w.pending[path] = true
+	if w.timer == nil {
+		w.timer = time.AfterFunc(watchDebounce, w.flush)
+	}
+}
+
+func (w *Watcher) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timer = nil
+	select {
+	case <-w.done:
+		return
+	default:
+	}
+	if len(w.pending) == 0 {
+		return
+	}
+
+	// Merge with a batch the consumer has not picked up yet
+	select {
+	case prev := <-w.events:
+		for _, p := range prev {
+			w.pending[p] = true
+		}
+	default:
+	}
+	paths := make([]string, 0, len(w.pending))
+	for p := range w.pending {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	w.pending = make(map[string]bool)
+	w.events <- paths
+}
+
+// WatchCmd waits for the next batch of changes
+func WatchCmd(w *Watcher) tea.Cmd {
+	return func() tea.Msg {
+		paths, ok := <-w.Events()
+		if !ok {
+			return nil
+		}
+		return FileChangeMsg{Paths: paths}
+	}
+}
+
+// pollBackend detects changes by comparing directory listings
+type pollBackend struct {
+	interval time.Duration
+	targets  []watchTarget
+	last     map[string]fileStamp
+}
+
+func newPollBackend(interval time.Duration) *pollBackend {
+	return &pollBackend{interval: interval}
+}
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+func (b *pollBackend) add(targets []watchTarget) []watchTarget {
+	b.targets = targets
+	b.last = b.scan()
+	return nil
+}
+
+func (b *pollBackend) scan() map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	for _, t := range b.targets {
+		for path, st := range scanTarget(t) {
+			stamps[path] = st
+		}
+	}
+	return stamps
+}
+
+func (b *pollBackend) run(changed func(path string), done <-chan struct{}) {
+	last := b.last
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		current := b.scan()
+		for path, st := range current {
+			if prev, ok := last[path]; !ok || prev != st {
+				changed(path)
+			}
+		}
+		for path := range last {
+			if _, ok := current[path]; !ok {
+				changed(path)
+			}
+		}
+		last = current
+	}
+}
+
+func (b *pollBackend) close() error {
+	return nil
+}
+
+func scanTarget(t watchTarget) map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	if t.name != "" {
+		path := filepath.Join(t.dir, t.name)
+		if info, err := os.Stat(path); err == nil {
+			stamps[path] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+		}
+		return stamps
+	}
+	entries, err := os.ReadDir(t.dir)
+	if err != nil {
+		// a path missing at startup may turn out to be a file
+		if info, err := os.Stat(t.dir); err == nil && !info.IsDir() {
+			stamps[t.dir] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+		}
+		return stamps
+	}
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		stamps[filepath.Join(t.dir, e.Name())] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+	}
+	return stamps
+}
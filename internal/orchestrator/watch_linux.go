@@ -0,0 +1,82 @@
+//go:build linux
+
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE |
+	syscall.IN_DELETE | syscall.IN_MOVED_TO | syscall.IN_MOVED_FROM
+
+// inotifyBackend uses Linux inotify on the watched directories
+type inotifyBackend struct {
+	f    *os.File
+	byWd map[int32][]watchTarget
+}
+
+func newNativeBackend() (watchBackend, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	// A non-blocking fd is registered with the runtime poller, so Close
+	// interrupts a pending Read.
+	return &inotifyBackend{f: os.NewFile(uintptr(fd), "inotify")}, nil
+}
+
+func (b *inotifyBackend) add(targets []watchTarget) []watchTarget {
+	b.byWd = make(map[int32][]watchTarget)
+	var failed []watchTarget
+	for _, t := range targets {
+		wd, err := syscall.InotifyAddWatch(int(b.f.Fd()), t.dir, inotifyMask)
+		if err != nil {
+			// e.g. the watch limit is reached; these are polled instead
+			failed = append(failed, t)
+			continue
+		}
+		b.byWd[int32(wd)] = append(b.byWd[int32(wd)], t)
+	}
+	return failed
+}
+
+func (b *inotifyBackend) run(changed func(path string), done <-chan struct{}) {
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := b.f.Read(buf)
+		if err != nil {
+			return
+		}
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameBytes := buf[offset+syscall.SizeofInotifyEvent : offset+syscall.SizeofInotifyEvent+int(ev.Len)]
+			offset += syscall.SizeofInotifyEvent + int(ev.Len)
+
+			name := string(nameBytes)
+			for i, c := range nameBytes {
+				if c == 0 {
+					name = string(nameBytes[:i])
+					break
+				}
+			}
+			for _, t := range b.byWd[ev.Wd] {
+				if t.name != "" && t.name != name {
+					continue
+				}
+				changed(filepath.Join(t.dir, name))
+			}
+		}
+		select {
+		case <-done:
+			return
+		default:
+		}
+	}
+}
+
+func (b *inotifyBackend) close() error {
+	return b.f.Close()
+}
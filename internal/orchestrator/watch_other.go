@@ -0,0 +1,9 @@
+//go:build !linux
+
+package orchestrator
+
+import "errors"
+
+func newNativeBackend() (watchBackend, error) {
+	return nil, errors.New("native file watching is not supported on this platform")
+}
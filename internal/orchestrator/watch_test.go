@@ -0,0 +1,93 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcherReportsAtomicSave(t *testing.T) {
+	dir := t.TempDir()
+	tasks := filepath.Join(dir, "tasks.json")
+	if err := os.WriteFile(tasks, []byte(`{"tasks": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWatcher([]string{tasks})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// Files next to tasks.json must not wake the dashboard
+	if err := os.WriteFile(filepath.Join(dir, "other.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTaskStore(tasks).Add("watch me", "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case paths := <-w.Events():
+		if len(paths) != 1 || paths[0] != tasks {
+			t.Errorf("expected only %s, got %v", tasks, paths)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported for tasks.json")
+	}
+}
+
+func TestPollBackendDetectsNewFiles(t *testing.T) {
+	dir := t.TempDir()
+	changed := make(chan string, 8)
+	done := make(chan struct{})
+	defer close(done)
+
+	b := newPollBackend(20 * time.Millisecond)
+	b.add([]watchTarget{{dir: dir}})
+	go b.run(func(path string) {
+		changed <- path
+	}, done)
+
+	pid := filepath.Join(dir, "backend.pid")
+	if err := os.WriteFile(pid, []byte("123"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case path := <-changed:
+		if path != pid {
+			t.Errorf("expected %s, got %s", pid, path)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("poll backend missed new file")
+	}
+}
+
+func TestWatcherPollsMissingPaths(t *testing.T) {
+	pids := filepath.Join(t.TempDir(), "pids")
+	w, err := NewWatcher([]string{pids})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// pids/ is created after the watcher started
+	if err := os.MkdirAll(pids, 0755); err != nil {
+		t.Fatal(err)
+	}
+	pid := filepath.Join(pids, "backend.pid")
+	if err := os.WriteFile(pid, []byte("123"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case paths := <-w.Events():
+		if len(paths) != 1 || paths[0] != pid {
+			t.Errorf("expected %s, got %v", pid, paths)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported inside a directory created later")
+	}
+}
@@ -0,0 +1,253 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+// agentStateColor returns the color used for a supervisor state
+func agentStateColor(state string) lipgloss.TerminalColor {
+	switch state {
+	case orchestrator.AgentRunning:
+		return special
+	case orchestrator.AgentRestarting:
+		return lipgloss.Color("214")
+	case orchestrator.AgentCrashed, orchestrator.AgentFailed:
+		return lipgloss.Color("196")
+	default:
+		return subtle
+	}
+}
+
+// agentStateEvents describes what changed between two supervisor snapshots
+func agentStateEvents(prev, next []orchestrator.AgentState) []string {
+	before := make(map[string]orchestrator.AgentState, len(prev))
+	for _, st := range prev {
+		before[st.Name] = st
+	}
+	var events []string
+	for _, st := range next {
+		if old, ok := before[st.Name]; ok && old.State == st.State && old.PID == st.PID {
+			continue
+		}
+		switch st.State {
+		case orchestrator.AgentRunning:
+			events = append(events, fmt.Sprintf("[AGENT] %s RUNNING (pid %d)", st.Name, st.PID))
+		case orchestrator.AgentCrashed:
+			events = append(events, fmt.Sprintf("[WARN] Agent %s CRASHED (%s), restarting in %s",
+				st.Name, st.Err, time.Until(st.RestartAt).Round(time.Second)))
+		case orchestrator.AgentRestarting:
+			events = append(events, fmt.Sprintf("[AGENT] %s RESTARTING (attempt %d)", st.Name, st.Restarts))
+		case orchestrator.AgentStopped:
+			events = append(events, fmt.Sprintf("[AGENT] %s STOPPED", st.Name))
+		case orchestrator.AgentFailed:
+			events = append(events, fmt.Sprintf("[ERROR] Agent %s FAILED after %d restarts: %s", st.Name, st.Restarts, st.Err))
+		}
+	}
+	return events
+}
+
+// renderAgentStrip renders one "● name STATE" badge per supervised agent
+func renderAgentStrip(states []orchestrator.AgentState) string {
+	if len(states) == 0 {
+		return ""
+	}
+	badges := make([]string, 0, len(states))
+	for _, st := range states {
+		badges = append(badges, lipgloss.NewStyle().Foreground(agentStateColor(st.State)).
+			Render(fmt.Sprintf("● %s %s", st.Name, st.State)))
+	}
+	return strings.Join(badges, "  ")
+}
+
+const agentTickInterval = 2 * time.Second
+
+// agentTickMsg refreshes CPU and RSS in the agents panel
+type agentTickMsg struct{}
+
+func agentTickCmd() tea.Cmd {
+	return tea.Tick(agentTickInterval, func(time.Time) tea.Msg { return agentTickMsg{} })
+}
+
+// setAgents stores a new agent snapshot and derives CPU usage from the
+// previous /proc sample of the same process
+func (m *MainModel) setAgents(agents []orchestrator.AgentStatus) {
+	cpu := make(map[string]float64, len(agents))
+	samples := make(map[string]orchestrator.ProcStat, len(agents))
+	for _, a := range agents {
+		if a.Proc == nil {
+			continue
+		}
+		var prev *orchestrator.ProcStat
+		if p, ok := m.agentSamples[a.Name]; ok {
+			prev = &p
+		}
+		cpu[a.Name] = a.Proc.CPUPercent(prev)
+		samples[a.Name] = *a.Proc
+	}
+	m.agents = agents
+	m.agentCPU = cpu
+	m.agentSamples = samples
+	m.agentSelected = clamp(m.agentSelected, 0, max(0, len(agents)-1))
+}
+
+// selectedAgent returns the agent under the cursor in the agents panel
+func (m MainModel) selectedAgent() (orchestrator.AgentStatus, bool) {
+	if m.agentSelected < 0 || m.agentSelected >= len(m.agents) {
+		return orchestrator.AgentStatus{}, false
+	}
+	return m.agents[m.agentSelected], true
+}
+
+// agentKey handles keys of the agents panel and reports whether it used the key
+func (m *MainModel) agentKey(key string) (tea.Cmd, bool) {
+	if m.Tab != tabAgents {
+		return nil, false
+	}
+	switch key {
+	case "up":
+		m.agentSelected = max(0, m.agentSelected-1)
+		return nil, true
+	case "down":
+		m.agentSelected = clamp(m.agentSelected+1, 0, max(0, len(m.agents)-1))
+		return nil, true
+	case "s", "S", "x", "X", "t", "T", "R", "l", "L":
+	default:
+		return nil, false
+	}
+
+	a, ok := m.selectedAgent()
+	if !ok {
+		return nil, true
+	}
+	switch key {
+	case "s", "S":
+		if a.Running {
+			m.events = append([]string{fmt.Sprintf("[WARN] Agent %s is already running (pid %d)", a.Name, a.PID)}, m.events...)
+			return nil, true
+		}
+		m.events = append([]string{fmt.Sprintf("Spawning agent %s...", a.Name)}, m.events...)
+		return orchestrator.SpawnAgentCmd(a.Name), true
+	case "R":
+		m.events = append([]string{fmt.Sprintf("Restarting agent %s...", a.Name)}, m.events...)
+		return orchestrator.RestartAgentCmd(a.Name), true
+	case "l", "L":
+		return m.openAgentLog(a.Name), true
+	default:
+		m.events = append([]string{fmt.Sprintf("Stopping agent %s...", a.Name)}, m.events...)
+		return orchestrator.StopAgentCmd(a.Name), true
+	}
+}
+
+// renderAgents draws the agents panel: one row per agent
+func (m MainModel) renderAgents(width, height int) string {
+	if len(m.agents) == 0 {
+		return lipgloss.NewStyle().Foreground(subtle).Render("No agents found in .claude/agents or .claude/pids.")
+	}
+
+	supervised := make(map[string]orchestrator.AgentState, len(m.agentStates))
+	for _, st := range m.agentStates {
+		supervised[st.Name] = st
+	}
+
+	const (
+		wName, wState, wPID, wTask, wUp, wCPU, wRSS = 14, 11, 8, 24, 9, 7, 9
+	)
+	wLog := max(10, width-(wName+wState+wPID+wTask+wUp+wCPU+wRSS)-2)
+	cell := func(s string, w int) string {
+		return lipgloss.NewStyle().Width(w).MaxWidth(w).Render(truncate(s, w-1))
+	}
+	header := lipgloss.NewStyle().Foreground(subtle).Bold(true).Render(
+		"  " + cell("AGENT", wName) + cell("STATE", wState) + cell("PID", wPID) + cell("TASK", wTask) +
+			cell("UPTIME", wUp) + cell("CPU", wCPU) + cell("RSS", wRSS) + cell("LAST LOG", wLog))
+
+	rows := []string{header}
+	first := max(0, m.agentSelected-(height-3)+1)
+	for i := first; i < len(m.agents) && len(rows) < height-1; i++ {
+		a := m.agents[i]
+		state := "DOWN"
+		if a.Running {
+			state = orchestrator.AgentRunning
+		}
+		if st, ok := supervised[a.Name]; ok {
+			state = st.State
+		}
+
+		pid, uptime, cpu, rss := "-", "-", "-", "-"
+		if a.Running {
+			pid = fmt.Sprintf("%d", a.PID)
+		}
+		if a.Proc != nil {
+			uptime = formatUptime(a.Proc.Uptime())
+			cpu = fmt.Sprintf("%.1f%%", m.agentCPU[a.Name])
+			rss = formatBytes(a.Proc.RSS)
+		}
+
+		cursor := "  "
+		if i == m.agentSelected {
+			cursor = lipgloss.NewStyle().Foreground(accent).Render("▶ ")
+		}
+		name := lipgloss.NewStyle().Foreground(agentColor(m.catalog, a.Name)).Bold(true).Render(truncate(a.Name, wName-1))
+		row := cursor +
+			lipgloss.NewStyle().Width(wName).Render(name) +
+			lipgloss.NewStyle().Width(wState).Foreground(agentStateColor(state)).Render(state) +
+			cell(pid, wPID) + cell(m.agentTask(a.Name), wTask) + cell(uptime, wUp) + cell(cpu, wCPU) + cell(rss, wRSS) +
+			lipgloss.NewStyle().Foreground(subtle).Render(truncate(a.LastLogLine, wLog))
+		rows = append(rows, row)
+	}
+	return strings.Join(rows, "\n")
+}
+
+// agentTask describes the in_progress task of an agent
+func (m MainModel) agentTask(agent string) string {
+	var ids []string
+	var desc string
+	for _, t := range m.Tasks {
+		if strings.EqualFold(t.Agent, agent) && t.Status == orchestrator.StatusInProgress {
+			ids = append(ids, fmt.Sprintf("#%d", t.ID))
+			if desc == "" {
+				desc = t.Description
+			}
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return "-"
+	case 1:
+		return ids[0] + " " + desc
+	default:
+		return strings.Join(ids, " ")
+	}
+}
+
+func formatUptime(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd%dh", d/(24*time.Hour), (d%(24*time.Hour))/time.Hour)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%dm", d/time.Hour, (d%time.Hour)/time.Minute)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm%ds", d/time.Minute, (d%time.Minute)/time.Second)
+	default:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "KMGT"[exp])
+}
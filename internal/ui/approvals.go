@@ -0,0 +1,319 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+// approvalTickInterval is how often the approval policy is applied
+const approvalTickInterval = 30 * time.Second
+
+// approvalTickMsg runs the next approval policy sweep
+type approvalTickMsg struct{}
+
+func approvalTickCmd() tea.Cmd {
+	return tea.Tick(approvalTickInterval, func(time.Time) tea.Msg { return approvalTickMsg{} })
+}
+
+// approvalEvents describes what a policy sweep did for the event log
+func approvalEvents(events []orchestrator.ApprovalEvent) []string {
+	var lines []string
+	for _, ev := range events {
+		a := ev.Approval
+		switch ev.Kind {
+		case orchestrator.EscalationExpiring:
+			lines = append(lines, fmt.Sprintf("[WARN] Approval %s for task #%d expires in %s", a.ID, a.TaskID, formatUptime(ev.Remaining)))
+		case orchestrator.EscalationAutoApproved:
+			lines = append(lines, fmt.Sprintf("Approval %s for task #%d auto-approved by policy (%s)", a.ID, a.TaskID, a.OperationType))
+		case orchestrator.EscalationExpired:
+			line := fmt.Sprintf("[WARN] Approval %s for task #%d expired", a.ID, a.TaskID)
+			switch ev.TaskStatus {
+			case orchestrator.StatusPending:
+				line += "; task re-queued"
+			case orchestrator.StatusFailed:
+				line += "; task marked failed"
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// approvalIcon returns the status icon of a request and its color
+func approvalIcon(status string) (string, lipgloss.TerminalColor) {
+	switch status {
+	case orchestrator.ApprovalApproved:
+		return "✓", special
+	case orchestrator.ApprovalRejected:
+		return "✗", lipgloss.Color("196")
+	case orchestrator.ApprovalExpired:
+		return "⏱", lipgloss.Color("196")
+	}
+	return "⏳", lipgloss.Color("220")
+}
+
+// setApprovals installs freshly loaded requests, keeping the selection on
+// the same request when it is still listed
+func (m *MainModel) setApprovals(list []orchestrator.Approval) {
+	selected := ""
+	if a, ok := m.selectedApproval(); ok {
+		selected = a.ID
+	}
+	m.approvals = list
+	m.approvalSelected = clamp(m.approvalSelected, 0, max(0, len(list)-1))
+	for i, a := range list {
+		if a.ID == selected {
+			if i != m.approvalSelected {
+				m.approvalFile, m.approvalScroll = 0, 0
+			}
+			m.approvalSelected = i
+		}
+	}
+}
+
+// nextApprovalDeadline returns the pending request that expires first
+func (m MainModel) nextApprovalDeadline() (orchestrator.Approval, bool) {
+	var next orchestrator.Approval
+	found := false
+	for _, a := range m.approvals {
+		d := a.Deadline()
+		if a.Status != orchestrator.ApprovalPending || d.IsZero() {
+			continue
+		}
+		if !found || d.Before(next.Deadline()) {
+			next, found = a, true
+		}
+	}
+	return next, found
+}
+
+// selectedApproval returns the request under the cursor in the approvals panel
+func (m MainModel) selectedApproval() (orchestrator.Approval, bool) {
+	if m.approvalSelected < 0 || m.approvalSelected >= len(m.approvals) {
+		return orchestrator.Approval{}, false
+	}
+	return m.approvals[m.approvalSelected], true
+}
+
+// pendingApprovals counts the requests waiting for an answer
+func (m MainModel) pendingApprovals() int {
+	n := 0
+	for _, a := range m.approvals {
+		if a.Status == orchestrator.ApprovalPending {
+			n++
+		}
+	}
+	return n
+}
+
+// approvalKey handles selection, diff navigation and approve/reject in the
+// approvals panel
+func (m *MainModel) approvalKey(key string) (tea.Cmd, bool) {
+	if m.Tab != tabApprovals {
+		return nil, false
+	}
+	switch key {
+	case "up", "k":
+		m.approvalSelected = max(0, m.approvalSelected-1)
+		m.approvalFile, m.approvalScroll = 0, 0
+	case "down", "j":
+		m.approvalSelected = clamp(m.approvalSelected+1, 0, max(0, len(m.approvals)-1))
+		m.approvalFile, m.approvalScroll = 0, 0
+	case "]":
+		if a, ok := m.selectedApproval(); ok && len(a.Files) > 0 {
+			m.approvalFile, m.approvalScroll = (m.approvalFile+1)%len(a.Files), 0
+		}
+	case "[":
+		if a, ok := m.selectedApproval(); ok && len(a.Files) > 0 {
+			m.approvalFile, m.approvalScroll = (m.approvalFile-1+len(a.Files))%len(a.Files), 0
+		}
+	case "d", "D":
+		if a, ok := m.selectedApproval(); ok {
+			m.openApprovalDiff(a)
+		}
+	case "pgdown", " ":
+		m.approvalScroll += 10
+	case "pgup":
+		m.approvalScroll = max(0, m.approvalScroll-10)
+	case "a", "A", "y", "Y", "r", "R", "n", "N":
+		a, ok := m.selectedApproval()
+		if !ok {
+			return nil, true
+		}
+		if a.Status != orchestrator.ApprovalPending {
+			m.events = append([]string{fmt.Sprintf("[WARN] Request %s is already %s", a.ID, a.Status)}, m.events...)
+			return nil, true
+		}
+		m.InputMode = true
+		m.approvalID = a.ID
+		m.Input.SetValue("")
+		if strings.ContainsAny(key, "aAyY") {
+			m.ActiveCommand = "approve"
+			m.Input.Placeholder = fmt.Sprintf("Approve %s: comment (optional)", a.ID)
+		} else {
+			m.ActiveCommand = "reject"
+			m.Input.Placeholder = fmt.Sprintf("Reject %s: reason (required)", a.ID)
+		}
+		m.Input.Focus()
+		return textinput.Blink, true
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// approvalInput completes the comment / reason prompt of approve and reject
+func (m *MainModel) approvalInput(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if m.ActiveCommand != "approve" && m.ActiveCommand != "reject" {
+		return nil, false
+	}
+	if msg.Type != tea.KeyEnter {
+		return nil, false
+	}
+	text := strings.TrimSpace(m.Input.Value())
+	if m.ActiveCommand == "reject" && text == "" {
+		m.events = append([]string{"[WARN] A reason is required to reject a request"}, m.events...)
+		return nil, true
+	}
+	var cmd tea.Cmd
+	if m.ActiveCommand == "approve" {
+		m.events = append([]string{fmt.Sprintf("Approving request %s...", m.approvalID)}, m.events...)
+		cmd = orchestrator.ApproveCmd(m.approvalID, text)
+	} else {
+		m.events = append([]string{fmt.Sprintf("Rejecting request %s...", m.approvalID)}, m.events...)
+		cmd = orchestrator.RejectCmd(m.approvalID, text)
+	}
+	m.InputMode = false
+	m.ActiveCommand = ""
+	m.approvalID = ""
+	m.Input.SetValue("")
+	m.Input.Blur()
+	return cmd, true
+}
+
+// renderApprovals draws the request list and the selected request below it
+func (m MainModel) renderApprovals(width, height int) string {
+	if len(m.approvals) == 0 {
+		return lipgloss.NewStyle().Foreground(subtle).Render("No approval requests in .claude/approvals.json or tasks.json.")
+	}
+
+	counts := map[string]int{}
+	for _, a := range m.approvals {
+		counts[a.Status]++
+	}
+	summary := fmt.Sprintf("%d requests · ⏳ %d pending · ✓ %d approved · ✗ %d rejected · ⏱ %d expired",
+		len(m.approvals), counts[orchestrator.ApprovalPending], counts[orchestrator.ApprovalApproved],
+		counts[orchestrator.ApprovalRejected], counts[orchestrator.ApprovalExpired])
+
+	const wID, wTask, wAgent, wOp, wDue = 10, 6, 12, 16, 12
+	wDesc := max(10, width-2-wID-wTask-wAgent-wOp-wDue-6)
+	row := func(icon, id, task, agent, op, desc, due string) string {
+		return fmt.Sprintf("%s %-*s %-*s %-*s %-*s %-*s %s", icon, wID, truncate(id, wID), wTask, task, wAgent, truncate(agent, wAgent), wOp, truncate(op, wOp), wDesc, truncate(desc, wDesc), truncate(due, wDue))
+	}
+	lines := []string{
+		lipgloss.NewStyle().Foreground(subtle).Render(summary),
+		lipgloss.NewStyle().Foreground(subtle).Bold(true).Render(row(" ", "ID", "TASK", "AGENT", "OPERATION", "DESCRIPTION", "EXPIRES")),
+	}
+
+	// the list takes up to a third of the panel, scrolled to keep the selection visible
+	listH := clamp(len(m.approvals), 1, max(1, height/3))
+	top := clamp(m.approvalSelected-listH+1, 0, max(0, len(m.approvals)-listH))
+	now := time.Now()
+	for i := top; i < min(len(m.approvals), top+listH); i++ {
+		a := m.approvals[i]
+		icon, color := approvalIcon(a.Status)
+		line := row(lipgloss.NewStyle().Foreground(color).Render(icon), a.ID, fmt.Sprintf("#%d", a.TaskID), a.Agent, a.OperationType, a.Description, approvalDue(a, now))
+		if !m.search.Match(m.approvalTask(a)) {
+			// outside the search
+			line = lipgloss.NewStyle().Foreground(subtle).Render(line)
+		}
+		if i == m.approvalSelected {
+			line = lipgloss.NewStyle().Background(lipgloss.Color("237")).Bold(true).Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	a, _ := m.selectedApproval()
+	lines = append(lines, lipgloss.NewStyle().Foreground(highlight).Render(strings.Repeat("─", width)))
+	lines = append(lines, m.approvalDetail(a, width, max(1, height-len(lines)))...)
+	return strings.Join(lines, "\n")
+}
+
+// approvalDue describes the deadline of a request
+func approvalDue(a orchestrator.Approval, now time.Time) string {
+	if a.Status != orchestrator.ApprovalPending {
+		return a.Status
+	}
+	deadline := a.Deadline()
+	if deadline.IsZero() {
+		return "-"
+	}
+	if !now.Before(deadline) {
+		return "overdue"
+	}
+	return "in " + formatUptime(deadline.Sub(now))
+}
+
+// approvalDetail shows who asked for what, the answer if any and the diff of
+// the selected file
+func (m MainModel) approvalDetail(a orchestrator.Approval, width, height int) []string {
+	head := fmt.Sprintf("%s · task #%d · %s · requested by %s at %s", a.ID, a.TaskID, a.OperationType, orDash(a.RequestedBy), orDash(a.CreatedAt))
+	lines := []string{lipgloss.NewStyle().Foreground(accent).Bold(true).Render(truncate(head, width))}
+	if a.Response != nil {
+		icon, color := approvalIcon(a.Status)
+		answer := fmt.Sprintf("%s %s by %s at %s", icon, a.Status, a.Response.RespondedBy, a.Response.RespondedAt)
+		if a.Response.Comment != "" {
+			answer += ": " + a.Response.Comment
+		}
+		lines = append(lines, lipgloss.NewStyle().Foreground(color).Render(truncate(answer, width)))
+	}
+
+	if len(a.Files) == 0 {
+		if len(a.Details) > 0 {
+			lines = append(lines, truncate("details: "+string(a.Details), width))
+		}
+		return lines
+	}
+	file := a.Files[min(m.approvalFile, len(a.Files)-1)]
+	lines = append(lines, lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("[%d/%d] %s", min(m.approvalFile, len(a.Files)-1)+1, len(a.Files), file.Path)))
+
+	diff := strings.Split(strings.TrimRight(file.Diff, "\n"), "\n")
+	if file.Diff == "" {
+		diff = []string{"(no diff)"}
+	}
+	rows := max(1, height-len(lines))
+	start := clamp(m.approvalScroll, 0, max(0, len(diff)-rows))
+	for _, l := range diff[start:min(len(diff), start+rows)] {
+		lines = append(lines, diffLineStyle(l).Render(cutWidth(strings.ReplaceAll(l, "\t", "    "), width)))
+	}
+	return lines
+}
+
+// diffLineStyle colors a unified diff line
+func diffLineStyle(line string) lipgloss.Style {
+	switch {
+	case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		return lipgloss.NewStyle().Bold(true)
+	case strings.HasPrefix(line, "+"):
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("114"))
+	case strings.HasPrefix(line, "-"):
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
+	case strings.HasPrefix(line, "@@"):
+		return lipgloss.NewStyle().Foreground(accent)
+	}
+	return lipgloss.NewStyle()
+}
+
+// orDash shows "-" for empty values
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
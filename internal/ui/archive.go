@@ -0,0 +1,217 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+// archiveView is the full-screen search over archived tasks
+type archiveView struct {
+	open      bool
+	loading   bool
+	entries   []orchestrator.ArchivedTask // the whole archive, newest first
+	results   []orchestrator.ArchivedTask // entries matching the query
+	cursor    int
+	searching bool
+	input     textinput.Model
+	query     orchestrator.Query
+	err       error // the query does not parse; the last results stay
+}
+
+// openArchive loads the archive for searching
+func (m *MainModel) openArchive() tea.Cmd {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = `words, "phrase", #ID, agent:, status:, prio:, after: or @saved`
+	ti.CharLimit = 120
+	ti.Width = 50
+	m.archive = archiveView{open: true, loading: true, input: ti}
+	return orchestrator.LoadArchiveCmd()
+}
+
+// loadArchive shows the archive read from disk
+func (m *MainModel) loadArchive(msg orchestrator.ArchiveLoadMsg) {
+	v := &m.archive
+	if !v.open {
+		return
+	}
+	v.entries, v.loading = msg, false
+	v.filter(m.searches)
+}
+
+// filter applies the query typed to the archive
+func (v *archiveView) filter(saved []orchestrator.SavedSearch) {
+	q, err := orchestrator.ParseSearch(v.input.Value(), saved)
+	if v.err = err; err != nil {
+		return
+	}
+	v.query = q
+	v.results = v.results[:0]
+	for _, a := range v.entries {
+		if q.Match(a.Task) {
+			v.results = append(v.results, a)
+		}
+	}
+	v.cursor = clamp(v.cursor, 0, max(0, len(v.results)-1))
+}
+
+// archived announces the tasks moved to the archive
+func (m *MainModel) archived(msg orchestrator.ArchivedMsg) tea.Cmd {
+	if len(msg) == 0 {
+		if m.archive.open {
+			m.events = append([]string{"Nothing to archive yet"}, m.events...)
+		}
+		return nil
+	}
+	ids := make([]int, len(msg))
+	for i, a := range msg {
+		ids[i] = a.Task.ID
+	}
+	m.events = append([]string{fmt.Sprintf("Archived %d finished task(s): %s", len(msg), orchestrator.FormatIDs(ids))}, m.events...)
+	cmds := []tea.Cmd{orchestrator.FetchTasksCmd()}
+	if m.archive.open {
+		cmds = append(cmds, orchestrator.LoadArchiveCmd())
+	}
+	return tea.Batch(cmds...)
+}
+
+// archiveKey handles the keys of the archive search while it is open
+func (m *MainModel) archiveKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	v := &m.archive
+	if !v.open || msg.String() == "ctrl+c" {
+		return nil, false
+	}
+	if v.searching {
+		switch msg.Type {
+		case tea.KeyEnter, tea.KeyEsc:
+			v.searching = false
+			v.input.Blur()
+			return nil, true
+		}
+		var cmd tea.Cmd
+		v.input, cmd = v.input.Update(msg)
+		v.filter(m.searches)
+		return cmd, true
+	}
+
+	switch msg.String() {
+	case "esc", "q", "z", "Z":
+		m.archive = archiveView{}
+		return nil, true
+	case "/":
+		v.searching = true
+		v.input.Focus()
+		return textinput.Blink, true
+	case "a", "A":
+		m.events = append([]string{"Archiving expired tasks..."}, m.events...)
+		return orchestrator.ArchiveExpiredCmd(false), true
+	}
+	if v.loading || len(v.results) == 0 {
+		return nil, true
+	}
+	a := v.results[v.cursor]
+	switch msg.String() {
+	case "up", "k":
+		v.cursor = max(0, v.cursor-1)
+	case "down", "j":
+		v.cursor = min(len(v.results)-1, v.cursor+1)
+	case "pgup":
+		v.cursor = max(0, v.cursor-10)
+	case "pgdown":
+		v.cursor = min(len(v.results)-1, v.cursor+10)
+	case "r", "R", "enter":
+		m.events = append([]string{fmt.Sprintf("Restoring task #%d from the archive...", a.Task.ID)}, m.events...)
+		return orchestrator.RestoreCmd(a.Task.ID), true
+	case "l", "L":
+		return m.openArchivedLog(a), true
+	}
+	return nil, true
+}
+
+// openArchivedLog opens the run log kept with an archived task, or the log
+// of its agent when the task had none of its own
+func (m *MainModel) openArchivedLog(a orchestrator.ArchivedTask) tea.Cmd {
+	p, err := orchestrator.CurrentProject()
+	if err != nil {
+		m.events = append([]string{fmt.Sprintf("[ERROR] %v", err)}, m.events...)
+		return nil
+	}
+	path := p.TaskLogFile(a.Task)
+	if a.Log != "" {
+		path = filepath.Join(p.Root, a.Log)
+	}
+	if path == "" {
+		m.events = append([]string{fmt.Sprintf("[WARN] No logs kept for task #%d", a.Task.ID)}, m.events...)
+		return nil
+	}
+	return m.openLog(fmt.Sprintf("Task #%d (archived)", a.Task.ID), path, false)
+}
+
+// restored puts a restored task back in view
+func (m *MainModel) restored(msg orchestrator.RestoredMsg) tea.Cmd {
+	m.events = append([]string{fmt.Sprintf("Restored task #%d (%s)", msg.ID, msg.Status)}, m.events...)
+	cmds := []tea.Cmd{orchestrator.FetchTasksCmd()}
+	if m.archive.open {
+		cmds = append(cmds, orchestrator.LoadArchiveCmd())
+	}
+	return tea.Batch(cmds...)
+}
+
+// renderArchive lists the matching archived tasks, one line each
+func (m MainModel) renderArchive(width, height int) string {
+	v := m.archive
+	if v.loading {
+		return m.Spinner.View() + " Reading the archive..."
+	}
+	muted := lipgloss.NewStyle().Foreground(subtle)
+	head := fmt.Sprintf("%d of %d archived task(s)", len(v.results), len(v.entries))
+	if !v.query.Empty() {
+		head += " matching " + v.query.Text
+	}
+	lines := []string{muted.Render(cutWidth(head, width))}
+	if v.err != nil {
+		lines[0] += "  " + lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(cutWidth(v.err.Error(), max(1, width-lipgloss.Width(lines[0])-2)))
+	}
+	if v.searching || v.input.Value() != "" {
+		lines = append(lines, v.input.View())
+	}
+	if len(v.results) == 0 {
+		lines = append(lines, muted.Render("(nothing archived matches)"))
+		return strings.Join(lines, "\n")
+	}
+
+	rows := max(1, height-len(lines))
+	top := clamp(v.cursor-rows/2, 0, max(0, len(v.results)-rows))
+	for i := top; i < min(len(v.results), top+rows); i++ {
+		t := v.results[i].Task
+		finished := "          "
+		if at := orchestrator.Finished(t); !at.IsZero() {
+			finished = at.Local().Format("2006-01-02")
+		}
+		agent := "-"
+		if t.Agent != "" {
+			agent = m.catalog.DisplayName(t.Agent)
+		}
+		prefix := "  "
+		style := lipgloss.NewStyle()
+		if i == v.cursor {
+			prefix = "▶ "
+			style = style.Background(lipgloss.Color("237")).Bold(true)
+		}
+		status := lipgloss.NewStyle().Foreground(special)
+		if t.Status == orchestrator.StatusFailed {
+			status = status.Foreground(lipgloss.Color("196"))
+		}
+		text := fmt.Sprintf("#%-4d %s  %-12s ", t.ID, finished, cutWidth(agent, 12))
+		label := fmt.Sprintf("%-9s ", t.Status)
+		desc := cutWidth(t.Description, max(1, width-lipgloss.Width(prefix+text+label)))
+		lines = append(lines, style.Render(prefix+text)+status.Render(label)+markWords(desc, v.query.Words(), style))
+	}
+	return strings.Join(lines, "\n")
+}
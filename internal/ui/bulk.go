@@ -0,0 +1,215 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+// currentList returns the task list of the tab shown, or nil
+func (m *MainModel) currentList() *list.Model {
+	switch m.Tab {
+	case tabPending:
+		return &m.pendingList
+	case tabActive:
+		return &m.activeList
+	case tabComplete:
+		return &m.completeList
+	}
+	return nil
+}
+
+// markedIDs returns the marked tasks in ID order
+func (m MainModel) markedIDs() []int {
+	var ids []int
+	for id := range m.marked {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// setMark marks or unmarks a task
+func (m *MainModel) setMark(id int, on bool) {
+	if id <= 0 {
+		return
+	}
+	if m.marked == nil {
+		m.marked = map[int]bool{}
+	}
+	if on {
+		m.marked[id] = true
+	} else {
+		delete(m.marked, id)
+	}
+}
+
+// pruneMarks forgets marked tasks that no longer exist
+func (m *MainModel) pruneMarks() {
+	exists := map[int]bool{}
+	for _, t := range m.Tasks {
+		exists[t.ID] = true
+	}
+	for id := range m.marked {
+		if !exists[id] {
+			delete(m.marked, id)
+		}
+	}
+}
+
+// bulkVerb describes an action for the confirmation and the summary
+func bulkVerb(a orchestrator.BulkAction) string {
+	switch a.Kind {
+	case orchestrator.BulkAssign:
+		if a.Arg == "" {
+			return "unassign"
+		}
+		return "assign to " + a.Arg
+	case orchestrator.BulkRaise:
+		return "raise the priority of"
+	case orchestrator.BulkLower:
+		return "lower the priority of"
+	}
+	return a.Kind
+}
+
+// askBulk runs an action on the marked tasks once confirmed. Bulk actions
+// are always confirmed; the safety policy may ask for more.
+func (m *MainModel) askBulk(a orchestrator.BulkAction) tea.Cmd {
+	ids := m.markedIDs()
+	action := a.Kind
+	if a.Kind == orchestrator.BulkRaise || a.Kind == orchestrator.BulkLower {
+		action = orchestrator.ActionPriority
+	}
+	verb := bulkVerb(a)
+	question := fmt.Sprintf("%s %d task(s): %s?", strings.ToUpper(verb[:1])+verb[1:], len(ids), orchestrator.FormatIDs(ids))
+	event := fmt.Sprintf("Bulk: %s %s...", verb, orchestrator.FormatIDs(ids))
+	return m.guard(action, ids, orchestrator.ConfirmYes, question, event, orchestrator.BulkCmd(a, ids))
+}
+
+// bulkKey marks tasks in the lists and, while tasks are marked, turns the
+// task actions into bulk actions on them
+func (m *MainModel) bulkKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if l := m.currentList(); l != nil {
+		switch msg.String() {
+		case " ":
+			if it, ok := l.SelectedItem().(item); ok {
+				m.setMark(it.id, !m.marked[it.id])
+				m.refreshLists()
+			}
+			return nil, true
+		case "shift+down", "shift+up":
+			// Range selection: mark the task under the cursor and the next one
+			if it, ok := l.SelectedItem().(item); ok {
+				m.setMark(it.id, true)
+			}
+			if msg.String() == "shift+down" {
+				l.CursorDown()
+			} else {
+				l.CursorUp()
+			}
+			if it, ok := l.SelectedItem().(item); ok {
+				m.setMark(it.id, true)
+			}
+			m.refreshLists()
+			return nil, true
+		case "ctrl+a":
+			// Every task shown, so a list filter or the agent filter narrows it
+			visible := l.VisibleItems()
+			all := len(visible) > 0
+			for _, v := range visible {
+				all = all && m.marked[v.(item).id]
+			}
+			for _, v := range visible {
+				m.setMark(v.(item).id, !all)
+			}
+			m.refreshLists()
+			return nil, true
+		}
+	}
+
+	if len(m.marked) == 0 || m.Tab == tabAgents || m.Tab == tabApprovals {
+		return nil, false
+	}
+	switch msg.String() {
+	case "s", "S":
+		return m.askBulk(orchestrator.BulkAction{Kind: orchestrator.BulkStart}), true
+	case "x", "X", "t", "T", "k", "K":
+		return m.askBulk(orchestrator.BulkAction{Kind: orchestrator.BulkStop}), true
+	case "c", "C":
+		return m.askBulk(orchestrator.BulkAction{Kind: orchestrator.BulkComplete}), true
+	case "d", "backspace":
+		return m.askBulk(orchestrator.BulkAction{Kind: orchestrator.BulkRemove}), true
+	case "+", "=":
+		return m.askBulk(orchestrator.BulkAction{Kind: orchestrator.BulkRaise}), true
+	case "-", "_":
+		return m.askBulk(orchestrator.BulkAction{Kind: orchestrator.BulkLower}), true
+	case "@":
+		m.InputMode = true
+		m.ActiveCommand = "bulk-assign"
+		m.Input.Placeholder = fmt.Sprintf("Agent for %d marked task(s) (- to unassign)", len(m.marked))
+		m.Input.SetValue("")
+		m.Input.Focus()
+		return textinput.Blink, true
+	}
+	return nil, false
+}
+
+// bulkInput completes the agent prompt of a bulk reassignment
+func (m *MainModel) bulkInput(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if m.ActiveCommand != "bulk-assign" || msg.Type != tea.KeyEnter {
+		return nil, false
+	}
+	agent := strings.TrimSpace(m.Input.Value())
+	if agent == "-" {
+		agent = ""
+	} else if _, ok := m.catalog.Lookup(agent); !ok {
+		m.events = append([]string{fmt.Sprintf("[WARN] Unknown agent %q", agent)}, m.events...)
+		return nil, true
+	}
+	m.InputMode = false
+	m.ActiveCommand = ""
+	m.Input.SetValue("")
+	m.Input.Blur()
+	return m.askBulk(orchestrator.BulkAction{Kind: orchestrator.BulkAssign, Arg: agent}), true
+}
+
+// bulkDone logs the outcome of every task and keeps the failed ones marked
+// so the action can be retried
+func (m *MainModel) bulkDone(msg orchestrator.BulkDoneMsg) tea.Cmd {
+	ok := 0
+	m.marked = map[int]bool{}
+	var lines []string
+	for _, r := range msg.Results {
+		if r.Err != nil {
+			lines = append(lines, fmt.Sprintf("[ERROR]   #%d: %v", r.ID, r.Err))
+			m.marked[r.ID] = true
+			continue
+		}
+		ok++
+		status := r.Task.Status
+		if msg.Action.Kind == orchestrator.BulkRemove {
+			status = "removed"
+		} else if msg.Action.Kind == orchestrator.BulkRaise || msg.Action.Kind == orchestrator.BulkLower {
+			status = r.Task.Priority
+		} else if msg.Action.Kind == orchestrator.BulkAssign {
+			status = orDash(r.Task.Agent)
+		}
+		lines = append(lines, fmt.Sprintf("  #%d: ok (%s)", r.ID, status))
+	}
+	summary := fmt.Sprintf("Bulk %s: %d/%d task(s) done", bulkVerb(msg.Action), ok, len(msg.Results))
+	if ok < len(msg.Results) {
+		summary = "[WARN] " + summary + "; failed ones stay marked"
+	}
+	// newest first, with the summary above its details
+	for i := len(lines) - 1; i >= 0; i-- {
+		m.events = append([]string{lines[i]}, m.events...)
+	}
+	m.events = append([]string{summary}, m.events...)
+	return orchestrator.FetchTasksCmd()
+}
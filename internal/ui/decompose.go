@@ -0,0 +1,261 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+// decomposeView is the full-screen review of a proposed task decomposition
+type decomposeView struct {
+	open    bool
+	task    string // description being decomposed
+	plan    orchestrator.Decomposition
+	source  string // backend that proposed the plan
+	attempt int    // requests made for the task so far
+	cursor  int
+	loading bool
+	editing string // field edited in input: desc, add, deps or feedback ("" = none)
+	input   textinput.Model
+}
+
+// openDecompose starts reviewing a decomposition of desc
+func (m *MainModel) openDecompose(desc string) tea.Cmd {
+	ti := textinput.New()
+	ti.CharLimit = 300
+	ti.Width = 60
+	m.decompose = decomposeView{open: true, task: desc, loading: true, attempt: 1, input: ti}
+	m.events = append([]string{fmt.Sprintf("Decomposing: %s...", desc)}, m.events...)
+	return orchestrator.DecomposeCmd(orchestrator.DecomposeRequest{Task: desc, Attempt: 1})
+}
+
+// loadDecomposition shows a proposed plan
+func (m *MainModel) loadDecomposition(msg orchestrator.DecompositionMsg) {
+	v := &m.decompose
+	if !v.open || msg.Task != v.task || msg.Attempt != v.attempt {
+		return // cancelled or asked again meanwhile
+	}
+	v.plan, v.source, v.loading = msg.Plan, msg.Source, false
+	v.cursor = clamp(v.cursor, 0, max(0, len(v.plan.Subtasks)-1))
+	switch {
+	case msg.Source == "single":
+		m.events = append([]string{fmt.Sprintf("[WARN] No decomposition (%v); proposing the task as is", msg.Err)}, m.events...)
+		return
+	case msg.Err != nil:
+		m.events = append([]string{fmt.Sprintf("[WARN] Decomposed with %s instead: %v", msg.Source, msg.Err)}, m.events...)
+	}
+	m.events = append([]string{fmt.Sprintf("%s proposed %d subtask(s) for review", msg.Source, len(v.plan.Subtasks))}, m.events...)
+}
+
+// subtaskAgents are the agents a subtask can be reassigned to ("" = unassigned)
+func (m MainModel) subtaskAgents() []string {
+	return append([]string{""}, m.catalog.Names()...)
+}
+
+// cycleSubtaskAgent reassigns the selected subtask to the next (or previous) agent
+func (m *MainModel) cycleSubtaskAgent(delta int) {
+	v := &m.decompose
+	agents := m.subtaskAgents()
+	s := &v.plan.Subtasks[v.cursor]
+	i := 0
+	for j, a := range agents {
+		if a == s.Agent {
+			i = j
+		}
+	}
+	s.Agent = agents[(i+delta+len(agents))%len(agents)]
+}
+
+// editSubtask opens the input for field, prefilled with value
+func (m *MainModel) editSubtask(field, placeholder, value string) tea.Cmd {
+	v := &m.decompose
+	v.editing = field
+	v.input.Placeholder = placeholder
+	v.input.SetValue(value)
+	v.input.CursorEnd()
+	v.input.Focus()
+	return textinput.Blink
+}
+
+// parseSubtaskDeps reads 1-based subtask numbers separated by commas or spaces
+func parseSubtaskDeps(s string, n int) ([]int, error) {
+	var deps []int
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		k, err := strconv.Atoi(strings.TrimPrefix(f, "#"))
+		if err != nil || k < 1 || k > n {
+			return nil, fmt.Errorf("no subtask %q", f)
+		}
+		deps = append(deps, k-1)
+	}
+	return deps, nil
+}
+
+// decomposeInput handles the keys of the field being edited
+func (m *MainModel) decomposeInput(msg tea.KeyMsg) tea.Cmd {
+	v := &m.decompose
+	switch msg.Type {
+	case tea.KeyEsc:
+		v.editing = ""
+		v.input.Blur()
+		return nil
+	case tea.KeyEnter:
+	default:
+		var cmd tea.Cmd
+		v.input, cmd = v.input.Update(msg)
+		return cmd
+	}
+
+	value := strings.TrimSpace(v.input.Value())
+	switch v.editing {
+	case "desc":
+		if value == "" {
+			m.events = append([]string{"[WARN] A subtask needs a description"}, m.events...)
+			return nil
+		}
+		v.plan.Subtasks[v.cursor].Description = value
+	case "add":
+		if value == "" {
+			m.events = append([]string{"[WARN] A subtask needs a description"}, m.events...)
+			return nil
+		}
+		v.plan.Subtasks = append(v.plan.Subtasks, orchestrator.Subtask{Description: value, Rationale: "added by hand"})
+		v.cursor = len(v.plan.Subtasks) - 1
+	case "deps":
+		deps, err := parseSubtaskDeps(value, len(v.plan.Subtasks))
+		if err != nil {
+			m.events = append([]string{fmt.Sprintf("[WARN] %v", err)}, m.events...)
+			return nil
+		}
+		v.plan.Subtasks[v.cursor].Dependencies = deps
+	case "feedback":
+		plan := v.plan
+		v.loading = true
+		v.attempt++
+		m.events = append([]string{fmt.Sprintf("Re-asking with feedback: %s", value)}, m.events...)
+		v.editing = ""
+		v.input.Blur()
+		return orchestrator.DecomposeCmd(orchestrator.DecomposeRequest{Task: v.task, Feedback: value, Previous: &plan, Attempt: v.attempt})
+	}
+	v.editing = ""
+	v.input.Blur()
+	return nil
+}
+
+// decomposeKey handles the keys of the decomposition review while it is open
+func (m *MainModel) decomposeKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	v := &m.decompose
+	if !v.open || msg.String() == "ctrl+c" {
+		return nil, false
+	}
+	if v.editing != "" {
+		return m.decomposeInput(msg), true
+	}
+	if msg.String() == "esc" || msg.String() == "q" {
+		m.events = append([]string{"Decomposition cancelled"}, m.events...)
+		m.decompose = decomposeView{}
+		return nil, true
+	}
+	if v.loading {
+		return nil, true
+	}
+
+	n := len(v.plan.Subtasks)
+	switch msg.String() {
+	case "a", "A":
+		return m.editSubtask("add", "New subtask description", ""), true
+	case "f", "F":
+		return m.editSubtask("feedback", "What should change in this plan?", ""), true
+	case "enter", "c":
+		if err := v.plan.Validate(); err != nil {
+			m.events = append([]string{fmt.Sprintf("[ERROR] %v", err)}, m.events...)
+			return nil, true
+		}
+		plan := v.plan
+		m.events = append([]string{fmt.Sprintf("Adding %d subtask(s) of: %s", n, v.task)}, m.events...)
+		m.decompose = decomposeView{}
+		return orchestrator.AddSubtasksCmd(plan), true
+	}
+	if n == 0 {
+		return nil, true
+	}
+	switch msg.String() {
+	case "up", "k":
+		v.cursor = max(0, v.cursor-1)
+	case "down", "j":
+		v.cursor = min(n-1, v.cursor+1)
+	case "K", "shift+up":
+		v.cursor = v.plan.Move(v.cursor, -1)
+	case "J", "shift+down":
+		v.cursor = v.plan.Move(v.cursor, 1)
+	case "tab", "right", "l":
+		m.cycleSubtaskAgent(1)
+	case "shift+tab", "left", "h":
+		m.cycleSubtaskAgent(-1)
+	case "e", "E":
+		return m.editSubtask("desc", "Subtask description", v.plan.Subtasks[v.cursor].Description), true
+	case "p", "P":
+		var deps []string
+		for _, d := range v.plan.Subtasks[v.cursor].Dependencies {
+			deps = append(deps, strconv.Itoa(d+1))
+		}
+		return m.editSubtask("deps", "Subtask numbers it depends on, e.g. 1,2", strings.Join(deps, ",")), true
+	case "d", "x", "delete":
+		v.plan.Remove(v.cursor)
+		v.cursor = clamp(v.cursor, 0, max(0, len(v.plan.Subtasks)-1))
+	}
+	return nil, true
+}
+
+// renderDecompose lists the proposed subtasks, two lines each
+func (m MainModel) renderDecompose(width, height int) string {
+	v := m.decompose
+	if v.loading {
+		return m.Spinner.View() + " Asking for subtasks of: " + v.task
+	}
+	muted := lipgloss.NewStyle().Foreground(subtle)
+	head := fmt.Sprintf("%d subtask(s) · proposed by %s (attempt %d)", len(v.plan.Subtasks), v.source, v.attempt)
+	lines := []string{muted.Render(cutWidth(head, width))}
+	if len(v.plan.Subtasks) == 0 {
+		lines = append(lines, muted.Render("(no subtasks: [A] to add one)"))
+	}
+
+	rows := max(1, (height-1)/2)
+	top := clamp(v.cursor-rows/2, 0, max(0, len(v.plan.Subtasks)-rows))
+	for i := top; i < min(len(v.plan.Subtasks), top+rows); i++ {
+		s := v.plan.Subtasks[i]
+		agent := "unassigned"
+		color := lipgloss.Color("244")
+		if s.Agent != "" {
+			agent, color = m.catalog.DisplayName(s.Agent), agentColor(m.catalog, s.Agent)
+		}
+		deps := ""
+		if len(s.Dependencies) > 0 {
+			var nums []string
+			for _, d := range s.Dependencies {
+				nums = append(nums, strconv.Itoa(d+1))
+			}
+			deps = "  after " + strings.Join(nums, ",")
+		}
+		prefix := "  "
+		style := lipgloss.NewStyle()
+		if i == v.cursor {
+			prefix = "▶ "
+			style = style.Background(lipgloss.Color("237")).Bold(true)
+		}
+		badge := lipgloss.NewStyle().Foreground(color).Render("[" + agent + "]")
+		text := fmt.Sprintf("%d. ", i+1)
+		desc := cutWidth(s.Description, max(1, width-lipgloss.Width(prefix+text+"["+agent+"] "+deps)))
+		lines = append(lines, style.Render(prefix+text)+badge+style.Render(" "+desc)+muted.Render(deps))
+		why := s.Rationale
+		if why == "" {
+			why = "-"
+		}
+		lines = append(lines, muted.Render(cutWidth("     "+why, width)))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,339 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+// diffSidebarWidth is the width of the file list, shown when there is room
+const diffSidebarWidth = 32
+
+var (
+	diffAddedStyle   = lipgloss.NewStyle().Background(lipgloss.Color("22"))
+	diffRemovedStyle = lipgloss.NewStyle().Background(lipgloss.Color("52"))
+	diffGutterStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	diffHunkStyle    = lipgloss.NewStyle().Foreground(accent)
+)
+
+// diffView is the full-screen diff review pane
+type diffView struct {
+	open   bool
+	title  string
+	files  []orchestrator.FileDiff
+	file   int          // file shown
+	split  bool         // side-by-side instead of unified
+	folded map[int]bool // folded hunks of the current file
+	hunk   int          // hunk under the cursor
+	scroll int          // first row shown
+}
+
+// diffRow is one rendered row of a file: a hunk header, or a line (unified)
+// or a pair of lines (side-by-side) of that hunk
+type diffRow struct {
+	hunk   int
+	header bool
+	left   *orchestrator.DiffLine
+	right  *orchestrator.DiffLine
+}
+
+// openDiff shows files in the diff pane
+func (m *MainModel) openDiff(title string, files []orchestrator.FileDiff) {
+	split := m.diffView.split
+	m.diffView = diffView{open: true, title: title, files: files, split: split, folded: map[int]bool{}}
+}
+
+// openApprovalDiff reviews the files attached to an approval request
+func (m *MainModel) openApprovalDiff(a orchestrator.Approval) {
+	if len(a.Files) == 0 {
+		m.events = append([]string{fmt.Sprintf("[WARN] Request %s has no file changes", a.ID)}, m.events...)
+		return
+	}
+	m.openDiff(fmt.Sprintf("request %s (task #%d)", a.ID, a.TaskID), orchestrator.ApprovalDiffs(a.Files))
+}
+
+// loadDiff opens a diff computed in the background
+func (m *MainModel) loadDiff(msg orchestrator.DiffLoadMsg) {
+	if msg.Err != nil {
+		m.events = append([]string{fmt.Sprintf("[ERROR] Diff of %s: %v", msg.Title, msg.Err)}, m.events...)
+		return
+	}
+	if len(msg.Files) == 0 {
+		m.events = append([]string{fmt.Sprintf("No changes for %s", msg.Title)}, m.events...)
+		return
+	}
+	m.openDiff(msg.Title, msg.Files)
+}
+
+// diffRows lays out the current file
+func (v diffView) diffRows() []diffRow {
+	if v.file >= len(v.files) {
+		return nil
+	}
+	var rows []diffRow
+	for i, h := range v.files[v.file].Hunks {
+		rows = append(rows, diffRow{hunk: i, header: true})
+		if v.folded[i] {
+			continue
+		}
+		if !v.split {
+			for j := range h.Lines {
+				rows = append(rows, diffRow{hunk: i, left: &h.Lines[j]})
+			}
+			continue
+		}
+		// side-by-side: removed lines pair up with the added lines after them
+		for j := 0; j < len(h.Lines); {
+			l := &h.Lines[j]
+			if l.Kind != orchestrator.DiffRemoved && l.Kind != orchestrator.DiffAdded {
+				rows = append(rows, diffRow{hunk: i, left: l, right: l})
+				j++
+				continue
+			}
+			var removed, added []*orchestrator.DiffLine
+			for ; j < len(h.Lines) && h.Lines[j].Kind == orchestrator.DiffRemoved; j++ {
+				removed = append(removed, &h.Lines[j])
+			}
+			for ; j < len(h.Lines) && h.Lines[j].Kind == orchestrator.DiffAdded; j++ {
+				added = append(added, &h.Lines[j])
+			}
+			for k := 0; k < max(len(removed), len(added)); k++ {
+				r := diffRow{hunk: i}
+				if k < len(removed) {
+					r.left = removed[k]
+				}
+				if k < len(added) {
+					r.right = added[k]
+				}
+				rows = append(rows, r)
+			}
+		}
+	}
+	return rows
+}
+
+// diffPageHeight is the number of rows shown in the pane
+func (m MainModel) diffPageHeight() int {
+	listH, logH := m.bodyHeights(renderAgentStrip(m.agentStates) != "")
+	return max(1, listH+logH-4) // borders, title and the file line
+}
+
+// scrollDiff moves the view and keeps the hunk cursor on the top row
+func (m *MainModel) scrollDiff(delta int) {
+	v := &m.diffView
+	rows := v.diffRows()
+	v.scroll = clamp(v.scroll+delta, 0, max(0, len(rows)-1))
+	if v.scroll < len(rows) {
+		v.hunk = rows[v.scroll].hunk
+	}
+}
+
+// jumpToHunk moves the cursor to the next (or previous) hunk
+func (m *MainModel) jumpToHunk(delta int) {
+	v := &m.diffView
+	n := len(v.files[v.file].Hunks)
+	if n == 0 {
+		return
+	}
+	v.hunk = clamp(v.hunk+delta, 0, n-1)
+	for i, r := range v.diffRows() {
+		if r.header && r.hunk == v.hunk {
+			v.scroll = i
+			break
+		}
+	}
+}
+
+// selectDiffFile shows another file from its top
+func (m *MainModel) selectDiffFile(i int) {
+	v := &m.diffView
+	n := len(v.files)
+	v.file = (i + n) % n
+	v.folded = map[int]bool{}
+	v.hunk, v.scroll = 0, 0
+}
+
+// diffKey handles the keys of the diff pane while it is open
+func (m *MainModel) diffKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	v := &m.diffView
+	if !v.open || msg.String() == "ctrl+c" {
+		return nil, false
+	}
+	page := m.diffPageHeight()
+	switch msg.String() {
+	case "esc", "q":
+		m.diffView = diffView{split: v.split}
+	case "up", "k":
+		m.scrollDiff(-1)
+	case "down", "j":
+		m.scrollDiff(1)
+	case "pgup", "b":
+		m.scrollDiff(-page)
+	case "pgdown", " ":
+		m.scrollDiff(page)
+	case "g", "home":
+		m.scrollDiff(-len(v.diffRows()))
+	case "G", "end":
+		m.scrollDiff(len(v.diffRows()))
+	case "n", "}":
+		m.jumpToHunk(1)
+	case "N", "p", "{":
+		m.jumpToHunk(-1)
+	case "]", "tab":
+		m.selectDiffFile(v.file + 1)
+	case "[", "shift+tab":
+		m.selectDiffFile(v.file - 1)
+	case "enter", "z":
+		v.folded[v.hunk] = !v.folded[v.hunk]
+		m.jumpToHunk(0)
+	case "Z":
+		fold := len(v.folded) == 0
+		v.folded = map[int]bool{}
+		if fold {
+			for i := range v.files[v.file].Hunks {
+				v.folded[i] = true
+			}
+		}
+		m.jumpToHunk(0)
+	case "s", "S":
+		v.split = !v.split
+		m.jumpToHunk(0)
+	}
+	return nil, true
+}
+
+// renderDiff draws the file list and the current file
+func (m MainModel) renderDiff(width, height int) string {
+	v := m.diffView
+	if len(v.files) == 0 {
+		return ""
+	}
+	body := width
+	var sidebar string
+	if width >= 100 {
+		body = width - diffSidebarWidth - 1
+		sidebar = m.renderDiffFiles(diffSidebarWidth, height)
+	}
+
+	f := v.files[v.file]
+	added, removed := f.Stats()
+	mode := "unified"
+	if v.split {
+		mode = "side-by-side"
+	}
+	name := f.Path
+	if f.OldPath != "" {
+		name = f.OldPath + " → " + f.Path
+	}
+	head := fmt.Sprintf("[%d/%d] %s  %s %s  %s  (%s)", v.file+1, len(v.files), name,
+		lipgloss.NewStyle().Foreground(lipgloss.Color("114")).Render(fmt.Sprintf("+%d", added)),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render(fmt.Sprintf("-%d", removed)),
+		f.Status, mode)
+	lines := []string{lipgloss.NewStyle().Bold(true).Render(head)}
+
+	rows := v.diffRows()
+	if len(rows) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(subtle).Render("(no textual changes)"))
+	}
+	h := max(1, height-1)
+	top := clamp(v.scroll, 0, max(0, len(rows)-1))
+	lang := syntaxFor(f.Path)
+	for _, r := range rows[top:min(len(rows), top+h)] {
+		lines = append(lines, m.renderDiffRow(f, r, lang, body))
+	}
+
+	out := strings.Join(lines, "\n")
+	if sidebar == "" {
+		return out
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, sidebar, " ", lipgloss.NewStyle().Width(body).Render(out))
+}
+
+// renderDiffFiles lists the files with their change counts
+func (m MainModel) renderDiffFiles(width, height int) string {
+	v := m.diffView
+	var lines []string
+	top := clamp(v.file-height/2, 0, max(0, len(v.files)-height))
+	for i := top; i < min(len(v.files), top+height); i++ {
+		f := v.files[i]
+		added, removed := f.Stats()
+		stats := fmt.Sprintf("+%d -%d", added, removed)
+		mark := map[string]string{"added": "A", "deleted": "D", "renamed": "R", "binary": "B"}[f.Status]
+		if mark == "" {
+			mark = "M"
+		}
+		name := filepath.Base(f.Path)
+		line := fmt.Sprintf("%s %-*s %s", mark, max(1, width-len(stats)-3), truncate(name, max(1, width-len(stats)-3)), stats)
+		style := lipgloss.NewStyle().Foreground(subtle)
+		if i == v.file {
+			style = lipgloss.NewStyle().Background(lipgloss.Color("237")).Bold(true)
+		}
+		lines = append(lines, style.Render(line))
+	}
+	return lipgloss.NewStyle().Width(width).Render(strings.Join(lines, "\n"))
+}
+
+// renderDiffRow draws a hunk header or its lines
+func (m MainModel) renderDiffRow(f orchestrator.FileDiff, r diffRow, lang string, width int) string {
+	v := m.diffView
+	if r.header {
+		h := f.Hunks[r.hunk]
+		fold := "▾"
+		if v.folded[r.hunk] {
+			fold = fmt.Sprintf("▸ (%d lines)", len(h.Lines))
+		}
+		header := h.Header
+		if header == "" {
+			header = "@@ change @@"
+		}
+		style := diffHunkStyle
+		if r.hunk == v.hunk {
+			style = style.Background(lipgloss.Color("237")).Bold(true)
+		}
+		return style.Render(cutWidth(fold+" "+header, width))
+	}
+	if !v.split {
+		return renderDiffLine(r.left, lang, width, true)
+	}
+	half := max(1, (width-1)/2)
+	return renderDiffLine(r.left, lang, half, false) + diffGutterStyle.Render("│") + renderDiffLine(r.right, lang, half, false)
+}
+
+// renderDiffLine draws one line with its line numbers, padded to width.
+// In unified mode both numbers are shown; side-by-side shows the side's own.
+func renderDiffLine(l *orchestrator.DiffLine, lang string, width int, unified bool) string {
+	if l == nil {
+		return strings.Repeat(" ", width)
+	}
+	base := lipgloss.NewStyle()
+	sign := " "
+	switch l.Kind {
+	case orchestrator.DiffAdded:
+		base, sign = diffAddedStyle, "+"
+	case orchestrator.DiffRemoved:
+		base, sign = diffRemovedStyle, "-"
+	case orchestrator.DiffNote:
+		return diffGutterStyle.Render(cutWidth(`\`+l.Text, width))
+	}
+	num := func(n int) string {
+		if n == 0 {
+			return "    "
+		}
+		return fmt.Sprintf("%4d", n)
+	}
+	gutter := num(l.New)
+	if l.Kind == orchestrator.DiffRemoved {
+		gutter = num(l.Old)
+	}
+	if unified {
+		gutter = num(l.Old) + " " + num(l.New)
+	}
+	gutter += " " + sign + " "
+	text := cutWidth(strings.ReplaceAll(l.Text, "\t", "    "), max(0, width-lipgloss.Width(gutter)))
+	pad := strings.Repeat(" ", max(0, width-lipgloss.Width(gutter)-lipgloss.Width(text)))
+	return diffGutterStyle.Inherit(base).Render(gutter) + highlightSyntax(text, lang, base) + base.Render(pad)
+}
@@ -0,0 +1,401 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+const (
+	graphNodeWidth  = 26 // total width of a node box including borders
+	graphNodeHeight = 4  // two content lines plus borders
+	graphGutter     = 5  // width of the edges drawn between two columns
+)
+
+// cycleEvents warns about the dependency cycles of next that prev did not
+// have, so reloading the same tasks does not repeat the warnings
+func cycleEvents(prev, next []orchestrator.Task) []string {
+	known := make(map[string]bool)
+	for _, c := range orchestrator.NewGraph(prev).Cycles() {
+		known[orchestrator.FormatIDs(c)] = true
+	}
+	var events []string
+	for _, c := range orchestrator.NewGraph(next).Cycles() {
+		if ids := orchestrator.FormatIDs(c); !known[ids] {
+			events = append(events, fmt.Sprintf("[WARN] Dependency cycle between %s", ids))
+		}
+	}
+	return events
+}
+
+// graphLayers groups task IDs into columns: a task sits one column to the
+// right of its deepest dependency. Tasks caught in a cycle have no depth and
+// are put in an extra last column so they stay visible.
+func graphLayers(g *orchestrator.Graph) [][]int {
+	order, _ := g.TopoOrder()
+	depth := make(map[int]int, len(order))
+	maxDepth := -1
+	for _, id := range order {
+		d := 0
+		for _, dep := range g.Dependencies(id) {
+			if dd, ok := depth[dep]; ok && dd+1 > d {
+				d = dd + 1
+			}
+		}
+		depth[id] = d
+		if d > maxDepth {
+			maxDepth = d
+		}
+	}
+
+	layers := make([][]int, maxDepth+1)
+	var cyclic []int
+	for _, id := range g.IDs() {
+		if d, ok := depth[id]; ok {
+			layers[d] = append(layers[d], id)
+		} else {
+			cyclic = append(cyclic, id)
+		}
+	}
+	if len(cyclic) > 0 {
+		layers = append(layers, cyclic)
+	}
+	return layers
+}
+
+// graphPosition returns the column and row of id, or -1, -1
+func graphPosition(layers [][]int, id int) (int, int) {
+	for c, layer := range layers {
+		for r, lid := range layer {
+			if lid == id {
+				return c, r
+			}
+		}
+	}
+	return -1, -1
+}
+
+// moveGraphSelection moves the graph cursor for arrow keys and reports
+// whether the key was used
+func (m *MainModel) moveGraphSelection(key string) bool {
+	switch key {
+	case "up", "down", "left", "right":
+	default:
+		return false
+	}
+
+	layers := graphLayers(orchestrator.NewGraph(m.Tasks))
+	if len(layers) == 0 {
+		return true
+	}
+	col, row := graphPosition(layers, m.graphSelected)
+	if col < 0 {
+		m.graphSelected = layers[0][0]
+		return true
+	}
+
+	switch key {
+	case "up":
+		row--
+	case "down":
+		row++
+	case "left":
+		col--
+	case "right":
+		col++
+	}
+	col = clamp(col, 0, len(layers)-1)
+	row = clamp(row, 0, len(layers[col])-1)
+	m.graphSelected = layers[col][row]
+	return true
+}
+
+// syncGraphSelection keeps the graph cursor on an existing task
+func (m *MainModel) syncGraphSelection() {
+	for _, t := range m.Tasks {
+		if t.ID == m.graphSelected {
+			return
+		}
+	}
+	m.graphSelected = 0
+	if layers := graphLayers(orchestrator.NewGraph(m.Tasks)); len(layers) > 0 {
+		m.graphSelected = layers[0][0]
+	}
+}
+
+// renderGraph draws the dependency graph into a width x height area. Edges
+// between neighbouring columns are drawn, those of the selection in color;
+// the boxes of the selection's dependencies and dependents are doubled, which
+// also shows edges that skip a column.
+func (m MainModel) renderGraph(width, height int) string {
+	if len(m.Tasks) == 0 {
+		return lipgloss.NewStyle().Foreground(subtle).Render("No tasks.")
+	}
+
+	g := orchestrator.NewGraph(m.Tasks)
+	layers := graphLayers(g)
+	selCol, selRow := graphPosition(layers, m.graphSelected)
+	if selCol < 0 {
+		selCol, selRow = 0, 0
+	}
+
+	// Horizontal window: as many columns as fit, keeping the selection visible
+	visibleCols := max(1, (width+graphGutter)/(graphNodeWidth+graphGutter))
+	firstCol := max(0, min(selCol-visibleCols/2, len(layers)-visibleCols))
+	lastCol := min(len(layers), firstCol+visibleCols)
+
+	detailH := 2
+	visibleRows := max(1, (height-detailH-1)/graphNodeHeight)
+
+	related := map[int]bool{}
+	for _, id := range append(g.Dependencies(m.graphSelected), g.Dependents(m.graphSelected)...) {
+		related[id] = true
+	}
+
+	var columns []string
+	line := map[int]int{} // line of the head of each visible node
+	for c := firstCol; c < lastCol; c++ {
+		layer := layers[c]
+		first := 0
+		if c == selCol && selRow >= visibleRows {
+			first = selRow - visibleRows + 1
+		}
+		last := min(len(layer), first+visibleRows)
+
+		var nodes []string
+		if first > 0 {
+			nodes = append(nodes, lipgloss.NewStyle().Foreground(subtle).Render(fmt.Sprintf("  ▲ %d more", first)))
+		}
+		for _, id := range layer[first:last] {
+			t, _ := g.Task(id)
+			line[id] = len(nodes)*graphNodeHeight + 1
+			if first > 0 {
+				line[id] -= graphNodeHeight - 1
+			}
+			nodes = append(nodes, renderGraphNode(m.catalog, g, t, id == m.graphSelected, related[id], !m.search.Match(t)))
+		}
+		if last < len(layer) {
+			nodes = append(nodes, lipgloss.NewStyle().Foreground(subtle).Render(fmt.Sprintf("  ▼ %d more", len(layer)-last)))
+		}
+		column := lipgloss.JoinVertical(lipgloss.Left, nodes...)
+		if c > firstCol {
+			var edges []graphEdge
+			for _, id := range layer[first:last] {
+				for _, dep := range g.Dependencies(id) {
+					if from, ok := line[dep]; ok && slices.Contains(layers[c-1], dep) {
+						edges = append(edges, graphEdge{from, line[id], dep == m.graphSelected || id == m.graphSelected})
+					}
+				}
+			}
+			columns = append(columns, renderGutter(edges))
+		}
+		columns = append(columns, column)
+	}
+
+	info := fmt.Sprintf("Layers %d-%d of %d  (dependencies flow left to right)", firstCol+1, lastCol, len(layers))
+	if !m.search.Empty() {
+		n := 0
+		for _, t := range m.Tasks {
+			if m.search.Match(t) {
+				n++
+			}
+		}
+		info += fmt.Sprintf("  · %d match /%s", n, m.search.Text)
+	}
+	header := lipgloss.NewStyle().Foreground(subtle).Render(info)
+	body := lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+	body = lipgloss.NewStyle().MaxHeight(height - detailH - 1).MaxWidth(width).Render(body)
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, body, m.graphDetail(g, width))
+}
+
+// graphEdge joins the head line of a dependency to that of its dependent in
+// the next column
+type graphEdge struct {
+	from, to int
+	hot      bool // an edge of the selected node
+}
+
+// Directions a gutter cell connects to
+const (
+	edgeUp = 1 << iota
+	edgeDown
+	edgeLeft
+	edgeRight
+)
+
+var edgeJunctions = map[int]string{
+	edgeLeft | edgeRight:                     "─",
+	edgeUp | edgeDown:                        "│",
+	edgeLeft | edgeDown:                      "┐",
+	edgeLeft | edgeUp:                        "┘",
+	edgeUp | edgeRight:                       "└",
+	edgeDown | edgeRight:                     "┌",
+	edgeLeft | edgeRight | edgeDown:          "┬",
+	edgeLeft | edgeRight | edgeUp:            "┴",
+	edgeUp | edgeDown | edgeRight:            "├",
+	edgeUp | edgeDown | edgeLeft:             "┤",
+	edgeUp | edgeDown | edgeLeft | edgeRight: "┼",
+}
+
+// renderGutter draws edges leaving on the left at from and arriving on the
+// right at to, bending in the middle column of the gutter
+func renderGutter(edges []graphEdge) string {
+	height := 0
+	for _, e := range edges {
+		height = max(height, e.from+1, e.to+1)
+	}
+	cells := make([][graphGutter]int, height)
+	hot := make([][graphGutter]bool, height)
+	mark := func(y, x, dirs int, h bool) {
+		cells[y][x] |= dirs
+		hot[y][x] = hot[y][x] || h
+	}
+	const bend = graphGutter / 2
+	for _, e := range edges {
+		for x := 0; x < bend; x++ {
+			mark(e.from, x, edgeLeft|edgeRight, e.hot)
+		}
+		for x := bend + 1; x < graphGutter; x++ {
+			mark(e.to, x, edgeLeft|edgeRight, e.hot)
+		}
+		top, bottom := min(e.from, e.to), max(e.from, e.to)
+		mark(e.from, bend, edgeLeft, e.hot)
+		mark(e.to, bend, edgeRight, e.hot)
+		for y := top; y <= bottom; y++ {
+			dirs := 0
+			if y > top {
+				dirs |= edgeUp
+			}
+			if y < bottom {
+				dirs |= edgeDown
+			}
+			mark(y, bend, dirs, e.hot)
+		}
+	}
+
+	cold := lipgloss.NewStyle().Foreground(subtle)
+	warm := lipgloss.NewStyle().Foreground(accent)
+	lines := make([]string, height)
+	for y := range cells {
+		var b strings.Builder
+		for x, dirs := range cells[y] {
+			style := cold
+			if hot[y][x] {
+				style = warm
+			}
+			switch {
+			case dirs == 0:
+				b.WriteString(" ")
+			case x == graphGutter-1:
+				b.WriteString(style.Render("▶"))
+			default:
+				b.WriteString(style.Render(edgeJunctions[dirs]))
+			}
+		}
+		lines[y] = b.String()
+	}
+	if height == 0 {
+		return strings.Repeat(" ", graphGutter)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderGraphNode(c *orchestrator.AgentCatalog, g *orchestrator.Graph, t orchestrator.Task, selected, related, dim bool) string {
+	status, statusColor := graphStatus(g, t)
+	color := agentColor(c, t.Agent)
+	if dim {
+		// outside the search: grey, so the matches stand out
+		statusColor, color = subtle, lipgloss.Color("240")
+	}
+	border := lipgloss.RoundedBorder()
+	switch {
+	case selected:
+		border = lipgloss.ThickBorder()
+	case related:
+		// a dependency or dependent of the selection
+		border = lipgloss.DoubleBorder()
+	}
+	agent := c.DisplayName(t.Agent)
+	if agent == "" {
+		agent = "unassigned"
+	}
+
+	inner := graphNodeWidth - 2
+	id := fmt.Sprintf("%s #%d", status, t.ID)
+	agent = truncate(agent, inner-lipgloss.Width(id)-1)
+	head := lipgloss.NewStyle().Foreground(statusColor).Bold(true).Render(id) +
+		" " + lipgloss.NewStyle().Foreground(color).Render(agent)
+	desc := truncate(t.Description, inner)
+	if dim {
+		desc = lipgloss.NewStyle().Foreground(subtle).Render(desc)
+	}
+
+	style := lipgloss.NewStyle().
+		Border(border).
+		BorderForeground(color).
+		Width(inner).
+		MaxHeight(graphNodeHeight)
+	if selected {
+		style = style.Background(lipgloss.Color("236"))
+	}
+	return style.Render(head + "\n" + desc)
+}
+
+// graphStatus returns the status icon and its color for a node
+func graphStatus(g *orchestrator.Graph, t orchestrator.Task) (string, lipgloss.TerminalColor) {
+	switch t.Status {
+	case orchestrator.StatusCompleted:
+		return "✔", special
+	case orchestrator.StatusInProgress:
+		return "▶", accent
+	case orchestrator.StatusPendingApproval:
+		return "⏸", lipgloss.Color("220")
+	case orchestrator.StatusFailed:
+		return "✖", lipgloss.Color("196")
+	case orchestrator.StatusStopped:
+		return "■", lipgloss.Color("214")
+	}
+	if g.IsBlocked(t.ID) {
+		return "⧗", lipgloss.Color("244")
+	}
+	return "○", lipgloss.Color("252")
+}
+
+// graphDetail describes the selected node's edges below the graph
+func (m MainModel) graphDetail(g *orchestrator.Graph, width int) string {
+	t, ok := g.Task(m.graphSelected)
+	if !ok {
+		return ""
+	}
+	parts := []string{fmt.Sprintf("#%d %s", t.ID, t.Status)}
+	if deps := g.Dependencies(t.ID); len(deps) > 0 {
+		parts = append(parts, "needs "+orchestrator.FormatIDs(deps))
+	}
+	if blockers := g.Blockers(t.ID); len(blockers) > 0 {
+		parts = append(parts, "waiting on "+orchestrator.FormatIDs(blockers))
+	}
+	if dependents := g.Dependents(t.ID); len(dependents) > 0 {
+		parts = append(parts, "unblocks "+orchestrator.FormatIDs(dependents))
+	}
+	line := lipgloss.NewStyle().Foreground(accent).Render(truncate(strings.Join(parts, " | "), width))
+	return line + "\n" + truncate(t.Description, width)
+}
+
+func truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
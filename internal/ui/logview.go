@@ -0,0 +1,451 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+// logViewMaxLines bounds the lines kept in memory for the open log
+const logViewMaxLines = 10000
+
+// logLevelFilters are the minimum levels the viewer cycles through
+var logLevelFilters = []struct {
+	name  string
+	level int
+}{
+	{"ALL", orchestrator.LevelNone},
+	{"INFO+", orchestrator.LevelInfo},
+	{"WARN+", orchestrator.LevelWarn},
+	{"ERROR", orchestrator.LevelError},
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// logView is the log viewer shown in place of the dashboard panels
+type logView struct {
+	open   bool
+	split  bool // keep the task lists visible above the log
+	title  string
+	path   string
+	offset int64 // how far the file has been read
+
+	lines  []string
+	levels []int // untagged lines inherit the level of the line before
+
+	follow bool // stick to the end as lines arrive
+	scroll int  // lines scrolled up from the end of the filtered log
+	filter int  // index into logLevelFilters
+
+	query     string
+	searching bool
+	search    textinput.Model
+	match     int // filtered line the last n/N jumped to
+
+	// Structured view of Claude stream-json output ([T] toggles, nil = raw lines)
+	transcript *orchestrator.Transcript
+	expanded   map[int]bool // sections shown with their body
+	cursor     int          // selected section
+}
+
+// openLog shows path in the log viewer and starts reading it
+func (m *MainModel) openLog(title, path string, split bool) tea.Cmd {
+	search := textinput.New()
+	search.Prompt = "/"
+	search.Placeholder = "search"
+	m.logView = logView{
+		open:   true,
+		split:  split,
+		title:  title,
+		path:   path,
+		offset: -1,
+		follow: true,
+		filter: m.logView.filter,
+		search: search,
+		match:  -1,
+	}
+	return orchestrator.TailLogCmd(path, -1)
+}
+
+// openTaskLog opens the log of task id, or the orchestrator log for id 0
+func (m *MainModel) openTaskLog(id int, split bool) tea.Cmd {
+	p, err := orchestrator.CurrentProject()
+	if err != nil {
+		m.events = append([]string{fmt.Sprintf("[ERROR] %v", err)}, m.events...)
+		return nil
+	}
+	path := p.OrchestratorLogFile()
+	title := "Orchestrator"
+	if id > 0 {
+		found := false
+		for _, t := range m.Tasks {
+			if t.ID == id {
+				path, found = p.TaskLogFile(t), true
+				title = fmt.Sprintf("Task #%d", id)
+				break
+			}
+		}
+		if !found {
+			m.events = append([]string{fmt.Sprintf("[ERROR] Task #%d not found", id)}, m.events...)
+			return nil
+		}
+	}
+	if path == "" {
+		m.events = append([]string{fmt.Sprintf("[WARN] No logs for %s yet in %s", strings.ToLower(title), p.LogsDir())}, m.events...)
+		return nil
+	}
+	return m.openLog(title, path, split)
+}
+
+// openTaskTranscript opens the log of task id as a structured transcript.
+// The whole file is read, since the start of the run matters here.
+func (m *MainModel) openTaskTranscript(id int) tea.Cmd {
+	if m.openTaskLog(id, false) == nil {
+		return nil
+	}
+	m.logView.title = fmt.Sprintf("Transcript #%d", id)
+	m.logView.offset = 0
+	m.logView.transcript = &orchestrator.Transcript{}
+	m.logView.expanded = map[int]bool{}
+	return orchestrator.TailLogCmd(m.logView.path, 0)
+}
+
+// openAgentLog opens the log of an agent
+func (m *MainModel) openAgentLog(name string) tea.Cmd {
+	p, err := orchestrator.CurrentProject()
+	if err != nil {
+		m.events = append([]string{fmt.Sprintf("[ERROR] %v", err)}, m.events...)
+		return nil
+	}
+	path := p.AgentLogFile(name)
+	if path == "" {
+		m.events = append([]string{fmt.Sprintf("[WARN] Agent %s has not logged anything yet", name)}, m.events...)
+		return nil
+	}
+	return m.openLog("Agent "+name, path, false)
+}
+
+// appendLog adds a chunk read from the open log. It returns the command to
+// keep reading when the file had more data than one chunk.
+func (m *MainModel) appendLog(msg orchestrator.LogChunkMsg) tea.Cmd {
+	v := &m.logView
+	if !v.open || msg.Path != v.path || msg.From != v.offset {
+		return nil // closed, another log, or a read that was overtaken
+	}
+	if msg.Err != nil {
+		m.events = append([]string{fmt.Sprintf("[ERROR] Reading %s: %v", filepath.Base(msg.Path), msg.Err)}, m.events...)
+		return nil
+	}
+	if msg.Reset {
+		v.lines, v.levels, v.scroll, v.match = nil, nil, 0, -1
+		if v.transcript != nil {
+			v.transcript, v.expanded, v.cursor = &orchestrator.Transcript{}, map[int]bool{}, 0
+		}
+	}
+	level := orchestrator.LevelInfo
+	if n := len(v.levels); n > 0 {
+		level = v.levels[n-1]
+	}
+	added := 0
+	for _, line := range msg.Lines {
+		line = strings.ReplaceAll(ansiEscape.ReplaceAllString(line, ""), "\t", "    ")
+		if l := orchestrator.LogLevel(line); l != orchestrator.LevelNone {
+			level = l
+		}
+		v.lines = append(v.lines, line)
+		v.levels = append(v.levels, level)
+		if v.transcript != nil {
+			v.transcript.Add(line)
+		}
+		if level >= logLevelFilters[v.filter].level {
+			added++
+		}
+	}
+	if drop := len(v.lines) - logViewMaxLines; drop > 0 {
+		v.lines = append([]string(nil), v.lines[drop:]...)
+		v.levels = append([]int(nil), v.levels[drop:]...)
+		v.match = -1
+	}
+	if !v.follow {
+		v.scroll += added // keep the lines on screen where they are
+	} else if v.transcript != nil {
+		v.cursor = max(0, len(v.transcript.Sections)-1)
+	}
+	v.offset = msg.Next
+	if msg.More {
+		return orchestrator.TailLogCmd(v.path, v.offset)
+	}
+	return nil
+}
+
+// visible returns the indices of the lines passing the level filter
+func (v logView) visible() []int {
+	floor := logLevelFilters[v.filter].level
+	idx := make([]int, 0, len(v.lines))
+	for i, l := range v.levels {
+		if l >= floor {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// matches returns the positions in vis whose line contains the query
+func (v logView) matches(vis []int) []int {
+	if v.query == "" {
+		return nil
+	}
+	q := strings.ToLower(v.query)
+	var found []int
+	for pos, i := range vis {
+		if strings.Contains(strings.ToLower(v.lines[i]), q) {
+			found = append(found, pos)
+		}
+	}
+	return found
+}
+
+// logPageHeight is the number of log lines that fit in the viewer
+func (m MainModel) logPageHeight() int {
+	listH, logH := m.bodyHeights(renderAgentStrip(m.agentStates) != "")
+	h := logH
+	if !m.logView.split {
+		h += listH
+	}
+	return max(1, h-4) // borders, title and status line
+}
+
+// scrollLog moves the view by delta lines (positive is up, towards older lines)
+func (m *MainModel) scrollLog(delta int) {
+	v := &m.logView
+	top := max(0, len(v.visible())-m.logPageHeight())
+	v.scroll = clamp(v.scroll+delta, 0, top)
+	v.follow = v.follow && v.scroll == 0
+}
+
+// jumpToMatch scrolls to the next (dir 1) or previous (dir -1) search match
+func (m *MainModel) jumpToMatch(dir int) {
+	v := &m.logView
+	vis := v.visible()
+	found := v.matches(vis)
+	if len(found) == 0 {
+		m.events = append([]string{fmt.Sprintf("[WARN] No match for %q", v.query)}, m.events...)
+		return
+	}
+	next := -1
+	if dir > 0 {
+		for _, pos := range found {
+			if pos > v.match {
+				next = pos
+				break
+			}
+		}
+		if next < 0 {
+			next = found[0]
+		}
+	} else {
+		for i := len(found) - 1; i >= 0; i-- {
+			if found[i] < v.match || v.match < 0 {
+				next = found[i]
+				break
+			}
+		}
+		if next < 0 {
+			next = found[len(found)-1]
+		}
+	}
+	v.match = next
+	h := m.logPageHeight()
+	v.follow = false
+	v.scroll = clamp(len(vis)-next-h/2-1, 0, max(0, len(vis)-h))
+}
+
+// logKey handles keys while the log viewer is open
+func (m *MainModel) logKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	v := &m.logView
+	if !v.open || msg.String() == "ctrl+c" {
+		return nil, false
+	}
+	if v.searching {
+		switch msg.Type {
+		case tea.KeyEnter:
+			v.searching = false
+			v.search.Blur()
+			v.query = strings.TrimSpace(v.search.Value())
+			v.match = -1
+			if v.query != "" {
+				m.jumpToMatch(-1) // the latest occurrence
+			}
+		case tea.KeyEsc:
+			v.searching = false
+			v.search.Blur()
+		default:
+			var cmd tea.Cmd
+			v.search, cmd = v.search.Update(msg)
+			return cmd, true
+		}
+		return nil, true
+	}
+
+	if v.transcript != nil && m.transcriptKey(msg.String()) {
+		return nil, true
+	}
+	page := m.logPageHeight()
+	switch msg.String() {
+	case "esc", "q":
+		if v.query != "" && msg.String() == "esc" {
+			v.query, v.match = "", -1
+			return nil, true
+		}
+		v.open = false
+	case "up", "k":
+		m.scrollLog(1)
+	case "down", "j":
+		m.scrollLog(-1)
+	case "pgup", "b":
+		m.scrollLog(page)
+	case "pgdown", " ":
+		m.scrollLog(-page)
+	case "g", "home":
+		m.scrollLog(len(v.lines))
+	case "G", "end":
+		v.scroll, v.follow = 0, true
+	case "f", "F":
+		v.follow = !v.follow
+		if v.follow {
+			v.scroll = 0
+		}
+	case "/":
+		v.searching = true
+		v.search.SetValue(v.query)
+		v.search.CursorEnd()
+		return v.search.Focus(), true
+	case "n":
+		if v.query != "" {
+			m.jumpToMatch(1)
+		}
+	case "N":
+		if v.query != "" {
+			m.jumpToMatch(-1)
+		}
+	case "v", "V":
+		v.filter = (v.filter + 1) % len(logLevelFilters)
+		v.scroll, v.match = 0, -1
+		v.follow = true
+	case "s", "S":
+		v.split = !v.split
+		m.scrollLog(0)
+	case "t", "T":
+		// switch to the transcript; the raw lines are kept in both views
+		v.transcript = orchestrator.ParseTranscript(v.lines)
+		v.expanded = map[int]bool{}
+		v.cursor = max(0, len(v.transcript.Sections)-1)
+		v.follow = true
+	}
+	return nil, true
+}
+
+// logLevelColor colors a line by its level
+func logLevelColor(level int) lipgloss.TerminalColor {
+	switch level {
+	case orchestrator.LevelError:
+		return lipgloss.Color("196")
+	case orchestrator.LevelWarn:
+		return lipgloss.Color("214")
+	case orchestrator.LevelDebug:
+		return subtle
+	}
+	return lipgloss.NoColor{}
+}
+
+// renderLog draws the status line and the visible part of the open log
+func (m MainModel) renderLog(width, height int) string {
+	v := m.logView
+	if v.transcript != nil {
+		return m.renderTranscript(width, height)
+	}
+	vis := v.visible()
+
+	state := lipgloss.NewStyle().Foreground(special).Render("FOLLOW")
+	if !v.follow {
+		state = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(fmt.Sprintf("PAUSED ↑%d", v.scroll))
+	}
+	status := []string{state, "level " + logLevelFilters[v.filter].name, fmt.Sprintf("%d lines", len(vis))}
+	if v.query != "" {
+		found := v.matches(vis)
+		pos := 0
+		for i, f := range found {
+			if f == v.match {
+				pos = i + 1
+			}
+		}
+		status = append(status, fmt.Sprintf("/%s %d/%d", v.query, pos, len(found)))
+	}
+	header := strings.Join(status, "  ")
+	if v.searching {
+		header = v.search.View()
+	}
+
+	rows := max(1, height-1)
+	end := len(vis) - min(v.scroll, len(vis))
+	start := max(0, end-rows)
+	lines := []string{header}
+	if len(vis) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(subtle).Render("(empty)"))
+	}
+	for pos := start; pos < end; pos++ {
+		i := vis[pos]
+		lines = append(lines, highlightLog(cutWidth(v.lines[i], width), v.query, v.levels[i], pos == v.match))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// highlightLog colors a plain line by level and marks every occurrence of query
+func highlightLog(line, query string, level int, current bool) string {
+	return markQuery(line, query, lipgloss.NewStyle().Foreground(logLevelColor(level)), current)
+}
+
+// markQuery renders line in base, with every occurrence of query marked
+func markQuery(line, query string, base lipgloss.Style, current bool) string {
+	mark := lipgloss.NewStyle().Background(highlight).Foreground(lipgloss.Color("255"))
+	if current {
+		mark = lipgloss.NewStyle().Background(accent).Foreground(lipgloss.Color("0"))
+	}
+	if query == "" {
+		return base.Render(line)
+	}
+	var b strings.Builder
+	lower, q := strings.ToLower(line), strings.ToLower(query)
+	for {
+		i := strings.Index(lower, q)
+		// ToLower can change byte lengths outside ASCII; fall back to plain text then
+		if i < 0 || len(lower) != len(line) {
+			b.WriteString(base.Render(line))
+			return b.String()
+		}
+		b.WriteString(base.Render(line[:i]))
+		b.WriteString(mark.Render(line[i : i+len(q)]))
+		line, lower = line[i+len(q):], lower[i+len(q):]
+	}
+}
+
+// cutWidth cuts s to at most width terminal cells
+func cutWidth(s string, width int) string {
+	if len(s) <= width {
+		return s
+	}
+	w := 0
+	for i, r := range s {
+		w += lipgloss.Width(string(r))
+		if w > width {
+			return s[:i]
+		}
+	}
+	return s
+}
@@ -3,7 +3,6 @@ package ui
 import (
 	"crypto/sha256"
 	"encoding/json"
-	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -12,28 +11,27 @@ import (
 	"shineos/claude-orchestra/internal/orchestrator"
 )
 
-// Auto-refresh interval
-const autoRefreshInterval = 5 * time.Second
-
-// tickMsg is sent periodically to trigger auto-refresh
-type tickMsg struct {
-	isAuto bool // true = auto-refresh, false = manual
-}
-
-// silentRefreshMsg is used for auto-refresh without UI flicker
-type silentRefreshMsg struct{}
+// Views in the Tab rotation
+const (
+	tabPending = iota
+	tabActive
+	tabComplete
+	tabAgents
+	tabGraph
+	tabApprovals
+	tabCount
+)
 
 // MainModel is the main state of the application
 type MainModel struct {
 	// State
-	Tab          int // 0: Pending, 1: Active, 2: Complete
+	Tab          int // 0: Pending, 1: Active, 2: Complete, 3: Agents, 4: Graph, 5: Approvals
 	InputMode    bool
 	Quitting     bool
 	Loaded       bool
 	Width        int
 	Height       int
 	Err          error
-	AutoRefresh  bool // Auto-refresh enabled
 
 	// Data
 	Tasks        []orchestrator.Task
@@ -41,10 +39,24 @@ type MainModel struct {
 	events       []string // Event log history
 	ActiveCommand string   // Current command waiting for ID input (start, complete, logs, edit)
 	ActiveTaskID  int      // ID being input/confirmed
+	graphSelected int      // Task ID selected in the graph view
 
 	// Process tracking - for cleanup
 	editorTempFile string // Track temp file for cleanup
 
+	// watcher pushes tasks.json / pids / logs changes (nil if no project was found)
+	watcher *orchestrator.Watcher
+
+	// supervisor runs the agents spawned from the dashboard (nil without a project)
+	supervisor  *orchestrator.Supervisor
+	agentStates []orchestrator.AgentState
+
+	// Agents panel
+	agents        []orchestrator.AgentStatus
+	agentCPU      map[string]float64               // CPU % since the previous sample
+	agentSamples  map[string]orchestrator.ProcStat // previous /proc sample per agent
+	agentSelected int
+
 	// Components
 	pendingList  list.Model
 	activeList   list.Model
@@ -59,6 +71,45 @@ type MainModel struct {
 	PendingTaskAgent string
 	AgentChoiceIndex int
 	AgentChoices     []string
+
+	// Agent definitions from .claude/agents/*.json, driving choices, filters and badges
+	catalog     *orchestrator.AgentCatalog
+	agentFilter string // show only tasks of this agent ("" = all)
+
+	// Query filtering the task panels ([/]) and the saved searches of
+	// .claude/searches.json it may use as @name
+	search   orchestrator.Query
+	searches []orchestrator.SavedSearch
+
+	// Log viewer ([L] full screen, [s] split under the task lists, [V] transcript)
+	logView logView
+
+	// Diff review pane ([G] task changes, [D] in the approvals panel)
+	diffView diffView
+
+	// Tasks marked for bulk actions ([Space], [Shift+↑/↓], [Ctrl+A])
+	marked map[int]bool
+
+	// Safety policy (.claude/safety-policy.json) and the action waiting for
+	// the confirmation it asks
+	safety  orchestrator.SafetyPolicy
+	confirm *confirmation
+
+	// Review of a proposed decomposition (add wizard with the auto agent)
+	decompose decomposeView
+
+	// Search of archived tasks ([Z])
+	archive archiveView
+
+	// Approvals panel
+	approvals        []orchestrator.Approval
+	approvalSelected int
+	approvalFile     int    // file of the selected request whose diff is shown
+	approvalScroll   int    // first diff line shown
+	approvalID       string // request answered by the approve / reject prompt
+
+	// approvalEngine expires and auto-approves requests by policy (nil without a project)
+	approvalEngine *orchestrator.ApprovalEngine
 }
 
 // computeTasksHash returns a hash of the tasks for change detection
@@ -84,16 +135,21 @@ func InitialModel() MainModel {
 	pList := list.New(pItems, list.NewDefaultDelegate(), 0, 0)
 	pList.Title = "Pending Tasks"
 	pList.SetShowHelp(false)
+	pList.SetFilteringEnabled(false) // replaced by the query of [/]
 
 	aItems := []list.Item{}
 	aList := list.New(aItems, list.NewDefaultDelegate(), 0, 0)
 	aList.Title = "Active Tasks"
 	aList.SetShowHelp(false)
+	aList.SetFilteringEnabled(false)
 
 	cItems := []list.Item{}
 	cList := list.New(cItems, list.NewDefaultDelegate(), 0, 0)
 	cList.Title = "Completed"
 	cList.SetShowHelp(false)
+	cList.SetFilteringEnabled(false)
+
+	catalog := orchestrator.DefaultAgentCatalog()
 
 	return MainModel{
 		Tab:              0,
@@ -102,27 +158,174 @@ func InitialModel() MainModel {
 		pendingList:      pList,
 		activeList:       aList,
 		completeList:     cList,
-		AutoRefresh:      true, // Auto-refresh enabled by default
-		AgentChoices: []string{
-			"AI (auto)",
-			"frontend",
-			"backend",
-			"tests",
-			"docs",
-			"planner",
-			"architect",
-			"reviewer",
-			"tester",
-		},
+		safety:           orchestrator.DefaultSafetyPolicy(),
+		catalog:          catalog,
+		AgentChoices:     agentChoices(catalog),
+	}
+}
+
+// autoAgentChoice lets the orchestrator pick the agent
+const autoAgentChoice = "AI (auto)"
+
+// agentChoices returns the add wizard choices for a catalog
+func agentChoices(c *orchestrator.AgentCatalog) []string {
+	return append([]string{autoAgentChoice}, c.Names()...)
+}
+
+// setCatalog installs a new agent catalog and redraws what depends on it
+func (m *MainModel) setCatalog(c *orchestrator.AgentCatalog) {
+	selected := ""
+	if m.AgentChoiceIndex < len(m.AgentChoices) {
+		selected = m.AgentChoices[m.AgentChoiceIndex]
+	}
+	m.catalog = c
+	m.AgentChoices = agentChoices(c)
+	m.AgentChoiceIndex = 0
+	for i, choice := range m.AgentChoices {
+		if choice == selected {
+			m.AgentChoiceIndex = i
+		}
+	}
+	if _, ok := c.Lookup(m.agentFilter); !ok {
+		m.agentFilter = ""
+	}
+	m.applyFilters()
+}
+
+// applyFilters rebuilds the task lists and shows the agent filter and the
+// search in their titles
+func (m *MainModel) applyFilters() {
+	suffix := ""
+	if m.agentFilter != "" {
+		suffix = " [" + m.catalog.DisplayName(m.agentFilter) + "]"
+	}
+	if !m.search.Empty() {
+		suffix += " /" + m.search.Text
 	}
+	m.pendingList.Title = "Pending Tasks" + suffix
+	m.activeList.Title = "Active Tasks" + suffix
+	m.completeList.Title = "Completed" + suffix
+	if m.Loaded {
+		m.refreshLists()
+	}
+}
+
+// cycleAgentFilter moves the agent filter to the next agent, then back to all
+func (m *MainModel) cycleAgentFilter() {
+	names := m.catalog.Names()
+	next := ""
+	for i, name := range names {
+		if name == m.agentFilter && i+1 < len(names) {
+			next = names[i+1]
+		}
+	}
+	if m.agentFilter == "" && len(names) > 0 {
+		next = names[0]
+	}
+	m.agentFilter = next
+	m.applyFilters()
+}
+
+// newProjectWatcher watches the current project, or returns nil when there is none
+func newProjectWatcher() *orchestrator.Watcher {
+	p, err := orchestrator.CurrentProject()
+	if err != nil {
+		return nil
+	}
+	w, err := orchestrator.NewWatcher(p.WatchPaths())
+	if err != nil {
+		return nil
+	}
+	return w
+}
+
+// newProjectSupervisor supervises the agents of the current project, taking
+// over agents that are already running, or returns nil when there is none
+func newProjectSupervisor() *orchestrator.Supervisor {
+	p, err := orchestrator.CurrentProject()
+	if err != nil {
+		return nil
+	}
+	s := orchestrator.NewSupervisor(p)
+	s.Adopt()
+	orchestrator.SetSupervisor(s)
+	return s
+}
+
+// newProjectApprovalEngine enforces the approval policy of the current
+// project, or returns nil when there is none
+func newProjectApprovalEngine() *orchestrator.ApprovalEngine {
+	p, err := orchestrator.CurrentProject()
+	if err != nil {
+		return nil
+	}
+	return orchestrator.NewApprovalEngine(p)
+}
+
+// servicesMsg hands the background services started by startServicesCmd
+// to the model; each is nil without a project
+type servicesMsg struct {
+	watcher        *orchestrator.Watcher
+	supervisor     *orchestrator.Supervisor
+	approvalEngine *orchestrator.ApprovalEngine
+}
+
+// startServicesCmd starts the watcher, the supervisor and the approval engine
+// once the program runs, so that building a model has no side effects
+func startServicesCmd() tea.Cmd {
+	return func() tea.Msg {
+		return servicesMsg{
+			watcher:        newProjectWatcher(),
+			supervisor:     newProjectSupervisor(),
+			approvalEngine: newProjectApprovalEngine(),
+		}
+	}
+}
+
+// startServices installs the services and starts listening to them
+func (m *MainModel) startServices(msg servicesMsg) []tea.Cmd {
+	m.watcher, m.supervisor, m.approvalEngine = msg.watcher, msg.supervisor, msg.approvalEngine
+	var cmds []tea.Cmd
+	if m.watcher != nil {
+		cmds = append(cmds, orchestrator.WatchCmd(m.watcher))
+	}
+	if m.supervisor != nil {
+		cmds = append(cmds, orchestrator.SupervisorCmd(m.supervisor))
+	}
+	if m.approvalEngine != nil {
+		cmds = append(cmds, orchestrator.ApprovalSweepCmd(m.approvalEngine), approvalTickCmd())
+	}
+	return cmds
+}
+
+// quit stops the services and ends the program. Agents keep running.
+func (m *MainModel) quit() tea.Cmd {
+	m.Quitting = true
+	if m.watcher != nil {
+		m.watcher.Close()
+		m.watcher = nil
+	}
+	if m.supervisor != nil {
+		orchestrator.SetSupervisor(nil)
+		m.supervisor.Close()
+		m.supervisor = nil
+	}
+	m.approvalEngine = nil
+	return tea.Quit
 }
 
 func (m MainModel) Init() tea.Cmd {
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		m.Spinner.Tick,
 		orchestrator.FetchTasksCmd(),
-		tea.Tick(autoRefreshInterval, func(t time.Time) tea.Msg {
-			return tickMsg{isAuto: true}
-		}),
-	)
+		orchestrator.FetchAgentsCmd(),
+		orchestrator.FetchApprovalsCmd(),
+		orchestrator.LoadAgentCatalogCmd(),
+		orchestrator.LoadSafetyPolicyCmd(),
+		orchestrator.LoadSavedSearchesCmd(),
+		orchestrator.ArchiveExpiredCmd(true),
+		agentTickCmd(),
+		startServicesCmd(),
+	}
+	return tea.Batch(cmds...)
 }
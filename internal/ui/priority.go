@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+// priorityColors are the badge backgrounds of the priorities worth noticing
+var priorityColors = map[string]lipgloss.Color{
+	orchestrator.PriorityCritical: lipgloss.Color("196"),
+	orchestrator.PriorityHigh:     lipgloss.Color("208"),
+	orchestrator.PriorityLow:      lipgloss.Color("238"),
+}
+
+// priorityBadge renders the priority of a task; normal tasks get none
+func priorityBadge(p string) string {
+	color, ok := priorityColors[p]
+	if !ok {
+		return ""
+	}
+	label := map[string]string{
+		orchestrator.PriorityCritical: "CRIT",
+		orchestrator.PriorityHigh:     "HIGH",
+		orchestrator.PriorityLow:      "LOW",
+	}[p]
+	return lipgloss.NewStyle().Background(color).Foreground(lipgloss.Color("255")).Bold(true).Padding(0, 1).Render(label) + " "
+}
+
+// shiftSelectedPriority raises (delta > 0) or lowers the priority of the
+// selected task
+func (m *MainModel) shiftSelectedPriority(delta int) tea.Cmd {
+	id := m.getSelectedID()
+	if id <= 0 {
+		return nil
+	}
+	for _, t := range m.Tasks {
+		if t.ID != id {
+			continue
+		}
+		p := orchestrator.ShiftPriority(t.Priority, delta)
+		if p == t.Priority {
+			m.events = append([]string{fmt.Sprintf("[HINT] Task #%d is already %s", id, p)}, m.events...)
+			return nil
+		}
+		return m.guard(orchestrator.ActionPriority, []int{id}, orchestrator.ConfirmNone, fmt.Sprintf("Set task #%d to %s?", id, p),
+			fmt.Sprintf("Task #%d priority: %s → %s", id, t.Priority, p), orchestrator.SetPriorityCmd(id, p))
+	}
+	return nil
+}
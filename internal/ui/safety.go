@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+// confirmation is an action held back until the user confirms it
+type confirmation struct {
+	question string   // e.g. "Remove task #3?"
+	reasons  []string // why the safety policy asks
+	phrase   string   // text to type for a typed confirmation ("" = y/n)
+	ids      []int
+	event    string // logged when the action runs
+	run      tea.Cmd
+	input    textinput.Model
+}
+
+// agentRunning reports whether the agents panel last saw name running
+func (m MainModel) agentRunning(name string) bool {
+	for _, a := range m.agents {
+		if a.Name == name {
+			return a.Running
+		}
+	}
+	return false
+}
+
+// guard runs cmd once the confirmation the safety policy asks for action on
+// ids is given, asking at least atLeast. event is logged when cmd runs.
+func (m *MainModel) guard(action string, ids []int, atLeast, question, event string, cmd tea.Cmd) tea.Cmd {
+	g := m.safety.Check(action, m.Tasks, ids, m.agentRunning).AtLeast(atLeast)
+	if err := g.Err(); err != nil {
+		m.events = append([]string{fmt.Sprintf("[WARN] %v", err)}, m.events...)
+		return nil
+	}
+	if g.Level == orchestrator.ConfirmNone {
+		m.events = append([]string{event}, m.events...)
+		return cmd
+	}
+	c := &confirmation{question: question, reasons: g.Reasons, ids: ids, event: event, run: cmd}
+	m.confirm = c
+	if g.Level != orchestrator.ConfirmTyped {
+		return nil
+	}
+	c.phrase = g.Phrase
+	c.input = textinput.New()
+	c.input.Placeholder = "Type " + g.Phrase + " to confirm"
+	c.input.CharLimit = 40
+	c.input.Width = 30
+	c.input.Focus()
+	return textinput.Blink
+}
+
+// confirmKey answers the confirmation waiting in the footer
+func (m *MainModel) confirmKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	c := m.confirm
+	if c == nil || msg.String() == "ctrl+c" {
+		return nil, false
+	}
+	if msg.Type == tea.KeyEsc || (c.phrase == "" && (msg.String() == "n" || msg.String() == "N")) {
+		m.confirm = nil
+		m.events = append([]string{"Cancelled: " + strings.TrimSuffix(c.question, "?")}, m.events...)
+		return nil, true
+	}
+	if c.phrase != "" {
+		if msg.Type != tea.KeyEnter {
+			var cmd tea.Cmd
+			c.input, cmd = c.input.Update(msg)
+			return cmd, true
+		}
+		if strings.TrimSpace(c.input.Value()) != c.phrase {
+			m.events = append([]string{fmt.Sprintf("[WARN] Type %s to confirm, or Esc to cancel", c.phrase)}, m.events...)
+			return nil, true
+		}
+	} else if msg.String() != "y" && msg.String() != "Y" && msg.Type != tea.KeyEnter {
+		return nil, true
+	}
+	m.confirm = nil
+	m.events = append([]string{c.event}, m.events...)
+	return c.run, true
+}
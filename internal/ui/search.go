@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+// openSearch prompts for the query of the task panels, starting from the
+// current one
+func (m *MainModel) openSearch() tea.Cmd {
+	m.InputMode = true
+	m.ActiveCommand = "search"
+	m.Input.Placeholder = `agent:backend status:failed prio:high+ "login" (Tab: saved searches)`
+	m.Input.SetValue(m.search.Text)
+	m.Input.CursorEnd()
+	m.Input.Focus()
+	return textinput.Blink
+}
+
+// openSaveSearch prompts for the name to save the current search under
+func (m *MainModel) openSaveSearch() tea.Cmd {
+	m.InputMode = true
+	m.ActiveCommand = "save-search"
+	m.Input.Placeholder = "Save as (an existing name without a search deletes it)"
+	if m.search.Empty() {
+		m.Input.Placeholder = "Saved search to delete"
+	}
+	m.Input.SetValue("")
+	m.Input.Focus()
+	return textinput.Blink
+}
+
+// searchInput completes the search and save-search prompts. Tab in the
+// search prompt cycles through the saved searches.
+func (m *MainModel) searchInput(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case m.ActiveCommand == "search" && msg.Type == tea.KeyTab:
+		if len(m.searches) == 0 {
+			m.events = append([]string{"[HINT] No saved searches yet: search, then press Ctrl+S to save"}, m.events...)
+			return nil, true
+		}
+		next := 0
+		for i, s := range m.searches {
+			if m.Input.Value() == "@"+s.Name {
+				next = (i + 1) % len(m.searches)
+			}
+		}
+		m.Input.SetValue("@" + m.searches[next].Name)
+		m.Input.CursorEnd()
+		return nil, true
+	case msg.Type != tea.KeyEnter:
+		return nil, false
+	case m.ActiveCommand == "search":
+		q, err := orchestrator.ParseSearch(m.Input.Value(), m.searches)
+		if err != nil {
+			m.events = append([]string{fmt.Sprintf("[WARN] %v", err)}, m.events...)
+			return nil, true
+		}
+		m.closeInput()
+		m.setSearch(q)
+		return nil, true
+	case m.ActiveCommand == "save-search":
+		name := strings.TrimSpace(m.Input.Value())
+		if name == "" {
+			return nil, true
+		}
+		m.closeInput()
+		if m.search.Empty() {
+			m.events = append([]string{fmt.Sprintf("Deleting saved search @%s...", strings.TrimPrefix(name, "@"))}, m.events...)
+		} else {
+			m.events = append([]string{fmt.Sprintf("Saving %q as @%s...", m.search.Text, strings.TrimPrefix(name, "@"))}, m.events...)
+		}
+		return orchestrator.SaveSearchCmd(name, m.search.Text), true
+	}
+	return nil, false
+}
+
+// closeInput leaves the prompt of the command line
+func (m *MainModel) closeInput() {
+	m.InputMode = false
+	m.ActiveCommand = ""
+	m.Input.SetValue("")
+	m.Input.Blur()
+}
+
+// setSearch filters the task panels by q and reports how many tasks match
+func (m *MainModel) setSearch(q orchestrator.Query) {
+	m.search = q
+	m.applyFilters()
+	if q.Empty() {
+		m.events = append([]string{"Search cleared"}, m.events...)
+		return
+	}
+	n := 0
+	for _, t := range m.Tasks {
+		if q.Match(t) {
+			n++
+		}
+	}
+	m.events = append([]string{fmt.Sprintf("Search %s: %d task(s)", q.Text, n)}, m.events...)
+}
+
+// savedSearches installs the saved searches read or written
+func (m *MainModel) savedSearches(msg orchestrator.SavedSearchesMsg) {
+	if msg.Err != nil {
+		m.events = append([]string{fmt.Sprintf("[WARN] %v", msg.Err)}, m.events...)
+		return
+	}
+	if m.searches != nil {
+		names := make([]string, len(msg.Searches))
+		for i, s := range msg.Searches {
+			names[i] = "@" + s.Name
+		}
+		m.events = append([]string{fmt.Sprintf("Saved searches: %s", orDash(strings.Join(names, ", ")))}, m.events...)
+	}
+	m.searches = msg.Searches
+	if m.searches == nil {
+		m.searches = []orchestrator.SavedSearch{}
+	}
+}
+
+// approvalTask returns the task an approval request belongs to, with the
+// request's description added, so the search can match either
+func (m MainModel) approvalTask(a orchestrator.Approval) orchestrator.Task {
+	t := orchestrator.Task{ID: a.TaskID, Agent: a.Agent, Status: orchestrator.StatusPendingApproval}
+	for _, task := range m.Tasks {
+		if task.ID == a.TaskID {
+			t = task
+			break
+		}
+	}
+	t.Description += "\n" + a.Description
+	return t
+}
+
+// markWords renders s in base with every occurrence of words marked
+func markWords(s string, words []string, base lipgloss.Style) string {
+	if len(words) == 0 {
+		return base.Render(s)
+	}
+	mark := lipgloss.NewStyle().Background(lipgloss.Color("220")).Foreground(lipgloss.Color("0"))
+	lower := strings.ToLower(s)
+	// ToLower can change byte lengths outside ASCII; leave such text unmarked
+	if len(lower) != len(s) {
+		return base.Render(s)
+	}
+	var b strings.Builder
+	for s != "" {
+		at, n := -1, 0
+		for _, w := range words {
+			if i := strings.Index(lower, w); w != "" && i >= 0 && (at < 0 || i < at || i == at && len(w) > n) {
+				at, n = i, len(w)
+			}
+		}
+		if at < 0 {
+			b.WriteString(base.Render(s))
+			break
+		}
+		if at > 0 {
+			b.WriteString(base.Render(s[:at]))
+		}
+		b.WriteString(mark.Render(s[at : at+n]))
+		s, lower = s[at+n:], lower[at+n:]
+	}
+	return b.String()
+}
@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// syntaxLang describes what highlightSyntax colors for a language
+type syntaxLang struct {
+	keywords map[string]bool
+	comment  string // line comment marker
+	quotes   string // string delimiters
+}
+
+var (
+	syntaxKeyword = lipgloss.Color("204")
+	syntaxString  = lipgloss.Color("186")
+	syntaxComment = lipgloss.Color("244")
+	syntaxNumber  = lipgloss.Color("141")
+)
+
+func keywordSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// syntaxLangs are keyed by the name syntaxFor returns
+var syntaxLangs = map[string]syntaxLang{
+	"go": {keywordSet(`break case chan const continue default defer else fallthrough for func go goto if
+		import interface map package range return select struct switch type var nil true false`), "//", "\"'`"},
+	"js": {keywordSet(`async await break case catch class const continue default delete do else export
+		extends false finally for from function if import in instanceof interface let new null return
+		static super switch this throw true try type typeof undefined var void while yield`), "//", "\"'`"},
+	"py": {keywordSet(`and as assert async await break class continue def del elif else except False
+		finally for from global if import in is lambda None nonlocal not or pass raise return True try
+		while with yield self`), "#", "\"'"},
+	"sh": {keywordSet(`case do done elif else esac fi for function if in local return then until while
+		export readonly echo exit`), "#", "\"'"},
+	"rs": {keywordSet(`as break const continue crate else enum extern false fn for if impl in let loop
+		match mod move mut pub ref return self Self static struct super trait true type unsafe use where while`), "//", "\""},
+	"data": {keywordSet(`true false null`), "#", "\"'"},
+}
+
+// syntaxFor picks the highlighting of a file by its extension ("" for none)
+func syntaxFor(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".go":
+		return "go"
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs", ".java", ".c", ".h", ".cpp", ".cs", ".swift", ".kt":
+		return "js"
+	case ".py":
+		return "py"
+	case ".sh", ".bash", ".zsh":
+		return "sh"
+	case ".rs":
+		return "rs"
+	case ".json", ".yml", ".yaml", ".toml":
+		return "data"
+	}
+	return ""
+}
+
+// highlightSyntax colors keywords, strings, numbers and comments of one
+// line of code on top of base. It works line by line, so strings and
+// comments spanning lines are only colored where they start.
+func highlightSyntax(line, lang string, base lipgloss.Style) string {
+	l, ok := syntaxLangs[lang]
+	if !ok || line == "" {
+		return base.Render(line)
+	}
+	var b strings.Builder
+	plain := 0 // start of the text not written yet
+	flush := func(end int) {
+		if end > plain {
+			b.WriteString(base.Render(line[plain:end]))
+		}
+	}
+	token := func(start, end int, color lipgloss.Color) {
+		flush(start)
+		b.WriteString(base.Foreground(color).Render(line[start:end]))
+		plain = end
+	}
+
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case l.comment != "" && strings.HasPrefix(line[i:], l.comment):
+			token(i, len(line), syntaxComment)
+			i = len(line)
+		case strings.IndexByte(l.quotes, c) >= 0:
+			end := i + 1
+			for end < len(line) && line[end] != c {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(line))
+			token(i, end, syntaxString)
+			i = end
+		case isIdentStart(c):
+			end := i + 1
+			for end < len(line) && (isIdentStart(line[end]) || isDigit(line[end])) {
+				end++
+			}
+			if l.keywords[line[i:end]] {
+				token(i, end, syntaxKeyword)
+			}
+			i = end
+		case isDigit(c):
+			end := i + 1
+			for end < len(line) && (isDigit(line[end]) || line[end] == '.' || line[end] == 'x' || line[end] == '_') {
+				end++
+			}
+			token(i, end, syntaxNumber)
+			i = end
+		default:
+			i++
+		}
+	}
+	flush(len(line))
+	return b.String()
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
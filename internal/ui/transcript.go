@@ -0,0 +1,213 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"shineos/claude-orchestra/internal/orchestrator"
+)
+
+// sectionBadges label and color each kind of transcript section
+var sectionBadges = map[string]struct {
+	label string
+	color lipgloss.Color
+}{
+	orchestrator.SectionSystem:   {"INIT", "245"},
+	orchestrator.SectionText:     {"CLAUDE", "75"},
+	orchestrator.SectionThinking: {"THINK", "141"},
+	orchestrator.SectionTool:     {"TOOL", "220"},
+	orchestrator.SectionEdit:     {"EDIT", "114"},
+	orchestrator.SectionBash:     {"BASH", "208"},
+	orchestrator.SectionResult:   {"RESULT", "39"},
+	orchestrator.SectionRaw:      {"RAW", "240"},
+}
+
+// transcriptRow is one rendered line of the transcript
+type transcriptRow struct {
+	section int
+	header  bool
+	text    string
+}
+
+// transcriptKey handles the keys that differ in the transcript view
+func (m *MainModel) transcriptKey(key string) bool {
+	v := &m.logView
+	last := max(0, len(v.transcript.Sections)-1)
+	switch key {
+	case "up", "k":
+		v.cursor = max(0, v.cursor-1)
+		v.follow = false
+	case "down", "j":
+		v.cursor = min(last, v.cursor+1)
+	case "pgup", "b":
+		v.cursor = max(0, v.cursor-m.logPageHeight()/2)
+		v.follow = false
+	case "pgdown":
+		v.cursor = min(last, v.cursor+m.logPageHeight()/2)
+	case "g", "home":
+		v.cursor, v.follow = 0, false
+	case "G", "end":
+		v.cursor, v.follow = last, true
+	case "f", "F":
+		v.follow = !v.follow
+		if v.follow {
+			v.cursor = last
+		}
+	case "enter", " ":
+		v.expanded[v.cursor] = !v.expanded[v.cursor]
+	case "e":
+		for i := range v.transcript.Sections {
+			v.expanded[i] = true
+		}
+	case "c":
+		v.expanded = map[int]bool{}
+	case "n", "N":
+		if v.query != "" {
+			m.jumpToSection(key == "n")
+		}
+	case "t", "T":
+		v.transcript, v.expanded = nil, nil
+		v.scroll, v.follow = 0, true
+	case "v", "V":
+		// levels do not apply to the transcript
+	default:
+		return false
+	}
+	return true
+}
+
+// jumpToSection selects and expands the next (or previous) section containing the query
+func (m *MainModel) jumpToSection(forward bool) {
+	v := &m.logView
+	n := len(v.transcript.Sections)
+	q := strings.ToLower(v.query)
+	for step := 1; step <= n; step++ {
+		i := (v.cursor + step) % n
+		if !forward {
+			i = (v.cursor - step + 2*n) % n
+		}
+		s := v.transcript.Sections[i]
+		if strings.Contains(strings.ToLower(s.Title), q) || strings.Contains(strings.ToLower(strings.Join(s.Body, "\n")), q) {
+			v.cursor, v.follow = i, false
+			v.expanded[i] = true
+			return
+		}
+	}
+	m.events = append([]string{fmt.Sprintf("[WARN] No match for %q", v.query)}, m.events...)
+}
+
+// transcriptRows lays out the sections, with the bodies of expanded ones
+func (v logView) transcriptRows() []transcriptRow {
+	var rows []transcriptRow
+	for i, s := range v.transcript.Sections {
+		rows = append(rows, transcriptRow{section: i, header: true, text: s.Title})
+		if v.expanded[i] {
+			for _, line := range s.Body {
+				rows = append(rows, transcriptRow{section: i, text: line})
+			}
+		}
+	}
+	return rows
+}
+
+// renderTranscript draws the status line and the visible sections
+func (m MainModel) renderTranscript(width, height int) string {
+	v := m.logView
+	t := v.transcript
+
+	state := lipgloss.NewStyle().Foreground(special).Render("FOLLOW")
+	if !v.follow {
+		state = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render("PAUSED")
+	}
+	u := t.Usage
+	status := []string{
+		state,
+		fmt.Sprintf("%d/%d", min(v.cursor+1, len(t.Sections)), len(t.Sections)),
+		fmt.Sprintf("tokens in %s out %s cache %s", formatTokens(u.InputTokens), formatTokens(u.OutputTokens), formatTokens(u.CacheReadInputTokens+u.CacheCreationInputTokens)),
+	}
+	if t.CostUSD > 0 {
+		status = append(status, fmt.Sprintf("$%.4f", t.CostUSD))
+	}
+	if v.query != "" {
+		status = append(status, "/"+v.query)
+	}
+	header := strings.Join(status, "  ")
+	if v.searching {
+		header = v.search.View()
+	}
+	lines := []string{header}
+	if len(t.Sections) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(subtle).Render("(empty)"))
+		return strings.Join(lines, "\n")
+	}
+
+	rows := v.transcriptRows()
+	h := max(1, height-1)
+	cursorRow := 0
+	for i, r := range rows {
+		if r.header && r.section == v.cursor {
+			cursorRow = i
+			break
+		}
+	}
+	top := clamp(cursorRow-h/3, 0, max(0, len(rows)-h))
+	if v.follow {
+		top = max(0, len(rows)-h)
+	}
+
+	for _, r := range rows[top:min(len(rows), top+h)] {
+		s := t.Sections[r.section]
+		if !r.header {
+			lines = append(lines, renderSectionBody(r.text, v.query, width))
+			continue
+		}
+		badge := sectionBadges[s.Kind]
+		fold := "▸"
+		if v.expanded[r.section] {
+			fold = "▾"
+		}
+		if len(s.Body) == 0 {
+			fold = " "
+		}
+		color := badge.color
+		if s.Error {
+			color = "196"
+		}
+		label := lipgloss.NewStyle().Foreground(color).Bold(true).Render(fmt.Sprintf("%-6s", badge.label))
+		title := cutWidth(r.text, max(1, width-10))
+		base := lipgloss.NewStyle()
+		if r.section == v.cursor {
+			base = base.Background(lipgloss.Color("237")).Bold(true)
+		}
+		lines = append(lines, fmt.Sprintf("%s %s  %s", fold, label, markQuery(title, v.query, base, false)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderSectionBody draws one body line, coloring diff lines
+func renderSectionBody(line, query string, width int) string {
+	base := lipgloss.NewStyle().Foreground(subtle)
+	switch {
+	case strings.HasPrefix(line, "+ "):
+		base = lipgloss.NewStyle().Foreground(lipgloss.Color("114"))
+	case strings.HasPrefix(line, "- "):
+		base = lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
+	case strings.HasPrefix(line, "→ error"):
+		base = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	case strings.HasPrefix(line, "→ "):
+		base = lipgloss.NewStyle().Foreground(accent)
+	}
+	return "    │ " + markQuery(cutWidth(line, max(1, width-6)), query, base, false)
+}
+
+// formatTokens shortens a token count (1234 -> 1.2k)
+func formatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprintf("%d", n)
+}
@@ -1,8 +1,18 @@
 package ui
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"shineos/claude-orchestra/internal/orchestrator"
 )
 
 func TestUpdate(t *testing.T) {
@@ -34,8 +44,842 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestGraphNavigation(t *testing.T) {
+	m := InitialModel()
+	m.Width, m.Height = 120, 40
+	m, _ = updateModel(m, orchestrator.TaskLoadMsg{
+		{ID: 1, Status: "completed"},
+		{ID: 2, Status: "pending", Dependencies: []int{1}},
+		{ID: 3, Status: "pending", Dependencies: []int{1}},
+		{ID: 4, Status: "pending", Dependencies: []int{2, 3}},
+	})
+	m.Tab = tabGraph
+	if m.getSelectedID() != 1 {
+		t.Fatalf("expected root #1 selected, got %d", m.getSelectedID())
+	}
+
+	for _, step := range []struct {
+		key  tea.KeyType
+		want int
+	}{{tea.KeyRight, 2}, {tea.KeyDown, 3}, {tea.KeyRight, 4}, {tea.KeyLeft, 2}} {
+		m, _ = updateModel(m, tea.KeyMsg{Type: step.key})
+		if m.Tab != tabGraph {
+			t.Fatalf("arrow keys should stay in the graph view")
+		}
+		if got := m.getSelectedID(); got != step.want {
+			t.Errorf("after %v expected #%d, got #%d", step.key, step.want, got)
+		}
+	}
+	if view := m.View(); !strings.Contains(view, "DEPENDENCY GRAPH") || !strings.Contains(view, "unblocks #4") {
+		t.Errorf("graph view missing expected content:\n%s", view)
+	}
+	// #1 fans out to #2 and #3, which both lead into #4
+	if view := m.renderGraph(120, 30); !strings.Contains(view, "──┬─▶") || !strings.Contains(view, "└─▶") || !strings.Contains(view, "──┘") {
+		t.Errorf("expected the edges drawn between the columns:\n%s", view)
+	}
+}
+
+func TestAgentStateMessages(t *testing.T) {
+	m := InitialModel()
+	m.Width, m.Height = 120, 40
+	m, _ = updateModel(m, orchestrator.AgentStateMsg{
+		{Name: "backend", State: orchestrator.AgentRunning, PID: 42},
+	})
+	m, _ = updateModel(m, orchestrator.AgentStateMsg{
+		{Name: "backend", State: orchestrator.AgentCrashed, ExitCode: 1, Err: "exited with code 1", Restarts: 1},
+	})
+	if len(m.events) < 2 || !strings.Contains(m.events[0], "backend CRASHED") || !strings.Contains(m.events[1], "backend RUNNING (pid 42)") {
+		t.Errorf("unexpected events %v", m.events)
+	}
+	if view := m.View(); !strings.Contains(view, "backend CRASHED") {
+		t.Errorf("agent state not rendered:\n%s", view)
+	}
+}
+
+func TestAgentsPanel(t *testing.T) {
+	m := InitialModel()
+	m.Width, m.Height = 160, 40
+	m.Tab = tabAgents
+	m, _ = updateModel(m, orchestrator.TaskLoadMsg{
+		{ID: 7, Status: "in_progress", Agent: "backend", Description: "login API"},
+	})
+	m, _ = updateModel(m, orchestrator.AgentsLoadMsg{
+		{Name: "backend", PID: 4242, Running: true, LastLogLine: "tool: Edit src/api.go"},
+		{Name: "docs"},
+	})
+
+	view := m.View()
+	for _, want := range []string{"AGENTS", "4242", "#7 login API", "tool: Edit src/api.go", "DOWN"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("agents panel missing %q:\n%s", want, view)
+		}
+	}
+
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyDown})
+	if a, _ := m.selectedAgent(); a.Name != "docs" {
+		t.Errorf("expected docs selected, got %q", a.Name)
+	}
+	if _, cmd := updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")}); cmd == nil || m.InputMode {
+		t.Error("s in the agents panel should spawn the agent, not prompt for a task ID")
+	}
+}
+
+func TestAgentCatalog(t *testing.T) {
+	m := InitialModel()
+	m, _ = updateModel(m, orchestrator.TaskLoadMsg{
+		{ID: 1, Status: "pending", Agent: "security", Description: "audit deps"},
+		{ID: 2, Status: "pending", Agent: "mobile", Description: "app icon"},
+	})
+	m, _ = updateModel(m, orchestrator.AgentCatalogMsg{Catalog: orchestrator.NewAgentCatalog([]orchestrator.AgentDef{
+		{Name: "mobile", DisplayName: "Mobile"},
+		{Name: "security", DisplayName: "Security"},
+	})})
+
+	if want := []string{autoAgentChoice, "mobile", "security"}; strings.Join(m.AgentChoices, ",") != strings.Join(want, ",") {
+		t.Errorf("expected agent choices %v, got %v", want, m.AgentChoices)
+	}
+
+	// f cycles the agent filter: mobile -> security -> all
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	if m.agentFilter != "mobile" || len(m.pendingList.Items()) != 1 {
+		t.Errorf("expected only mobile tasks, got filter %q with %d items", m.agentFilter, len(m.pendingList.Items()))
+	}
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	if m.agentFilter != "" || len(m.pendingList.Items()) != 2 {
+		t.Errorf("expected all tasks, got filter %q with %d items", m.agentFilter, len(m.pendingList.Items()))
+	}
+}
+
+func TestLogView(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "task-3.log")
+	log := "10:00 [INFO] Started task #3\n10:01 [DEBUG] reading tasks.json\n10:02 [ERROR] build failed\n  at main.go:12\n"
+	if err := os.WriteFile(path, []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := InitialModel()
+	m.Width, m.Height = 120, 40
+	cmd := m.openLog("Task #3", path, false)
+	m, _ = updateModel(m, cmd())
+
+	view := m.View()
+	for _, want := range []string{"LOGS: Task #3 (task-3.log)", "FOLLOW", "build failed", "at main.go:12"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("log view missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "Pending Tasks") {
+		t.Error("the full screen log view should hide the task lists")
+	}
+
+	// v cycles ALL -> INFO+ -> WARN+; the stack line stays with its error
+	press := func(keys ...string) {
+		for _, k := range keys {
+			m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		}
+	}
+	press("v", "v")
+	if vis := m.logView.visible(); len(vis) != 2 || m.logView.lines[vis[1]] != "  at main.go:12" {
+		t.Errorf("expected the error and its continuation, got %v", vis)
+	}
+	press("v", "v")
+
+	// search jumps to the match and stops following
+	press("/", "t", "a", "s", "k")
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.logView.query != "task" || m.logView.match != 1 || m.logView.follow {
+		t.Errorf("expected a jump to the latest match, got %+v", m.logView.match)
+	}
+	press("N")
+	if m.logView.match != 0 {
+		t.Errorf("expected the previous match, got %d", m.logView.match)
+	}
+
+	// new lines arrive through the watcher
+	if err := os.WriteFile(path, []byte(log+"10:03 [INFO] retrying\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, cmd = updateModel(m, orchestrator.FileChangeMsg{Paths: []string{path}})
+	for _, msg := range collect(cmd) {
+		if chunk, ok := msg.(orchestrator.LogChunkMsg); ok {
+			m, _ = updateModel(m, chunk)
+		}
+	}
+	if n := len(m.logView.lines); n != 5 {
+		t.Errorf("expected the appended line, got %d lines", n)
+	}
+
+	// s keeps the task lists visible, esc clears the search and then closes
+	press("s")
+	if !strings.Contains(m.View(), "Pending Tasks") {
+		t.Error("split view should show the task lists")
+	}
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyEsc})
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.logView.open {
+		t.Error("esc should close the log viewer")
+	}
+}
+
+func TestTranscriptView(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "task-5.log")
+	log := `{"type":"system","subtype":"init","model":"claude-sonnet"}
+{"type":"assistant","message":{"id":"m1","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go vet ./..."}}],"usage":{"input_tokens":1200,"output_tokens":30}}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"vet: all good"}]}}
+`
+	if err := os.WriteFile(path, []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := InitialModel()
+	m.Width, m.Height = 120, 40
+	cmd := m.openLog("Task #5", path, false)
+	m, _ = updateModel(m, cmd())
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+
+	view := m.View()
+	for _, want := range []string{"BASH", "$ go vet ./...", "tokens in 1.2k out 30"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("transcript missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "vet: all good") {
+		t.Error("sections should start collapsed")
+	}
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.Contains(m.View(), "vet: all good") {
+		t.Error("enter should expand the selected section")
+	}
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if m.logView.transcript != nil || !strings.Contains(m.View(), `"tool_use_id":"t1"`) {
+		t.Error("t should switch back to the raw lines")
+	}
+}
+
+func TestApprovalsPanel(t *testing.T) {
+	m := InitialModel()
+	m.Width, m.Height = 120, 40
+	m.Tab = tabApprovals
+	m, _ = updateModel(m, orchestrator.TaskLoadMsg{
+		{ID: 4, Status: "pending_approval", Agent: "backend", Description: "login API"},
+	})
+	if len(m.activeList.Items()) != 1 {
+		t.Errorf("expected the pending_approval task in the active list, got %d items", len(m.activeList.Items()))
+	}
+	m, _ = updateModel(m, orchestrator.ApprovalsLoadMsg{
+		{ID: "7", TaskID: 4, Agent: "backend", OperationType: orchestrator.OpTaskCompletion, Status: orchestrator.ApprovalPending,
+			Files: []orchestrator.ApprovalFile{{Path: "api/login.go", Diff: "+func Login() {}"}, {Path: "api/login_test.go"}}},
+		{ID: "6", TaskID: 3, Status: orchestrator.ApprovalApproved},
+	})
+	if m.pendingApprovals() != 1 || m.getSelectedID() != 4 {
+		t.Fatalf("expected 1 pending request on task #4, got %d on #%d", m.pendingApprovals(), m.getSelectedID())
+	}
+	if view := m.renderApprovals(100, 20); !strings.Contains(view, "api/login.go") || !strings.Contains(view, "[1/2]") {
+		t.Errorf("expected the diff of the first file:\n%s", view)
+	}
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("]")})
+	if m.approvalFile != 1 {
+		t.Errorf("expected ] to show the next file, got %d", m.approvalFile)
+	}
+
+	// rejecting needs a reason
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if !m.InputMode || m.ActiveCommand != "reject" || m.approvalID != "7" {
+		t.Fatalf("expected the reject prompt for request 7, got %v %q %q", m.InputMode, m.ActiveCommand, m.approvalID)
+	}
+	m, cmd := updateModel(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || !m.InputMode || !strings.Contains(m.events[0], "reason is required") {
+		t.Errorf("expected the prompt to stay open without a reason, got %v %q", m.InputMode, m.events[0])
+	}
+	m.Input.SetValue("missing tests")
+	m, cmd = updateModel(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || m.InputMode || m.ActiveCommand != "" {
+		t.Errorf("expected the reject command and the prompt closed, got %v %q", m.InputMode, m.ActiveCommand)
+	}
+
+	// answered requests cannot be answered again
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if m.InputMode || !strings.Contains(m.events[0], "already approved") {
+		t.Errorf("expected a warning for an answered request, got %v %q", m.InputMode, m.events[0])
+	}
+}
+
+func TestApprovalSweepEvents(t *testing.T) {
+	m := InitialModel()
+	m, _ = updateModel(m, orchestrator.ApprovalSweepMsg{Events: []orchestrator.ApprovalEvent{
+		{Kind: orchestrator.EscalationExpiring, Approval: orchestrator.Approval{ID: "3", TaskID: 2}, Remaining: 45 * time.Minute},
+		{Kind: orchestrator.EscalationExpired, Approval: orchestrator.Approval{ID: "7", TaskID: 4}, TaskStatus: orchestrator.StatusPending},
+	}})
+	if len(m.events) < 2 || m.events[0] != "[WARN] Approval 7 for task #4 expired; task re-queued" ||
+		m.events[1] != "[WARN] Approval 3 for task #2 expires in 45m0s" {
+		t.Errorf("unexpected events: %q", m.events)
+	}
+
+	deadline := time.Now().Add(2 * time.Hour).UTC().Format(time.RFC3339)
+	m, _ = updateModel(m, orchestrator.ApprovalsLoadMsg{
+		{ID: "3", TaskID: 2, Status: orchestrator.ApprovalPending, ExpiresAt: deadline},
+		{ID: "5", TaskID: 1, Status: orchestrator.ApprovalPending},
+	})
+	if next, ok := m.nextApprovalDeadline(); !ok || next.ID != "3" {
+		t.Errorf("expected request 3 to expire next, got %+v", next)
+	}
+}
+
+func TestDiffView(t *testing.T) {
+	m := InitialModel()
+	m.Width, m.Height = 140, 40
+	m.Tab = tabApprovals
+	m, _ = updateModel(m, orchestrator.ApprovalsLoadMsg{
+		{ID: "7", TaskID: 4, Status: orchestrator.ApprovalPending, Files: []orchestrator.ApprovalFile{
+			{Path: "api/login.go", Diff: "@@ -1,3 +1,3 @@\n package api\n-func Login() {}\n+func Login() error { return nil }\n@@ -9 +9,2 @@\n x\n+y\n"},
+			{Path: "api/new.go", Diff: "+package api"},
+		}},
+	})
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	v := m.diffView
+	if !v.open || len(v.files) != 2 || len(v.diffRows()) != 7 {
+		t.Fatalf("expected the request diff in unified mode, got open=%v files=%d rows=%d", v.open, len(v.files), len(v.diffRows()))
+	}
+	if view := m.View(); !strings.Contains(view, "DIFF: request 7") || !strings.Contains(view, "Login") {
+		t.Errorf("expected the diff pane:\n%s", view)
+	}
+
+	// side-by-side pairs the removed line with the added one
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	rows := m.diffView.diffRows()
+	if len(rows) != 6 || rows[2].left.Kind != '-' || rows[2].right.Kind != '+' {
+		t.Errorf("unexpected side-by-side rows: %+v", rows)
+	}
+
+	// n moves to the next hunk, enter folds it
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.diffView.hunk != 1 || !m.diffView.folded[1] || len(m.diffView.diffRows()) != 4 {
+		t.Errorf("expected the second hunk folded, got hunk %d rows %d", m.diffView.hunk, len(m.diffView.diffRows()))
+	}
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("]")})
+	if m.diffView.file != 1 || len(m.diffView.folded) != 0 {
+		t.Errorf("expected the next file unfolded, got file %d", m.diffView.file)
+	}
+
+	m, _ = updateModel(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.diffView.open || !m.diffView.split || m.Tab != tabApprovals {
+		t.Errorf("expected esc to close the pane and keep the mode, got %+v", m.diffView)
+	}
+}
+
+func TestDecomposeReview(t *testing.T) {
+	m := InitialModel()
+	m.Width, m.Height = 140, 40
+	m.decompose = decomposeView{open: true, task: "Login", loading: true, attempt: 2, input: textinput.New()}
+	// a slow answer to the first attempt is dropped
+	m, _ = updateModel(m, orchestrator.DecompositionMsg{Task: "Login", Attempt: 1, Source: "claude", Plan: orchestrator.Decomposition{Subtasks: []orchestrator.Subtask{{Description: "stale"}}}})
+	if !m.decompose.loading || len(m.decompose.plan.Subtasks) != 0 {
+		t.Fatalf("expected the stale answer to be dropped, got %+v", m.decompose.plan)
+	}
+	m, _ = updateModel(m, orchestrator.DecompositionMsg{Task: "Login", Attempt: 2, Source: "claude", Plan: orchestrator.Decomposition{Subtasks: []orchestrator.Subtask{
+		{Description: "API", Agent: "backend", Rationale: "server side"},
+		{Description: "Form", Agent: "frontend", Dependencies: []int{0}},
+	}}})
+	if view := m.View(); !strings.Contains(view, "DECOMPOSITION: Login") || !strings.Contains(view, "server side") {
+		t.Fatalf("expected the proposed subtasks:\n%s", view)
+	}
+
+	key := func(k string) {
+		t.Helper()
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "tab":
+			msg = tea.KeyMsg{Type: tea.KeyTab}
+		}
+		m, _ = updateModel(m, msg)
+	}
+	// move the form first: its dependency follows the API subtask
+	key("j")
+	key("K")
+	if s := m.decompose.plan.Subtasks; s[0].Description != "Form" || s[0].Dependencies[0] != 1 || m.decompose.cursor != 0 {
+		t.Fatalf("unexpected order after reordering: %+v", s)
+	}
+	key("tab")
+	if a := m.decompose.plan.Subtasks[0].Agent; a == "frontend" {
+		t.Errorf("expected tab to reassign the subtask")
+	}
+	key("a")
+	for _, r := range "Docs" {
+		key(string(r))
+	}
+	key("enter")
+	if s := m.decompose.plan.Subtasks; len(s) != 3 || s[2].Description != "Docs" || m.decompose.cursor != 2 {
+		t.Fatalf("expected an added subtask, got %+v", s)
+	}
+	key("p")
+	key("1")
+	key("enter")
+	if deps := m.decompose.plan.Subtasks[2].Dependencies; len(deps) != 1 || deps[0] != 0 {
+		t.Errorf("expected docs to depend on subtask 1, got %v", deps)
+	}
+	key("k")
+	key("d")
+	if s := m.decompose.plan.Subtasks; len(s) != 2 || s[1].Description != "Docs" || len(s[0].Dependencies) != 0 {
+		t.Errorf("expected the API subtask and the dependency on it removed, got %+v", s)
+	}
+
+	key("f")
+	for _, r := range "smaller" {
+		key(string(r))
+	}
+	m, cmd := updateModel(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.decompose.loading || cmd == nil {
+		t.Errorf("expected feedback to re-ask for a plan")
+	}
+	m.decompose.loading = false
+
+	m, cmd = updateModel(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.decompose.open || cmd == nil {
+		t.Errorf("expected enter to add the plan and close the review")
+	}
+}
+
+func TestPriorityOrder(t *testing.T) {
+	m := InitialModel()
+	m, _ = updateModel(m, orchestrator.TaskLoadMsg{
+		{ID: 1, Description: "old low", Status: "pending", Priority: "low", CreatedAt: "2026-01-01T00:00:00Z"},
+		{ID: 2, Description: "normal", Status: "pending", Priority: "normal", CreatedAt: "2026-01-02T00:00:00Z"},
+		{ID: 3, Description: "new critical", Status: "pending", Priority: "critical", CreatedAt: "2026-01-03T00:00:00Z"},
+		{ID: 4, Description: "old normal", Status: "pending", CreatedAt: "2026-01-01T12:00:00Z"},
+	})
+	var ids []int
+	for _, it := range m.pendingList.Items() {
+		ids = append(ids, it.(item).id)
+	}
+	if orchestrator.FormatIDs(ids) != orchestrator.FormatIDs([]int{3, 4, 2, 1}) {
+		t.Fatalf("expected critical, then normal by age, then low, got %v", ids)
+	}
+	if title := m.pendingList.Items()[0].(item).title; !strings.Contains(title, "CRIT") {
+		t.Errorf("expected a priority badge, got %q", title)
+	}
+
+	// raising the selected task keeps the cursor on it once the list re-sorts
+	m.pendingList.Select(2)
+	m, cmd := updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("+")})
+	if cmd == nil || !strings.Contains(m.events[0], "#2 priority: normal → high") {
+		t.Fatalf("expected a priority change of #2, got %v", m.events)
+	}
+	tasks := append([]orchestrator.Task(nil), m.Tasks...)
+	tasks[1].Priority = "high"
+	m, _ = updateModel(m, orchestrator.TaskLoadMsg(tasks))
+	if it := m.pendingList.SelectedItem().(item); it.id != 2 || m.pendingList.Index() != 1 {
+		t.Errorf("expected #2 selected at index 1, got #%d at %d", it.id, m.pendingList.Index())
+	}
+
+	m.pendingList.Select(0)
+	m, cmd = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("+")})
+	if cmd != nil || !strings.Contains(m.events[0], "already critical") {
+		t.Errorf("expected critical to be the top, got %v", m.events[0])
+	}
+}
+
+func TestBulkActions(t *testing.T) {
+	m := InitialModel()
+	m, _ = updateModel(m, orchestrator.TaskLoadMsg{
+		{ID: 1, Description: "a", Status: "pending"},
+		{ID: 2, Description: "b", Status: "pending"},
+		{ID: 3, Description: "c", Status: "pending"},
+		{ID: 4, Description: "d", Status: "pending"},
+	})
+	key := func(k tea.KeyMsg) tea.Cmd {
+		t.Helper()
+		var cmd tea.Cmd
+		m, cmd = updateModel(m, k)
+		return cmd
+	}
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	key(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	key(tea.KeyMsg{Type: tea.KeyShiftDown})
+	if ids := m.markedIDs(); orchestrator.FormatIDs(ids) != orchestrator.FormatIDs([]int{1, 2}) {
+		t.Fatalf("expected #1 and #2 marked, got %v", ids)
+	}
+	if title := m.pendingList.Items()[0].(item).title; !strings.Contains(title, "✔") {
+		t.Errorf("expected a mark on #1, got %q", title)
+	}
+	key(tea.KeyMsg{Type: tea.KeyCtrlA})
+	if len(m.marked) != 4 {
+		t.Fatalf("expected every task marked, got %v", m.markedIDs())
+	}
+	key(tea.KeyMsg{Type: tea.KeyCtrlA})
+	if len(m.marked) != 0 {
+		t.Fatalf("expected ctrl+a to clear a full selection, got %v", m.markedIDs())
+	}
+
+	key(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	key(tea.KeyMsg{Type: tea.KeyShiftUp})
+	if cmd := key(runes("c")); cmd != nil || m.confirm == nil || len(m.confirm.ids) != 2 {
+		t.Fatalf("expected a confirmation for 2 tasks, got %+v", m.confirm)
+	}
+	if view := m.View(); !strings.Contains(view, "Complete 2 task(s)") {
+		t.Errorf("expected the confirmation in the footer:\n%s", view)
+	}
+	if cmd := key(runes("y")); cmd == nil || m.confirm != nil {
+		t.Fatalf("expected y to run the bulk action")
+	}
+
+	m, cmd := updateModel(m, orchestrator.BulkDoneMsg{
+		Action: orchestrator.BulkAction{Kind: orchestrator.BulkComplete},
+		Results: []orchestrator.BulkResult{
+			{ID: 1, Task: orchestrator.Task{ID: 1, Status: "completed"}},
+			{ID: 2, Err: orchestrator.ErrBlocked},
+		},
+	})
+	if cmd == nil || !strings.Contains(m.events[0], "1/2 task(s) done") || !strings.Contains(m.events[2], "#2") {
+		t.Errorf("unexpected summary: %v", m.events[:3])
+	}
+	if ids := m.markedIDs(); len(ids) != 1 || ids[0] != 2 {
+		t.Errorf("expected the failed task to stay marked, got %v", ids)
+	}
+
+	key(runes("@"))
+	for _, r := range "nobody" {
+		key(runes(string(r)))
+	}
+	key(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.confirm != nil || !strings.Contains(m.events[0], "Unknown agent") {
+		t.Errorf("expected an unknown agent to be refused, got %v", m.events[0])
+	}
+	key(tea.KeyMsg{Type: tea.KeyEsc})
+	key(tea.KeyMsg{Type: tea.KeyEsc})
+	if len(m.marked) != 0 {
+		t.Errorf("expected esc to clear the marks, got %v", m.markedIDs())
+	}
+}
+
+func TestSafetyConfirmation(t *testing.T) {
+	m := InitialModel()
+	m, _ = updateModel(m, orchestrator.TaskLoadMsg{
+		{ID: 1, Description: "a", Status: "pending"},
+		{ID: 2, Description: "b", Status: "pending", Dependencies: []int{1}},
+		{ID: 3, Description: "c", Status: "pending_approval"},
+	})
+	key := func(k tea.KeyMsg) tea.Cmd {
+		t.Helper()
+		var cmd tea.Cmd
+		m, cmd = updateModel(m, k)
+		return cmd
+	}
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+
+	// #2 is selected after the list sorts by age: a plain y/n
+	m.pendingList.Select(1)
+	if cmd := key(runes("d")); cmd != nil || m.confirm == nil || m.confirm.phrase != "" {
+		t.Fatalf("expected a y/n question before removing, got %+v", m.confirm)
+	}
+	key(runes("n"))
+	if m.confirm != nil || !strings.HasPrefix(m.events[0], "Cancelled") {
+		t.Fatalf("expected n to cancel, got %v", m.events[0])
+	}
+
+	// #1 has a dependent: the ID must be typed
+	m.pendingList.Select(0)
+	key(runes("d"))
+	if m.confirm == nil || m.confirm.phrase != "1" {
+		t.Fatalf("expected a typed confirmation, got %+v", m.confirm)
+	}
+	if view := m.View(); !strings.Contains(view, "#2 depend on #1") {
+		t.Errorf("expected the reason in the footer:\n%s", view)
+	}
+	key(runes("y"))
+	if cmd := key(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil || m.confirm == nil {
+		t.Fatalf("expected a wrong phrase to be refused")
+	}
+	key(tea.KeyMsg{Type: tea.KeyBackspace})
+	key(runes("1"))
+	if cmd := key(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil || m.confirm != nil || m.events[0] != "Removing task #1..." {
+		t.Fatalf("expected the typed ID to remove the task, got %v", m.events[0])
+	}
+
+	// #3 waits for approval
+	m.Tab = tabActive
+	if cmd := key(runes("x")); cmd != nil || m.confirm != nil || !strings.Contains(m.events[0], "waits for approval") {
+		t.Errorf("expected a protected task to be refused, got %v", m.events[0])
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	m := InitialModel()
+	for _, k := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("u")}, {Type: tea.KeyCtrlR}} {
+		if _, cmd := updateModel(m, k); cmd == nil {
+			t.Errorf("expected %q to return a command", k.String())
+		}
+	}
+
+	// u must not also page the focused list back
+	var items []list.Item
+	for i := 1; i <= 30; i++ {
+		items = append(items, item{id: i, title: fmt.Sprintf("task %d", i)})
+	}
+	m.pendingList.SetItems(items)
+	m.pendingList.SetSize(40, 10)
+	m.pendingList.Paginator.Page = 1
+	if next, _ := updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")}); next.pendingList.Paginator.Page != 1 {
+		t.Errorf("u paged the list back to %d", next.pendingList.Paginator.Page)
+	}
+
+	m, cmd := updateModel(m, orchestrator.JournalMsg{Undo: true, Entry: orchestrator.JournalEntry{
+		Op:     "remove #3",
+		Before: []orchestrator.Task{{ID: 3}},
+	}})
+	if cmd == nil || m.events[0] != "Undid: remove #3 (#3)" {
+		t.Errorf("expected a reload and an event, got %q", m.events[0])
+	}
+	m, _ = updateModel(m, orchestrator.ErrorMsg(fmt.Errorf("%w: task #3 was modified afterwards", orchestrator.ErrJournalConflict)))
+	if !strings.HasPrefix(m.events[0], "[HINT]") {
+		t.Errorf("expected a hint for a conflict, got %q", m.events[0])
+	}
+}
+
+func TestArchiveView(t *testing.T) {
+	m := InitialModel()
+	m.Width, m.Height = 140, 40
+	m, cmd := updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	if !m.archive.open || !m.archive.loading || cmd == nil {
+		t.Fatalf("expected z to open the archive and load it")
+	}
+	m, _ = updateModel(m, orchestrator.ArchiveLoadMsg{
+		{Task: orchestrator.Task{ID: 7, Description: "Login form", Status: "failed", Agent: "frontend", UpdatedAt: "2026-01-03T10:00:00Z"}},
+		{Task: orchestrator.Task{ID: 4, Description: "Schema", Status: "completed", Agent: "backend", CompletedAt: "2026-01-02T10:00:00Z"}},
+	})
+	if view := m.View(); !strings.Contains(view, "ARCHIVE") || !strings.Contains(view, "Login form") || !strings.Contains(view, "2 of 2") {
+		t.Fatalf("expected the archived tasks:\n%s", view)
+	}
+
+	key := func(k string) tea.Cmd {
+		t.Helper()
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		if k == "enter" {
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		}
+		var cmd tea.Cmd
+		m, cmd = updateModel(m, msg)
+		return cmd
+	}
+	key("/")
+	for _, r := range "schema" {
+		key(string(r))
+	}
+	key("enter")
+	if len(m.archive.results) != 1 || m.archive.results[0].Task.ID != 4 || m.archive.searching {
+		t.Fatalf("expected the search to keep #4, got %+v", m.archive.results)
+	}
+	if cmd := key("r"); cmd == nil || !strings.Contains(m.events[0], "Restoring task #4") {
+		t.Errorf("expected r to restore #4, got %q", m.events[0])
+	}
+	m, cmd = updateModel(m, orchestrator.RestoredMsg{ID: 4, Status: "completed"})
+	if cmd == nil || m.events[0] != "Restored task #4 (completed)" {
+		t.Errorf("expected a reload and an event, got %q", m.events[0])
+	}
+
+	m, _ = updateModel(m, orchestrator.ArchivedMsg{{Task: orchestrator.Task{ID: 9}}, {Task: orchestrator.Task{ID: 10}}})
+	if m.events[0] != "Archived 2 finished task(s): #9, #10" {
+		t.Errorf("unexpected event %q", m.events[0])
+	}
+	key("q")
+	if m.archive.open {
+		t.Errorf("expected q to close the archive")
+	}
+}
+
+func TestTaskSearch(t *testing.T) {
+	m := InitialModel()
+	m.Width, m.Height = 160, 40
+	m, _ = updateModel(m, orchestrator.TaskLoadMsg{
+		{ID: 1, Description: "Login form", Status: "failed", Agent: "frontend", Priority: "high"},
+		{ID: 2, Description: "Login API", Status: "failed", Agent: "backend", Priority: "normal"},
+		{ID: 3, Description: "Schema", Status: "pending", Agent: "backend"},
+	})
+	m, _ = updateModel(m, orchestrator.SavedSearchesMsg{Searches: []orchestrator.SavedSearch{{Name: "triage", Query: "status:failed"}}})
+
+	key := func(k string) {
+		t.Helper()
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "tab":
+			msg = tea.KeyMsg{Type: tea.KeyTab}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "ctrl+s":
+			msg = tea.KeyMsg{Type: tea.KeyCtrlS}
+		}
+		m, _ = updateModel(m, msg)
+	}
+	key("/")
+	if !m.InputMode || m.ActiveCommand != "search" {
+		t.Fatalf("expected / to prompt for a query")
+	}
+	for _, r := range "status:nope" {
+		key(string(r))
+	}
+	key("enter")
+	if !m.InputMode || !strings.Contains(m.events[0], "unknown status") {
+		t.Fatalf("expected an invalid query to be reported, got %q", m.events[0])
+	}
+	m.Input.SetValue("")
+	key("tab")
+	if m.Input.Value() != "@triage" {
+		t.Fatalf("expected tab to pick the saved search, got %q", m.Input.Value())
+	}
+	for _, r := range ` prio:high+ "login"` {
+		key(string(r))
+	}
+	key("enter")
+	if m.InputMode || m.search.Empty() || m.events[0] != `Search @triage prio:high+ "login": 1 task(s)` {
+		t.Fatalf("unexpected search: %+v %q", m.search, m.events[0])
+	}
+	if items := m.activeList.Items(); len(items) != 1 || items[0].(item).id != 1 {
+		t.Errorf("expected only #1 listed, got %v", items)
+	}
+	if desc := m.activeList.Items()[0].(item).desc; ansiEscape.ReplaceAllString(desc, "") != "Login form" {
+		t.Errorf("expected the description kept around the highlight, got %q", desc)
+	}
+	if !strings.Contains(m.activeList.Title, `/@triage prio:high+ "login"`) {
+		t.Errorf("expected the query in the title, got %q", m.activeList.Title)
+	}
+
+	key("ctrl+s")
+	for _, r := range "hot" {
+		key(string(r))
+	}
+	m, cmd := updateModel(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || m.InputMode || !strings.Contains(m.events[0], "as @hot") {
+		t.Errorf("expected the search to be saved, got %q", m.events[0])
+	}
+
+	key("esc")
+	if !m.search.Empty() || len(m.activeList.Items()) != 2 || len(m.pendingList.Items()) != 1 {
+		t.Errorf("expected esc to clear the search")
+	}
+}
+
+func TestMarkWords(t *testing.T) {
+	got := markWords("Fix the login API", []string{"login", "api"}, lipgloss.NewStyle())
+	if ansiEscape.ReplaceAllString(got, "") != "Fix the login API" {
+		t.Errorf("marking changed the text: %q", got)
+	}
+	if got := markWords("plain", nil, lipgloss.NewStyle()); got != "plain" {
+		t.Errorf("expected plain text without words, got %q", got)
+	}
+}
+
+func TestHighlightSyntax(t *testing.T) {
+	line := `return "x" // done`
+	if got := highlightSyntax(line, "go", lipgloss.NewStyle()); ansiEscape.ReplaceAllString(got, "") != line {
+		t.Errorf("highlighting changed the text: %q", got)
+	}
+	if got := highlightSyntax(line, "", lipgloss.NewStyle()); got != line {
+		t.Errorf("expected plain text without a language, got %q", got)
+	}
+}
+
+// collect runs cmd and flattens batches into their messages
+func collect(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		var msgs []tea.Msg
+		for _, c := range batch {
+			msgs = append(msgs, collect(c)...)
+		}
+		return msgs
+	}
+	return []tea.Msg{msg}
+}
+
 // Helper to cast model back to MainModel
 func updateModel(m MainModel, msg tea.Msg) (MainModel, tea.Cmd) {
 	newM, cmd := m.Update(msg)
 	return newM.(MainModel), cmd
 }
+
+func TestFileChangeDispatch(t *testing.T) {
+	p := &orchestrator.Project{Root: t.TempDir()}
+	orchestrator.SetProject(p)
+	t.Cleanup(func() { orchestrator.SetProject(nil) })
+	m := InitialModel()
+
+	kinds := func(paths ...string) string {
+		var out []string
+		for _, cmd := range m.fileChangeCmds(paths) {
+			out = append(out, fmt.Sprintf("%T", cmd()))
+		}
+		return strings.Join(out, ",")
+	}
+	if got := kinds(filepath.Join(p.PidsDir(), "backend.pid")); got != "orchestrator.AgentsLoadMsg" {
+		t.Errorf("a pid file should only reload the agents, got %s", got)
+	}
+	if got := kinds(filepath.Join(p.LogsDir(), "backend.log")); got != "" {
+		t.Errorf("a log nobody is viewing should reload nothing, got %s", got)
+	}
+	if got := kinds(p.ApprovalsPath(), p.TasksPath()); strings.Count(got, ",") != 1 || strings.Contains(got, "Agents") {
+		t.Errorf("expected the tasks and approvals reloaded, got %s", got)
+	}
+}
+
+func TestServicesLifecycle(t *testing.T) {
+	p := &orchestrator.Project{Root: t.TempDir()}
+	orchestrator.SetProject(p)
+	t.Cleanup(func() { orchestrator.SetProject(nil) })
+	m := InitialModel()
+	if m.watcher != nil || m.supervisor != nil || m.approvalEngine != nil {
+		t.Fatal("InitialModel should not start any service")
+	}
+
+	msg, ok := startServicesCmd()().(servicesMsg)
+	if !ok || msg.watcher == nil || msg.supervisor == nil || msg.approvalEngine == nil {
+		t.Fatalf("expected every service for a project, got %+v", msg)
+	}
+	m, cmd := updateModel(m, msg)
+	if m.watcher != msg.watcher || cmd == nil {
+		t.Fatal("servicesMsg should install the services and listen to them")
+	}
+
+	m, cmd = updateModel(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if _, ok := cmd().(tea.QuitMsg); !ok || !m.Quitting {
+		t.Fatal("q should quit")
+	}
+	if m.watcher != nil || m.supervisor != nil || m.approvalEngine != nil {
+		t.Error("quitting should stop the services")
+	}
+}
+
+func TestCycleWarnings(t *testing.T) {
+	m := InitialModel()
+	tasks := []orchestrator.Task{
+		{ID: 1, Description: "API", Status: "pending", Dependencies: []int{2}},
+		{ID: 2, Description: "UI", Status: "pending", Dependencies: []int{1}},
+	}
+	warnings := func() int {
+		n := 0
+		for _, ev := range m.events {
+			if strings.Contains(ev, "Dependency cycle between #1, #2") {
+				n++
+			}
+		}
+		return n
+	}
+
+	m, _ = updateModel(m, orchestrator.TaskLoadMsg(tasks))
+	if warnings() != 1 {
+		t.Fatalf("expected one warning for the cycle, got %v", m.events)
+	}
+	edited := slices.Clone(tasks)
+	edited[0].Description = "Login API"
+	m, _ = updateModel(m, orchestrator.TaskLoadMsg(edited))
+	if warnings() != 1 {
+		t.Errorf("an unrelated edit should not repeat the warning, got %v", m.events)
+	}
+}
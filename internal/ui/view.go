@@ -2,9 +2,12 @@ package ui
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"shineos/claude-orchestra/internal/orchestrator"
 )
 
 var (
@@ -44,12 +47,8 @@ func (m MainModel) View() string {
     tW := W - 4
     if tW < 60 { tW = 60 }
     
-    tH := H - 4
-    if tH < 15 { tH = 15 }
-    
-    logH := (tH * 35) / 100
-    if logH < 6 { logH = 6 }
-    listH := tH - 3 - logH 
+    strip := renderAgentStrip(m.agentStates)
+    listH, logH := m.bodyHeights(strip != "")
 
     gapW := 1
     // Total row width = cW1 + Gap + cW2 + Gap + cW3 = tW
@@ -68,17 +67,17 @@ func (m MainModel) View() string {
     // Note: s.Width(n) sets the INNER content width. We must subtract chromeW.
     m.pendingList.SetSize(cW1 - chromeW, listH - chromeH)
     ps1 := sBase
-    if m.Tab == 0 && !m.AddingTask { ps1 = sActive }
+    if m.Tab == tabPending && !m.AddingTask { ps1 = sActive }
     v1 := ps1.Width(cW1 - chromeW).Height(listH - chromeH).Render(m.pendingList.View())
 
     m.activeList.SetSize(cW2 - chromeW, listH - chromeH)
     as2 := sBase
-    if m.Tab == 1 && !m.AddingTask { as2 = sActive }
+    if m.Tab == tabActive && !m.AddingTask { as2 = sActive }
     v2 := as2.Width(cW2 - chromeW).Height(listH - chromeH).Render(m.activeList.View())
 
     m.completeList.SetSize(cW3 - chromeW, listH - chromeH)
     cs3 := sBase
-    if m.Tab == 2 && !m.AddingTask { cs3 = sActive }
+    if m.Tab == tabComplete && !m.AddingTask { cs3 = sActive }
     v3 := cs3.Width(cW3 - chromeW).Height(listH - chromeH).Render(m.completeList.View())
 
     // Log
@@ -93,10 +92,45 @@ func (m MainModel) View() string {
     }
     // Log box should also be tW wide total
     vLog := sBase.Width(tW - chromeW).Height(logH - chromeH).Render(lTitle + "\n" + strings.Join(lLines, "\n"))
+    if m.archive.open {
+        // The archive search takes the whole body, under any log opened from it
+        vH := logH + listH
+        lTitle = titleStyle.Render("ARCHIVE")
+        vLog = sActive.Width(tW - chromeW).Height(vH - chromeH).Render(lTitle + "\n" + m.renderArchive(tW - chromeW, vH - chromeH - 1))
+    }
+    if m.logView.open {
+        // The log viewer takes the log box (split) or the whole body
+        vH := logH
+        if !m.logView.split { vH += listH }
+        lTitle = titleStyle.Render(fmt.Sprintf("LOGS: %s (%s)", m.logView.title, filepath.Base(m.logView.path)))
+        vLog = sActive.Width(tW - chromeW).Height(vH - chromeH).Render(lTitle + "\n" + m.renderLog(tW - chromeW, vH - chromeH - 1))
+    }
+    if m.diffView.open {
+        // The diff pane takes the whole body
+        vH := logH + listH
+        lTitle = titleStyle.Render("DIFF: " + m.diffView.title)
+        vLog = sActive.Width(tW - chromeW).Height(vH - chromeH).Render(lTitle + "\n" + m.renderDiff(tW - chromeW, vH - chromeH - 1))
+    }
+    if m.decompose.open {
+        // So does the decomposition review
+        vH := logH + listH
+        lTitle = titleStyle.Render("DECOMPOSITION: " + m.decompose.task)
+        vLog = sActive.Width(tW - chromeW).Height(vH - chromeH).Render(lTitle + "\n" + m.renderDecompose(tW - chromeW, vH - chromeH - 1))
+    }
 
     // 4. HEADER & FOOTER
 	header := lipgloss.NewStyle().Width(tW).Bold(true).Foreground(accent).
         Render(fmt.Sprintf("💠 CLAUDE ORCHESTRA | CONTROL CENTER v1.1   [%dx%d]", W, H))
+    if n := m.pendingApprovals(); n > 0 {
+        pending := fmt.Sprintf("   ⏳ %d approval(s) pending", n)
+        if next, ok := m.nextApprovalDeadline(); ok {
+            pending += " · next expires " + approvalDue(next, time.Now())
+        }
+        header += lipgloss.NewStyle().Foreground(lipgloss.Color("220")).Bold(true).Render(pending)
+    }
+    if strip != "" {
+        header = lipgloss.JoinVertical(lipgloss.Left, header, lipgloss.NewStyle().MaxWidth(tW).Render(strip))
+    }
 
     var footer string
     if m.AddingTask {
@@ -112,17 +146,30 @@ func (m MainModel) View() string {
         case 2:
             var choices []string
             for i, choice := range m.AgentChoices {
+                label := choice
+                if choice != autoAgentChoice {
+                    label = lipgloss.NewStyle().Foreground(agentColor(m.catalog, choice)).Render(m.catalog.DisplayName(choice))
+                }
                 if i == m.AgentChoiceIndex {
-                    choices = append(choices, lipgloss.NewStyle().Background(accent).Foreground(lipgloss.Color("0")).Render(" "+choice+" "))
+                    choices = append(choices, lipgloss.NewStyle().Background(accent).Foreground(lipgloss.Color("0")).Render(" "+m.catalog.DisplayName(choice)+" "))
                 } else {
-                    choices = append(choices, choice)
+                    choices = append(choices, label)
                 }
             }
             content = "Select Agent: " + strings.Join(choices, "  ")
             hint = "[Tab/Arrows] Change  [Enter] Next  [Esc] Cancel"
+            if m.AgentChoiceIndex < len(m.AgentChoices) {
+                if def, ok := m.catalog.Lookup(m.AgentChoices[m.AgentChoiceIndex]); ok && def.Description != "" {
+                    about := def.Description
+                    if len(def.Capabilities) > 0 {
+                        about += " (" + strings.Join(def.Capabilities, ", ") + ")"
+                    }
+                    hint = about + "   " + hint
+                }
+            }
         case 3:
-            agent := m.PendingTaskAgent
-            if agent == "" { agent = "AI (auto)" }
+            agent := m.catalog.DisplayName(m.PendingTaskAgent)
+            if agent == "" { agent = autoAgentChoice }
             content = lipgloss.NewStyle().Foreground(special).Render(fmt.Sprintf("CONFIRM: [%s] %s", agent, m.PendingTaskDesc))
             hint = "[Enter] Confirm  [E] Edit Description  [Esc] Cancel"
         }
@@ -130,10 +177,69 @@ func (m MainModel) View() string {
     } else {
         // Regular Footer
         fCmd := lipgloss.NewStyle().Foreground(special).Render("(Command Mode)")
-        fHnt := "[Tab] Move  [A] Add  [S] Start  [T] Stop  [C] Comp  [L] Logs  [V] Verbose  [E] Edit  [W] Watch  [R] Refresh  [O] Open  [Q] Exit"
+        fHnt := "[Tab] Move  [A] Add  [S] Start  [T] Stop  [C] Comp  [L] Logs  [V] Transcript  [G] Diff  [E] Edit  [^E] Edit All  [+/-] Priority  [Space] Mark  [W] Watch  [F] Filter Agent  [R] Refresh  [/] Search  [^S] Save Search  [U] Undo  [^R] Redo  [Z] Archive  [O] Open  [Q] Exit"
+        if !m.search.Empty() {
+            fCmd = lipgloss.NewStyle().Foreground(special).Render("(Search: " + m.search.Text + ")  [Esc] Clear")
+        }
+        if m.Tab == tabAgents {
+            fCmd = lipgloss.NewStyle().Foreground(special).Render("(Agents)")
+            fHnt = "[↑/↓] Select  [S] Spawn  [X] Stop  [Shift+R] Restart  [L] Logs  [r] Refresh  [Tab] Next View  [Q] Exit"
+        }
+        if m.Tab == tabGraph {
+            fCmd = lipgloss.NewStyle().Foreground(special).Render("(Graph View)")
+            fHnt = "[Arrows] Select  [S] Start  [T] Stop  [C] Comp  [E] Edit  [D] Remove  [+/-] Priority  [Tab] Next View  [Q] Exit"
+        }
+        if m.Tab == tabApprovals {
+            fCmd = lipgloss.NewStyle().Foreground(special).Render("(Approvals)")
+            fHnt = "[↑/↓] Select  [[/]] File  [PgUp/PgDn] Scroll Diff  [D] Diff  [A] Approve  [R] Reject  [Tab] Next View  [Q] Exit"
+        }
+        if m.archive.open {
+            fCmd = lipgloss.NewStyle().Foreground(special).Render("(Archive)")
+            fHnt = "[/] Search  [↑/↓] Select  [R] Restore  [L] Logs  [A] Archive Now  [Esc] Back"
+            if m.archive.searching {
+                fHnt = "Type to filter  [Enter/Esc] Done"
+            }
+        }
+        if m.logView.open {
+            fCmd = lipgloss.NewStyle().Foreground(special).Render("(Logs)")
+            fHnt = "[↑/↓/PgUp/PgDn] Scroll  [G] End  [F] Follow  [/] Search  [N/Shift+N] Next/Prev  [V] Level  [T] Transcript  [S] Split  [Esc] Back"
+            if m.logView.transcript != nil {
+                fHnt = "[↑/↓] Select  [Enter] Expand  [E/C] Expand/Collapse All  [F] Follow  [/] Search  [N/Shift+N] Next/Prev  [T] Raw  [S] Split  [Esc] Back"
+            }
+        }
+        if m.diffView.open {
+            fCmd = lipgloss.NewStyle().Foreground(special).Render("(Diff)")
+            fHnt = "[↑/↓/PgUp/PgDn] Scroll  [N/P] Next/Prev Hunk  [Enter] Fold  [Shift+Z] Fold All  [[/]] File  [S] Side-by-side  [Esc] Back"
+        }
+        if m.decompose.open {
+            fCmd = lipgloss.NewStyle().Foreground(special).Render("(Decomposition)")
+            fHnt = "[↑/↓] Select  [Shift+J/K] Reorder  [Tab] Agent  [E] Edit  [P] Depends On  [A] Add  [D] Delete  [F] Feedback  [Enter] Add All  [Esc] Cancel"
+            if m.decompose.editing != "" {
+                fCmd = m.decompose.input.View()
+                fHnt = "[Enter]: Confirm  [Esc]: Cancel"
+            }
+        }
+        if len(m.marked) > 0 && m.Tab != tabAgents && m.Tab != tabApprovals {
+            fCmd = lipgloss.NewStyle().Foreground(special).Render(fmt.Sprintf("(%d marked: %s)", len(m.marked), orchestrator.FormatIDs(m.markedIDs())))
+            fHnt = "[Space] Mark  [Shift+↑/↓] Range  [^A] All  [S] Start  [T] Stop  [C] Comp  [D] Remove  [@] Reassign  [+/-] Priority  [Esc] Clear"
+        }
         if m.InputMode {
             fCmd = m.Input.View()
             fHnt = "[Enter]: Confirm  [Esc]: Cancel"
+            if m.ActiveCommand == "search" {
+                fHnt = "[Enter]: Search (empty clears)  [Tab]: Saved Searches  [Esc]: Cancel"
+            }
+        }
+        if c := m.confirm; c != nil {
+            question := c.question
+            if len(c.reasons) > 0 {
+                question += " (" + strings.Join(c.reasons, "; ") + ")"
+            }
+            fCmd = lipgloss.NewStyle().Foreground(lipgloss.Color("220")).Bold(true).Render(question)
+            fHnt = "[Y/Enter] Yes  [N/Esc] No"
+            if c.phrase != "" {
+                fHnt = c.input.View() + "  [Enter] Confirm  [Esc] Cancel"
+            }
         }
         footer = lipgloss.JoinVertical(lipgloss.Left, fCmd, fHnt)
     }
@@ -141,8 +247,39 @@ func (m MainModel) View() string {
     // 5. ASSEMBLY
     hGap := strings.Repeat(" ", gapW)
     mid := lipgloss.JoinHorizontal(lipgloss.Top, v1, hGap, v2, hGap, v3)
+    if m.Tab == tabAgents {
+        gW, gH := tW-chromeW, listH-chromeH
+        mid = sActive.Width(gW).Height(gH).Render(titleStyle.Render("AGENTS") + "\n" + m.renderAgents(gW, gH-1))
+    }
+    if m.Tab == tabGraph {
+        gW, gH := tW-chromeW, listH-chromeH
+        mid = sActive.Width(gW).Height(gH).Render(titleStyle.Render("DEPENDENCY GRAPH") + "\n" + m.renderGraph(gW, gH-1))
+    }
+    if m.Tab == tabApprovals {
+        gW, gH := tW-chromeW, listH-chromeH
+        mid = sActive.Width(gW).Height(gH).Render(titleStyle.Render("APPROVALS") + "\n" + m.renderApprovals(gW, gH-1))
+    }
     board := lipgloss.JoinVertical(lipgloss.Left, header, mid, vLog, footer)
+    if m.logView.open && !m.logView.split || m.archive.open || m.diffView.open || m.decompose.open {
+        board = lipgloss.JoinVertical(lipgloss.Left, header, vLog, footer)
+    }
     
 	// 6. FINAL PLACEMENT (Centered but with smaller gutters)
     return lipgloss.Place(W, H, lipgloss.Center, lipgloss.Center, board)
 }
+
+// bodyHeights splits the height left by header and footer between the task
+// panels and the log box below them
+func (m MainModel) bodyHeights(strip bool) (listH, logH int) {
+	tH := m.Height - 4
+	if strip {
+		tH-- // agent liveness line under the header
+	}
+	tH = max(tH, 15)
+	logH = (tH * 35) / 100
+	if m.logView.open && m.logView.split {
+		logH = tH / 2
+	}
+	logH = max(logH, 6)
+	return tH - 3 - logH, logH
+}